@@ -7,6 +7,7 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"bedrock-performance/internal/benchmark"
 	"bedrock-performance/internal/config"
@@ -15,6 +16,8 @@ import (
 func main() {
 	// Parse command line flags
 	configPath := flag.String("config", "config.json", "Path to configuration file")
+	dryRun := flag.Bool("dry-run", false, "Count input tokens and estimate cost and TPM without sending generation requests")
+	assumedLatency := flag.Duration("assumed-latency", 5*time.Second, "Request duration assumed by -dry-run")
 	flag.Parse()
 
 	// Load configuration
@@ -41,6 +44,18 @@ func main() {
 	// Create and run the benchmark
 	runner := benchmark.NewRunner(cfg)
 
+	if *dryRun {
+		if *assumedLatency <= 0 {
+			fmt.Fprintf(os.Stderr, "-assumed-latency must be positive\n")
+			os.Exit(1)
+		}
+		if _, err := runner.DryRun(ctx, *assumedLatency); err != nil {
+			fmt.Fprintf(os.Stderr, "Dry run failed: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	allStats, err := runner.Run(ctx)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Benchmark failed: %v\n", err)
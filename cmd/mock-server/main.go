@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"bedrock-performance/internal/mock"
+)
+
+func main() {
+	// Parse command line flags
+	configPath := flag.String("config", "", "Path to mock server configuration file (optional)")
+	addr := flag.String("addr", "", "Listen address, overrides addr in the configuration file")
+	flag.Parse()
+
+	// Load configuration
+	cfg := mock.DefaultConfig()
+	if *configPath != "" {
+		var err error
+		cfg, err = mock.LoadConfig(*configPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to load configuration: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if *addr != "" {
+		cfg.Addr = *addr
+	}
+
+	server := &http.Server{Addr: cfg.Addr, Handler: mock.NewServer(cfg)}
+
+	// Setup signal handling for graceful shutdown
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		<-sigChan
+		fmt.Println("\nReceived interrupt signal, shutting down...")
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(ctx)
+	}()
+
+	fmt.Printf("Mock Bedrock runtime listening on %s (TTFT p50 %.0fms, %.0f tokens/s, %d-%d output tokens)\n",
+		cfg.Addr, cfg.TTFT.P50, cfg.TokensPerSecond, cfg.OutputTokens.Min, cfg.OutputTokens.Max)
+	fmt.Printf("Set aws.endpoint_url to %s in the benchmark configuration\n", endpointURL(cfg.Addr))
+
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		fmt.Fprintf(os.Stderr, "Mock server failed: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// endpointURL returns the URL a local benchmark reaches the listen address
+// at; an empty or wildcard host is reached through localhost
+func endpointURL(addr string) string {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "http://" + addr
+	}
+	if ip := net.ParseIP(host); host == "" || (ip != nil && ip.IsUnspecified()) {
+		host = "localhost"
+	}
+	return "http://" + net.JoinHostPort(host, port)
+}
@@ -0,0 +1,217 @@
+package bedrock
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Capabilities describes what a model family supports over InvokeModel
+type Capabilities struct {
+	Streaming bool
+	// PromptCaching means the format accepts cache breakpoints on a shared prefix
+	PromptCaching bool
+	// Thinking means the format accepts an extended thinking budget
+	Thinking bool
+	// ReasoningEffort means the format accepts a reasoning effort level
+	ReasoningEffort bool
+	// ToolUse means the format accepts tool definitions and reports tool_use blocks
+	ToolUse bool
+	// Images and Documents mean the format accepts image and document attachments
+	Images    bool
+	Documents bool
+	// TopK and StopSequences mean the format has fields for top_k and stop sequences
+	TopK          bool
+	StopSequences bool
+}
+
+// InferenceParams carries the generation settings used to build a request body
+type InferenceParams struct {
+	Prompt      string
+	MaxTokens   int
+	Temperature float64
+	// Optional sampling and prompt settings; zero values are omitted from requests
+	SystemPrompt  string
+	TopP          float64
+	TopK          int
+	StopSequences []string
+	// Prefill starts the assistant turn with this text
+	Prefill string
+	// SharedPrefix is a system prompt or document sent unchanged ahead of every
+	// prompt; only set for formats that support prompt caching
+	SharedPrefix string
+	// CachePrefix places a cache breakpoint after SharedPrefix
+	CachePrefix bool
+	// ThinkingBudget enables extended thinking with this many tokens (0 disables it)
+	ThinkingBudget int
+	// ReasoningEffort is "low", "medium" or "high" for models with effort control ("" for the model default)
+	ReasoningEffort string
+	// Tools are offered to the model; ToolChoice is "auto", "any" or a tool name
+	Tools      []ToolSpec
+	ToolChoice string
+	// ToolCalls, when set, makes this the second turn: the assistant's tool
+	// calls are replayed and each is answered with ToolResult
+	ToolCalls  []ToolCall
+	ToolResult string
+	// Images and Documents are attached to the user message, ahead of Prompt
+	Images    []Image
+	Documents []Document
+}
+
+// StreamDelta is the generated content carried by a single stream chunk
+type StreamDelta struct {
+	Text string
+	// Thinking is reasoning content emitted before the answer
+	Thinking string
+	// ToolUse opens a tool_use block; ToolInput is a fragment of its input
+	// JSON and BlockStop closes the current content block
+	ToolUse   *ToolCall
+	ToolInput string
+	BlockStop bool
+}
+
+// ModelAdapter translates between the benchmark and the InvokeModel JSON
+// format of one model family. Adapters are stateless and safe for concurrent use.
+type ModelAdapter interface {
+	// Name returns the format name that can be selected with model.format
+	Name() string
+
+	// Capabilities reports which invocation modes the family supports
+	Capabilities() Capabilities
+
+	// BuildRequest returns the InvokeModel request body
+	BuildRequest(params *InferenceParams) ([]byte, error)
+
+	// ParseResponse extracts content and token usage from a non-streaming response body
+	ParseResponse(body []byte, result *InvokeResult) error
+
+	// ParseStreamChunk extracts content and token usage from one stream chunk
+	ParseStreamChunk(chunk []byte, result *InvokeResult) (StreamDelta, error)
+}
+
+// adapterRegistry maps format names and model ID prefixes to adapters
+type adapterRegistry struct {
+	mu       sync.RWMutex
+	byName   map[string]ModelAdapter
+	byPrefix map[string]ModelAdapter
+}
+
+var registry = &adapterRegistry{
+	byName:   make(map[string]ModelAdapter),
+	byPrefix: make(map[string]ModelAdapter),
+}
+
+// RegisterAdapter registers an adapter under its format name and the model ID
+// prefixes (e.g. "anthropic.") it serves. Adapters register themselves from
+// init functions, so adding a model family only requires a new file.
+func RegisterAdapter(adapter ModelAdapter, prefixes ...string) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	registry.byName[adapter.Name()] = adapter
+	for _, prefix := range prefixes {
+		registry.byPrefix[strings.ToLower(prefix)] = adapter
+	}
+}
+
+// ResolveAdapter returns the adapter for a model. An explicit format (from
+// model.format) takes precedence; otherwise the longest registered prefix of
+// the base model ID wins. Inference profile IDs and ARNs are resolved through
+// their underlying model; application profiles, provisioned throughput and
+// imported models carry no family information and need an explicit format.
+func ResolveAdapter(modelID, format string) (ModelAdapter, error) {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+
+	if format != "" {
+		adapter, ok := registry.byName[strings.ToLower(format)]
+		if !ok {
+			return nil, fmt.Errorf("unknown model format %q (supported: %s)", format, strings.Join(registry.names(), ", "))
+		}
+		return adapter, nil
+	}
+
+	ref := ParseModelID(modelID)
+	if ref.BaseModelID == "" {
+		return nil, fmt.Errorf("cannot determine the model family of %s (%s); set model.format to one of: %s",
+			modelID, ref.Kind, strings.Join(registry.names(), ", "))
+	}
+
+	id := strings.ToLower(ref.BaseModelID)
+	var match ModelAdapter
+	matchLen := 0
+	for prefix, adapter := range registry.byPrefix {
+		if strings.HasPrefix(id, prefix) && len(prefix) > matchLen {
+			match = adapter
+			matchLen = len(prefix)
+		}
+	}
+	if match == nil {
+		return nil, fmt.Errorf("unsupported model: %s (set model.format to one of: %s)", modelID, strings.Join(registry.names(), ", "))
+	}
+	return match, nil
+}
+
+// systemText returns the system prompt and shared prefix as a single text, for
+// formats that take the system prompt as one string
+func (p *InferenceParams) systemText() string {
+	switch {
+	case p.SystemPrompt == "":
+		return p.SharedPrefix
+	case p.SharedPrefix == "":
+		return p.SystemPrompt
+	default:
+		return p.SystemPrompt + "\n\n" + p.SharedPrefix
+	}
+}
+
+// AdapterNames returns the registered format names in sorted order
+func AdapterNames() []string {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+	return registry.names()
+}
+
+// names returns the sorted format names; the caller must hold the lock
+func (r *adapterRegistry) names() []string {
+	names := make([]string, 0, len(r.byName))
+	for name := range r.byName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// invocationMetricsKey is the field Bedrock adds to the final chunk of a stream
+// (and to some non-streaming bodies) with the service-side invocation metrics
+const invocationMetricsKey = "amazon-bedrock-invocationMetrics"
+
+// applyInvocationMetrics copies token counts and server-side latencies from an
+// amazon-bedrock-invocationMetrics object in payload, if present, onto result
+func applyInvocationMetrics(payload []byte, result *InvokeResult) {
+	if !bytes.Contains(payload, []byte(invocationMetricsKey)) {
+		return
+	}
+
+	var envelope InvocationMetricsEnvelope
+	if err := json.Unmarshal(payload, &envelope); err != nil || envelope.Metrics == nil {
+		return
+	}
+
+	if envelope.Metrics.InputTokenCount > 0 {
+		result.InputTokens = envelope.Metrics.InputTokenCount
+	}
+	if envelope.Metrics.OutputTokenCount > 0 {
+		result.OutputTokens = envelope.Metrics.OutputTokenCount
+	}
+	if envelope.Metrics.InvocationLatency > 0 {
+		result.ServerLatency = time.Duration(envelope.Metrics.InvocationLatency) * time.Millisecond
+	}
+	if envelope.Metrics.FirstByteLatency > 0 {
+		result.ServerFirstByteLatency = time.Duration(envelope.Metrics.FirstByteLatency) * time.Millisecond
+	}
+}
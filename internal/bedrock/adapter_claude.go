@@ -0,0 +1,207 @@
+package bedrock
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+func init() {
+	RegisterAdapter(claudeAdapter{}, "anthropic.")
+}
+
+// claudeAdapter implements the Anthropic Messages API format
+type claudeAdapter struct{}
+
+func (claudeAdapter) Name() string { return "claude" }
+
+func (claudeAdapter) Capabilities() Capabilities {
+	return Capabilities{Streaming: true, PromptCaching: true, Thinking: true, ToolUse: true, Images: true, Documents: true, TopK: true, StopSequences: true}
+}
+
+// BuildRequest prepares a request for Claude models
+func (claudeAdapter) BuildRequest(params *InferenceParams) ([]byte, error) {
+	req := ClaudeRequest{
+		AnthropicVersion: "bedrock-2023-05-31",
+		MaxTokens:        params.MaxTokens,
+		Messages: []ClaudeMessage{
+			{
+				Role:    "user",
+				Content: params.Prompt,
+			},
+		},
+		Temperature:   params.Temperature,
+		TopP:          params.TopP,
+		TopK:          params.TopK,
+		StopSequences: params.StopSequences,
+	}
+	if len(params.Images) > 0 || len(params.Documents) > 0 {
+		content, err := claudeUserContent(params)
+		if err != nil {
+			return nil, err
+		}
+		req.Messages[0].Content = content
+	}
+	if params.ThinkingBudget > 0 {
+		// Extended thinking requires the default temperature and does not
+		// allow top_k or an assistant prefill; the configuration rejects them
+		req.Thinking = &ClaudeThinking{Type: "enabled", BudgetTokens: params.ThinkingBudget}
+		req.Temperature = 0
+		req.TopK = 0
+	} else if params.Prefill != "" && len(params.ToolCalls) == 0 {
+		req.Messages = append(req.Messages, ClaudeMessage{Role: "assistant", Content: params.Prefill})
+	}
+	if len(params.Tools) > 0 {
+		req.Tools, req.ToolChoice = claudeTools(params)
+	}
+	if len(params.ToolCalls) > 0 {
+		req.Messages = append(req.Messages, claudeToolTurn(params)...)
+	}
+	if params.SystemPrompt != "" {
+		req.System = append(req.System, ClaudeSystemBlock{Type: "text", Text: params.SystemPrompt})
+	}
+	if params.SharedPrefix != "" {
+		block := ClaudeSystemBlock{Type: "text", Text: params.SharedPrefix}
+		if params.CachePrefix {
+			block.CacheControl = &ClaudeCacheControl{Type: "ephemeral"}
+		}
+		req.System = append(req.System, block)
+	}
+	return json.Marshal(req)
+}
+
+// ParseResponse parses a Claude response
+func (claudeAdapter) ParseResponse(body []byte, result *InvokeResult) error {
+	var resp ClaudeResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return err
+	}
+
+	result.InputTokens = resp.Usage.InputTokens
+	result.OutputTokens = resp.Usage.OutputTokens
+	result.CacheReadInputTokens = resp.Usage.CacheReadInputTokens
+	result.CacheWriteInputTokens = resp.Usage.CacheCreationInputTokens
+
+	var answer, thinking strings.Builder
+	for _, block := range resp.Content {
+		switch block.Type {
+		case "thinking":
+			thinking.WriteString(block.Thinking)
+		case "text":
+			answer.WriteString(block.Text)
+		}
+	}
+	result.ResponseContent = answer.String()
+	result.ThinkingContent = thinking.String()
+	result.StopReason = resp.StopReason
+	return nil
+}
+
+// ParseStreamChunk parses a Claude streaming event
+func (claudeAdapter) ParseStreamChunk(chunk []byte, result *InvokeResult) (StreamDelta, error) {
+	var streamEvent ClaudeStreamEvent
+	if err := json.Unmarshal(chunk, &streamEvent); err != nil {
+		return StreamDelta{}, fmt.Errorf("invalid Claude stream event: %w", err)
+	}
+
+	var delta StreamDelta
+	switch streamEvent.Type {
+	case "content_block_start":
+		if block := streamEvent.ContentBlock; block != nil && block.Type == "tool_use" {
+			delta.ToolUse = &ToolCall{ID: block.ID, Name: block.Name}
+		}
+	case "content_block_delta":
+		if streamEvent.Delta == nil {
+			break
+		}
+		switch streamEvent.Delta.Type {
+		case "thinking_delta":
+			delta.Thinking = streamEvent.Delta.Thinking
+		case "input_json_delta":
+			delta.ToolInput = streamEvent.Delta.PartialJSON
+		case "text_delta", "":
+			delta.Text = streamEvent.Delta.Text
+		}
+	case "content_block_stop":
+		delta.BlockStop = true
+	}
+
+	// Capture usage information
+	if streamEvent.Type == "message_delta" && streamEvent.Usage != nil {
+		result.OutputTokens = streamEvent.Usage.OutputTokens
+	}
+	if streamEvent.Type == "message_delta" && streamEvent.Delta != nil && streamEvent.Delta.StopReason != "" {
+		result.StopReason = streamEvent.Delta.StopReason
+	}
+
+	if streamEvent.Type == "message_start" && streamEvent.Message != nil {
+		result.InputTokens = streamEvent.Message.Usage.InputTokens
+		result.CacheReadInputTokens = streamEvent.Message.Usage.CacheReadInputTokens
+		result.CacheWriteInputTokens = streamEvent.Message.Usage.CacheCreationInputTokens
+	}
+
+	return delta, nil
+}
+
+// claudeTools returns the tool definitions and tool choice of a Claude request
+func claudeTools(params *InferenceParams) ([]ClaudeTool, *ClaudeToolChoice) {
+	tools := make([]ClaudeTool, 0, len(params.Tools))
+	for _, tool := range params.Tools {
+		tools = append(tools, ClaudeTool{Name: tool.Name, Description: tool.Description, InputSchema: tool.InputSchema})
+	}
+
+	var choice *ClaudeToolChoice
+	switch params.ToolChoice {
+	case "", "auto":
+		choice = &ClaudeToolChoice{Type: "auto"}
+	case "any":
+		choice = &ClaudeToolChoice{Type: "any"}
+	default:
+		choice = &ClaudeToolChoice{Type: "tool", Name: params.ToolChoice}
+	}
+	return tools, choice
+}
+
+// claudeToolTurn returns the assistant tool_use message and the user message
+// answering every tool call with the canned result
+func claudeToolTurn(params *InferenceParams) []ClaudeMessage {
+	var uses, results []ClaudeRequestBlock
+	for _, call := range params.ToolCalls {
+		input := json.RawMessage(call.Input)
+		if !call.ValidInput() {
+			input = json.RawMessage("{}")
+		}
+		uses = append(uses, ClaudeRequestBlock{Type: "tool_use", ID: call.ID, Name: call.Name, Input: input})
+		results = append(results, ClaudeRequestBlock{Type: "tool_result", ToolUseID: call.ID, Content: params.ToolResult})
+	}
+	return []ClaudeMessage{
+		{Role: "assistant", Content: uses},
+		{Role: "user", Content: results},
+	}
+}
+
+// claudeUserContent returns the image and document blocks followed by the
+// prompt text. Claude accepts PDF and plain text documents.
+func claudeUserContent(params *InferenceParams) ([]ClaudeRequestBlock, error) {
+	var blocks []ClaudeRequestBlock
+	for _, image := range params.Images {
+		blocks = append(blocks, ClaudeRequestBlock{
+			Type:   "image",
+			Source: &ClaudeSource{Type: "base64", MediaType: image.MediaType(), Data: base64.StdEncoding.EncodeToString(image.Data)},
+		})
+	}
+	for _, doc := range params.Documents {
+		var source *ClaudeSource
+		switch doc.Format {
+		case "pdf":
+			source = &ClaudeSource{Type: "base64", MediaType: "application/pdf", Data: base64.StdEncoding.EncodeToString(doc.Data)}
+		case "txt":
+			source = &ClaudeSource{Type: "text", MediaType: "text/plain", Data: string(doc.Data)}
+		default:
+			return nil, fmt.Errorf("claude accepts pdf and txt documents, not %s (%s); use test.api \"converse\"", doc.Format, doc.Name)
+		}
+		blocks = append(blocks, ClaudeRequestBlock{Type: "document", Source: source})
+	}
+	return append(blocks, ClaudeRequestBlock{Type: "text", Text: params.Prompt}), nil
+}
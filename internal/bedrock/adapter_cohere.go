@@ -0,0 +1,107 @@
+package bedrock
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+func init() {
+	RegisterAdapter(cohereAdapter{}, "cohere.command-r")
+}
+
+// cohereAdapter implements the Cohere Command R / R+ chat format
+type cohereAdapter struct{}
+
+func (cohereAdapter) Name() string { return "cohere" }
+
+func (cohereAdapter) Capabilities() Capabilities {
+	return Capabilities{Streaming: true, TopK: true, StopSequences: true}
+}
+
+// CohereRequest represents a request to Cohere Command R models
+type CohereRequest struct {
+	Message       string   `json:"message"`
+	Preamble      string   `json:"preamble,omitempty"`
+	MaxTokens     int      `json:"max_tokens"`
+	Temperature   float64  `json:"temperature"`
+	P             float64  `json:"p,omitempty"`
+	K             int      `json:"k,omitempty"`
+	StopSequences []string `json:"stop_sequences,omitempty"`
+}
+
+// CohereResponse represents a response from Cohere Command R models
+type CohereResponse struct {
+	Text         string      `json:"text"`
+	FinishReason string      `json:"finish_reason"`
+	Meta         *CohereMeta `json:"meta,omitempty"`
+}
+
+// CohereMeta carries billing information when Cohere reports it
+type CohereMeta struct {
+	BilledUnits struct {
+		InputTokens  int `json:"input_tokens"`
+		OutputTokens int `json:"output_tokens"`
+	} `json:"billed_units"`
+}
+
+// CohereStreamEvent represents a streaming event from Cohere Command R models
+type CohereStreamEvent struct {
+	EventType    string          `json:"event_type"`
+	Text         string          `json:"text"`
+	IsFinished   bool            `json:"is_finished"`
+	FinishReason string          `json:"finish_reason"`
+	Response     *CohereResponse `json:"response,omitempty"`
+}
+
+// BuildRequest prepares a request for Cohere Command R models. The system
+// prompt is sent as the preamble; Command R does not support prefill.
+func (cohereAdapter) BuildRequest(params *InferenceParams) ([]byte, error) {
+	req := CohereRequest{
+		Message:       params.Prompt,
+		Preamble:      params.systemText(),
+		MaxTokens:     params.MaxTokens,
+		Temperature:   params.Temperature,
+		P:             params.TopP,
+		K:             params.TopK,
+		StopSequences: params.StopSequences,
+	}
+	return json.Marshal(req)
+}
+
+// ParseResponse parses a Cohere response; token usage otherwise comes from the invocation metrics
+func (cohereAdapter) ParseResponse(body []byte, result *InvokeResult) error {
+	var resp CohereResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return err
+	}
+
+	result.ResponseContent = resp.Text
+	result.StopReason = resp.FinishReason
+	if resp.Meta != nil {
+		result.InputTokens = resp.Meta.BilledUnits.InputTokens
+		result.OutputTokens = resp.Meta.BilledUnits.OutputTokens
+	}
+	return nil
+}
+
+// ParseStreamChunk parses a Cohere stream event
+func (cohereAdapter) ParseStreamChunk(chunk []byte, result *InvokeResult) (StreamDelta, error) {
+	var event CohereStreamEvent
+	if err := json.Unmarshal(chunk, &event); err != nil {
+		return StreamDelta{}, fmt.Errorf("invalid Cohere stream event: %w", err)
+	}
+
+	if event.EventType == "stream-end" {
+		result.StopReason = event.FinishReason
+	}
+	if event.EventType == "stream-end" && event.Response != nil && event.Response.Meta != nil {
+		result.InputTokens = event.Response.Meta.BilledUnits.InputTokens
+		result.OutputTokens = event.Response.Meta.BilledUnits.OutputTokens
+	}
+
+	var delta StreamDelta
+	if event.EventType == "text-generation" {
+		delta.Text = event.Text
+	}
+	return delta, nil
+}
@@ -0,0 +1,7 @@
+package bedrock
+
+func init() {
+	// OpenAI open-weight models use chat completions with max_completion_tokens
+	// and control reasoning with reasoning_effort
+	RegisterAdapter(openAIChatAdapter{name: "gpt-oss", maxTokensField: "max_completion_tokens", reasoningEffort: true}, "openai.gpt-oss")
+}
@@ -0,0 +1,6 @@
+package bedrock
+
+func init() {
+	// AI21 Jamba models accept the OpenAI chat completions format
+	RegisterAdapter(openAIChatAdapter{name: "jamba"}, "ai21.jamba")
+}
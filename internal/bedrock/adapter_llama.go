@@ -0,0 +1,100 @@
+package bedrock
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+func init() {
+	RegisterAdapter(llamaAdapter{name: "llama"}, "meta.llama")
+	RegisterAdapter(llamaAdapter{name: "llama2", legacyTemplate: true}, "meta.llama2")
+}
+
+// llamaAdapter implements the Meta Llama text completion format
+type llamaAdapter struct {
+	name string
+	// legacyTemplate selects the Llama 2 [INST] prompt format instead of the Llama 3 chat template
+	legacyTemplate bool
+}
+
+func (a llamaAdapter) Name() string { return a.name }
+
+func (a llamaAdapter) Capabilities() Capabilities {
+	// Llama 3.2 vision models take images; the Llama 2 template has no image
+	// tag. The request has no top_k or stop sequence fields.
+	return Capabilities{Streaming: true, Images: !a.legacyTemplate}
+}
+
+// chatPrompt wraps a user prompt in the model's chat template so the model
+// answers as an assistant instead of continuing the raw text. The system
+// prompt and prefill, if any, are placed in their template positions.
+func (a llamaAdapter) chatPrompt(params *InferenceParams) string {
+	system := params.systemText()
+	if a.legacyTemplate {
+		prompt := params.Prompt
+		if system != "" {
+			prompt = "<<SYS>>\n" + system + "\n<</SYS>>\n\n" + prompt
+		}
+		return "<s>[INST] " + prompt + " [/INST]" + params.Prefill
+	}
+
+	var sb strings.Builder
+	sb.WriteString("<|begin_of_text|>")
+	if system != "" {
+		sb.WriteString("<|start_header_id|>system<|end_header_id|>\n\n" + system + "<|eot_id|>")
+	}
+	sb.WriteString("<|start_header_id|>user<|end_header_id|>\n\n")
+	sb.WriteString(strings.Repeat("<|image|>", len(params.Images)) + params.Prompt + "<|eot_id|>")
+	sb.WriteString("<|start_header_id|>assistant<|end_header_id|>\n\n" + params.Prefill)
+	return sb.String()
+}
+
+// BuildRequest prepares a request for Llama models
+func (a llamaAdapter) BuildRequest(params *InferenceParams) ([]byte, error) {
+	req := LlamaRequest{
+		Prompt:      a.chatPrompt(params),
+		MaxGenLen:   params.MaxTokens,
+		Temperature: params.Temperature,
+		TopP:        params.TopP,
+	}
+	for _, image := range params.Images {
+		req.Images = append(req.Images, image.Data)
+	}
+	return json.Marshal(req)
+}
+
+// ParseResponse parses a Llama response
+func (llamaAdapter) ParseResponse(body []byte, result *InvokeResult) error {
+	var resp LlamaResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return err
+	}
+
+	result.InputTokens = resp.PromptTokenCount
+	result.OutputTokens = resp.GenerationTokenCount
+	result.ResponseContent = resp.Generation
+	result.StopReason = resp.StopReason
+	return nil
+}
+
+// ParseStreamChunk parses a Llama stream chunk. The prompt token count is only
+// set on the first chunk and the generation count is cumulative; the final
+// chunk also carries amazon-bedrock-invocationMetrics.
+func (llamaAdapter) ParseStreamChunk(chunk []byte, result *InvokeResult) (StreamDelta, error) {
+	var resp LlamaResponse
+	if err := json.Unmarshal(chunk, &resp); err != nil {
+		return StreamDelta{}, fmt.Errorf("invalid Llama stream event: %w", err)
+	}
+
+	if resp.PromptTokenCount > 0 {
+		result.InputTokens = resp.PromptTokenCount
+	}
+	if resp.GenerationTokenCount > 0 {
+		result.OutputTokens = resp.GenerationTokenCount
+	}
+	if resp.StopReason != "" {
+		result.StopReason = resp.StopReason
+	}
+	return StreamDelta{Text: resp.Generation}, nil
+}
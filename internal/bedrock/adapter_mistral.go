@@ -0,0 +1,91 @@
+package bedrock
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+func init() {
+	RegisterAdapter(mistralAdapter{}, "mistral.")
+}
+
+// mistralAdapter implements the Mistral text completion format
+type mistralAdapter struct{}
+
+func (mistralAdapter) Name() string { return "mistral" }
+
+func (mistralAdapter) Capabilities() Capabilities {
+	return Capabilities{Streaming: true, TopK: true, StopSequences: true}
+}
+
+// MistralRequest represents a request to Mistral models
+type MistralRequest struct {
+	Prompt      string   `json:"prompt"`
+	MaxTokens   int      `json:"max_tokens"`
+	Temperature float64  `json:"temperature"`
+	TopP        float64  `json:"top_p,omitempty"`
+	TopK        int      `json:"top_k,omitempty"`
+	Stop        []string `json:"stop,omitempty"`
+}
+
+// MistralResponse represents a response (or stream chunk) from Mistral models
+type MistralResponse struct {
+	Outputs []MistralOutput `json:"outputs"`
+}
+
+// MistralOutput represents a single generated output
+type MistralOutput struct {
+	Text       string `json:"text"`
+	StopReason string `json:"stop_reason"`
+}
+
+// BuildRequest prepares a request for Mistral models
+func (mistralAdapter) BuildRequest(params *InferenceParams) ([]byte, error) {
+	// The Mistral instruct template has no system role; the system prompt
+	// leads the first user turn instead
+	prompt := params.Prompt
+	if system := params.systemText(); system != "" {
+		prompt = system + "\n\n" + prompt
+	}
+
+	req := MistralRequest{
+		Prompt:      "<s>[INST] " + prompt + " [/INST]" + params.Prefill,
+		MaxTokens:   params.MaxTokens,
+		Temperature: params.Temperature,
+		TopP:        params.TopP,
+		TopK:        params.TopK,
+		Stop:        params.StopSequences,
+	}
+	return json.Marshal(req)
+}
+
+// ParseResponse parses a Mistral response; token usage comes from the invocation metrics
+func (mistralAdapter) ParseResponse(body []byte, result *InvokeResult) error {
+	var resp MistralResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return err
+	}
+
+	if len(resp.Outputs) > 0 {
+		result.ResponseContent = resp.Outputs[0].Text
+		result.StopReason = resp.Outputs[0].StopReason
+	}
+	return nil
+}
+
+// ParseStreamChunk parses a Mistral stream chunk
+func (mistralAdapter) ParseStreamChunk(chunk []byte, result *InvokeResult) (StreamDelta, error) {
+	var resp MistralResponse
+	if err := json.Unmarshal(chunk, &resp); err != nil {
+		return StreamDelta{}, fmt.Errorf("invalid Mistral stream event: %w", err)
+	}
+
+	var delta StreamDelta
+	if len(resp.Outputs) > 0 {
+		delta.Text = resp.Outputs[0].Text
+		if resp.Outputs[0].StopReason != "" {
+			result.StopReason = resp.Outputs[0].StopReason
+		}
+	}
+	return delta, nil
+}
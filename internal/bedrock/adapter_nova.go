@@ -0,0 +1,194 @@
+package bedrock
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+func init() {
+	RegisterAdapter(novaAdapter{}, "amazon.nova")
+}
+
+// novaAdapter implements the Amazon Nova messages-v1 format
+type novaAdapter struct{}
+
+func (novaAdapter) Name() string { return "nova" }
+
+func (novaAdapter) Capabilities() Capabilities {
+	return Capabilities{Streaming: true, PromptCaching: true, Images: true, Documents: true, TopK: true, StopSequences: true}
+}
+
+// NovaRequest represents a request to Amazon Nova models
+type NovaRequest struct {
+	SchemaVersion   string              `json:"schemaVersion"`
+	System          []NovaContentBlock  `json:"system,omitempty"`
+	Messages        []NovaMessage       `json:"messages"`
+	InferenceConfig NovaInferenceConfig `json:"inferenceConfig"`
+}
+
+// NovaMessage represents a message in a Nova request or response
+type NovaMessage struct {
+	Role    string             `json:"role"`
+	Content []NovaContentBlock `json:"content"`
+}
+
+// NovaContentBlock represents a content block in a Nova message
+type NovaContentBlock struct {
+	Text       string          `json:"text,omitempty"`
+	Image      *NovaMedia      `json:"image,omitempty"`
+	Document   *NovaMedia      `json:"document,omitempty"`
+	CachePoint *NovaCachePoint `json:"cachePoint,omitempty"`
+}
+
+// NovaMedia is an image or document attachment; Source.Bytes is base64
+// encoded by encoding/json and Name is only set for documents
+type NovaMedia struct {
+	Format string `json:"format"`
+	Name   string `json:"name,omitempty"`
+	Source struct {
+		Bytes []byte `json:"bytes"`
+	} `json:"source"`
+}
+
+// NovaCachePoint marks the end of a cacheable prompt prefix
+type NovaCachePoint struct {
+	Type string `json:"type"`
+}
+
+// NovaInferenceConfig represents Nova inference parameters
+type NovaInferenceConfig struct {
+	MaxTokens     int      `json:"maxTokens"`
+	Temperature   float64  `json:"temperature"`
+	TopP          float64  `json:"topP,omitempty"`
+	TopK          int      `json:"topK,omitempty"`
+	StopSequences []string `json:"stopSequences,omitempty"`
+}
+
+// NovaUsage represents token usage reported by Nova
+type NovaUsage struct {
+	InputTokens           int `json:"inputTokens"`
+	OutputTokens          int `json:"outputTokens"`
+	CacheReadInputTokens  int `json:"cacheReadInputTokenCount"`
+	CacheWriteInputTokens int `json:"cacheWriteInputTokenCount"`
+}
+
+// NovaResponse represents a response from Nova models
+type NovaResponse struct {
+	Output struct {
+		Message NovaMessage `json:"message"`
+	} `json:"output"`
+	StopReason string    `json:"stopReason"`
+	Usage      NovaUsage `json:"usage"`
+}
+
+// NovaStreamEvent represents a streaming event from Nova models
+type NovaStreamEvent struct {
+	ContentBlockDelta *struct {
+		Delta struct {
+			Text string `json:"text"`
+		} `json:"delta"`
+	} `json:"contentBlockDelta,omitempty"`
+	MessageStop *struct {
+		StopReason string `json:"stopReason"`
+	} `json:"messageStop,omitempty"`
+	Metadata *struct {
+		Usage *NovaUsage `json:"usage,omitempty"`
+	} `json:"metadata,omitempty"`
+}
+
+// BuildRequest prepares a request for Nova models
+func (novaAdapter) BuildRequest(params *InferenceParams) ([]byte, error) {
+	req := NovaRequest{
+		SchemaVersion: "messages-v1",
+		Messages: []NovaMessage{
+			{
+				Role:    "user",
+				Content: novaUserContent(params),
+			},
+		},
+		InferenceConfig: NovaInferenceConfig{
+			MaxTokens:     params.MaxTokens,
+			Temperature:   params.Temperature,
+			TopP:          params.TopP,
+			TopK:          params.TopK,
+			StopSequences: params.StopSequences,
+		},
+	}
+	if params.Prefill != "" {
+		req.Messages = append(req.Messages, NovaMessage{Role: "assistant", Content: []NovaContentBlock{{Text: params.Prefill}}})
+	}
+	if params.SystemPrompt != "" {
+		req.System = append(req.System, NovaContentBlock{Text: params.SystemPrompt})
+	}
+	if params.SharedPrefix != "" {
+		req.System = append(req.System, NovaContentBlock{Text: params.SharedPrefix})
+		if params.CachePrefix {
+			req.System = append(req.System, NovaContentBlock{CachePoint: &NovaCachePoint{Type: "default"}})
+		}
+	}
+	return json.Marshal(req)
+}
+
+// novaUserContent returns the image and document blocks followed by the prompt text
+func novaUserContent(params *InferenceParams) []NovaContentBlock {
+	var content []NovaContentBlock
+	for _, image := range params.Images {
+		media := &NovaMedia{Format: image.Format}
+		media.Source.Bytes = image.Data
+		content = append(content, NovaContentBlock{Image: media})
+	}
+	for _, doc := range params.Documents {
+		media := &NovaMedia{Format: doc.Format, Name: converseDocumentName(doc.Name)}
+		media.Source.Bytes = doc.Data
+		content = append(content, NovaContentBlock{Document: media})
+	}
+	return append(content, NovaContentBlock{Text: params.Prompt})
+}
+
+// ParseResponse parses a Nova response
+func (novaAdapter) ParseResponse(body []byte, result *InvokeResult) error {
+	var resp NovaResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return err
+	}
+
+	applyNovaUsage(&resp.Usage, result)
+
+	var contentBuilder strings.Builder
+	for _, block := range resp.Output.Message.Content {
+		contentBuilder.WriteString(block.Text)
+	}
+	result.ResponseContent = contentBuilder.String()
+	result.StopReason = resp.StopReason
+	return nil
+}
+
+// ParseStreamChunk parses a Nova stream chunk
+func (novaAdapter) ParseStreamChunk(chunk []byte, result *InvokeResult) (StreamDelta, error) {
+	var event NovaStreamEvent
+	if err := json.Unmarshal(chunk, &event); err != nil {
+		return StreamDelta{}, fmt.Errorf("invalid Nova stream event: %w", err)
+	}
+
+	if event.Metadata != nil && event.Metadata.Usage != nil {
+		applyNovaUsage(event.Metadata.Usage, result)
+	}
+	if event.MessageStop != nil {
+		result.StopReason = event.MessageStop.StopReason
+	}
+
+	var delta StreamDelta
+	if event.ContentBlockDelta != nil {
+		delta.Text = event.ContentBlockDelta.Delta.Text
+	}
+	return delta, nil
+}
+
+// applyNovaUsage copies Nova token usage, including cache reads and writes, onto result
+func applyNovaUsage(usage *NovaUsage, result *InvokeResult) {
+	result.InputTokens = usage.InputTokens
+	result.OutputTokens = usage.OutputTokens
+	result.CacheReadInputTokens = usage.CacheReadInputTokens
+	result.CacheWriteInputTokens = usage.CacheWriteInputTokens
+}
@@ -0,0 +1,110 @@
+package bedrock
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+func init() {
+	RegisterAdapter(openAIChatAdapter{name: "deepseek"}, "deepseek.")
+	RegisterAdapter(openAIChatAdapter{name: "qwen"}, "qwen.")
+	RegisterAdapter(openAIChatAdapter{name: "openai-chat"})
+}
+
+// openAIChatAdapter implements the OpenAI chat completions format used by
+// DeepSeek, Qwen and other open-weight models on Bedrock
+type openAIChatAdapter struct {
+	name string
+	// maxTokensField is the request field for the output limit (default "max_tokens")
+	maxTokensField string
+	// reasoningEffort means the model takes a reasoning_effort field
+	reasoningEffort bool
+}
+
+func (a openAIChatAdapter) Name() string { return a.name }
+
+func (a openAIChatAdapter) Capabilities() Capabilities {
+	return Capabilities{Streaming: true, StopSequences: true, ReasoningEffort: a.reasoningEffort}
+}
+
+// BuildRequest prepares an OpenAI-style chat request (no anthropic_version)
+func (a openAIChatAdapter) BuildRequest(params *InferenceParams) ([]byte, error) {
+	maxTokensField := a.maxTokensField
+	if maxTokensField == "" {
+		maxTokensField = "max_tokens"
+	}
+
+	var messages []map[string]string
+	if system := params.systemText(); system != "" {
+		messages = append(messages, map[string]string{"role": "system", "content": system})
+	}
+	messages = append(messages, map[string]string{"role": "user", "content": params.Prompt})
+	if params.Prefill != "" {
+		messages = append(messages, map[string]string{"role": "assistant", "content": params.Prefill})
+	}
+
+	req := map[string]interface{}{
+		"messages":     messages,
+		maxTokensField: params.MaxTokens,
+		"temperature":  params.Temperature,
+	}
+	if params.TopP > 0 {
+		req["top_p"] = params.TopP
+	}
+	if len(params.StopSequences) > 0 {
+		req["stop"] = params.StopSequences
+	}
+	if a.reasoningEffort && params.ReasoningEffort != "" {
+		req["reasoning_effort"] = params.ReasoningEffort
+	}
+	return json.Marshal(req)
+}
+
+// ParseResponse parses an OpenAI-style chat response
+func (openAIChatAdapter) ParseResponse(body []byte, result *InvokeResult) error {
+	var resp DeepSeekResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return err
+	}
+
+	applyChatUsage(&resp.Usage, result)
+
+	if len(resp.Choices) > 0 {
+		result.ResponseContent = resp.Choices[0].Message.Content
+		result.ThinkingContent = resp.Choices[0].Message.ReasoningContent
+		result.StopReason = resp.Choices[0].FinishReason
+	}
+	return nil
+}
+
+// ParseStreamChunk parses an OpenAI-style chat completion chunk
+func (a openAIChatAdapter) ParseStreamChunk(chunk []byte, result *InvokeResult) (StreamDelta, error) {
+	var streamEvent DeepSeekStreamEvent
+	if err := json.Unmarshal(chunk, &streamEvent); err != nil {
+		return StreamDelta{}, fmt.Errorf("invalid %s stream event: %w", a.name, err)
+	}
+
+	if streamEvent.Usage != nil {
+		applyChatUsage(streamEvent.Usage, result)
+	}
+
+	if len(streamEvent.Choices) > 0 && streamEvent.Choices[0].FinishReason != "" {
+		result.StopReason = streamEvent.Choices[0].FinishReason
+	}
+
+	var delta StreamDelta
+	if len(streamEvent.Choices) > 0 && streamEvent.Choices[0].Delta != nil {
+		delta.Text = streamEvent.Choices[0].Delta.Content
+		delta.Thinking = streamEvent.Choices[0].Delta.ReasoningContent
+	}
+	return delta, nil
+}
+
+// applyChatUsage copies chat completion token usage, including reported reasoning tokens, onto result
+func applyChatUsage(usage *DeepSeekUsage, result *InvokeResult) {
+	result.InputTokens = usage.PromptTokens
+	result.OutputTokens = usage.CompletionTokens
+	if usage.CompletionTokensDetails != nil {
+		result.ThinkingTokens = usage.CompletionTokensDetails.ReasoningTokens
+	}
+}
@@ -0,0 +1,351 @@
+package bedrock
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestResolveAdapter(t *testing.T) {
+	tests := []struct {
+		modelID string
+		format  string
+		want    string
+	}{
+		{"anthropic.claude-3-haiku-20240307-v1:0", "", "claude"},
+		{"us.anthropic.claude-3-5-sonnet-20241022-v2:0", "", "claude"},
+		{"meta.llama3-8b-instruct-v1:0", "", "llama"},
+		// meta.llama2 is longer than meta.llama and wins
+		{"meta.llama2-13b-chat-v1", "", "llama2"},
+		{"META.LLAMA2-13B-CHAT-V1", "", "llama2"},
+		{"mistral.mistral-7b-instruct-v0:2", "", "mistral"},
+		{"deepseek.r1-v1:0", "", "deepseek"},
+		{"qwen.qwen3-32b-v1:0", "", "qwen"},
+		{"amazon.nova-lite-v1:0", "", "nova"},
+		{"amazon.titan-text-express-v1", "", "titan"},
+		{"cohere.command-r-plus-v1:0", "", "cohere"},
+		{"ai21.jamba-1-5-mini-v1:0", "", "jamba"},
+		{"openai.gpt-oss-20b-1:0", "", "gpt-oss"},
+		// An explicit format overrides the model ID prefix
+		{"meta.llama3-8b-instruct-v1:0", "llama2", "llama2"},
+		{"anthropic.claude-3-haiku-20240307-v1:0", "Mistral", "mistral"},
+		{"arn:aws:bedrock:us-east-1:123456789012:provisioned-model/abc123", "claude", "claude"},
+	}
+	for _, tt := range tests {
+		adapter, err := ResolveAdapter(tt.modelID, tt.format)
+		if err != nil {
+			t.Errorf("ResolveAdapter(%q, %q) error: %v", tt.modelID, tt.format, err)
+			continue
+		}
+		if adapter.Name() != tt.want {
+			t.Errorf("ResolveAdapter(%q, %q) = %s, want %s", tt.modelID, tt.format, adapter.Name(), tt.want)
+		}
+	}
+
+	for _, tt := range []struct{ modelID, format string }{
+		{"acme.unknown-model-v1", ""},
+		// Titan embeddings and older Cohere Command models have no adapter
+		{"amazon.titan-embed-text-v2:0", ""},
+		{"cohere.command-text-v14", ""},
+		{"anthropic.claude-3-haiku-20240307-v1:0", "no-such-format"},
+		// Provisioned throughput carries no family information
+		{"arn:aws:bedrock:us-east-1:123456789012:provisioned-model/abc123", ""},
+	} {
+		if adapter, err := ResolveAdapter(tt.modelID, tt.format); err == nil {
+			t.Errorf("ResolveAdapter(%q, %q) = %s, want an error", tt.modelID, tt.format, adapter.Name())
+		}
+	}
+}
+
+// familyCase is a request/response round trip of one model family. request
+// maps top-level fields of the request body to their expected JSON; an empty
+// value means the field must be absent.
+type familyCase struct {
+	modelID  string
+	params   InferenceParams
+	request  map[string]string
+	response string
+	chunks   []string
+	// want is the parsed text and token usage of both the response and the stream
+	want InvokeResult
+}
+
+// testParams are the settings every family case starts from
+func testParams() InferenceParams {
+	return InferenceParams{
+		Prompt:        "Hi",
+		MaxTokens:     64,
+		Temperature:   0.5,
+		SystemPrompt:  "Be brief.",
+		TopK:          5,
+		StopSequences: []string{"END"},
+	}
+}
+
+var familyCases = []familyCase{
+	{
+		modelID: "anthropic.claude-3-haiku-20240307-v1:0",
+		params:  testParams(),
+		request: map[string]string{
+			"anthropic_version": `"bedrock-2023-05-31"`,
+			"max_tokens":        `64`,
+			"temperature":       `0.5`,
+			"top_k":             `5`,
+			"stop_sequences":    `["END"]`,
+			"system":            `[{"type": "text", "text": "Be brief."}]`,
+			"messages":          `[{"role": "user", "content": "Hi"}]`,
+		},
+		response: `{"content": [{"type": "text", "text": "Hello"}], "stop_reason": "end_turn", "usage": {"input_tokens": 12, "output_tokens": 3}}`,
+		chunks: []string{
+			`{"type": "message_start", "message": {"usage": {"input_tokens": 12}}}`,
+			`{"type": "content_block_delta", "delta": {"type": "text_delta", "text": "Hel"}}`,
+			`{"type": "content_block_delta", "delta": {"type": "text_delta", "text": "lo"}}`,
+			`{"type": "message_delta", "delta": {"stop_reason": "end_turn"}, "usage": {"output_tokens": 3}}`,
+		},
+		want: InvokeResult{ResponseContent: "Hello", InputTokens: 12, OutputTokens: 3, StopReason: "end_turn"},
+	},
+	{
+		modelID: "meta.llama3-8b-instruct-v1:0",
+		params:  testParams(),
+		request: map[string]string{
+			"prompt":      `"<|begin_of_text|><|start_header_id|>system<|end_header_id|>\n\nBe brief.<|eot_id|><|start_header_id|>user<|end_header_id|>\n\nHi<|eot_id|><|start_header_id|>assistant<|end_header_id|>\n\n"`,
+			"max_gen_len": `64`,
+			"temperature": `0.5`,
+			"top_k":       ``,
+			"stop":        ``,
+		},
+		response: `{"generation": "Hello", "prompt_token_count": 12, "generation_token_count": 3, "stop_reason": "stop"}`,
+		chunks: []string{
+			`{"generation": "Hel", "prompt_token_count": 12, "generation_token_count": 1}`,
+			`{"generation": "lo", "generation_token_count": 3, "stop_reason": "stop"}`,
+		},
+		want: InvokeResult{ResponseContent: "Hello", InputTokens: 12, OutputTokens: 3, StopReason: "stop"},
+	},
+	{
+		modelID: "meta.llama2-13b-chat-v1",
+		params:  testParams(),
+		request: map[string]string{
+			"prompt":      `"<s>[INST] <<SYS>>\nBe brief.\n<</SYS>>\n\nHi [/INST]"`,
+			"max_gen_len": `64`,
+		},
+		response: `{"generation": "Hello", "prompt_token_count": 12, "generation_token_count": 3, "stop_reason": "stop"}`,
+		chunks: []string{
+			`{"generation": "Hello", "prompt_token_count": 12, "generation_token_count": 3, "stop_reason": "stop"}`,
+		},
+		want: InvokeResult{ResponseContent: "Hello", InputTokens: 12, OutputTokens: 3, StopReason: "stop"},
+	},
+	{
+		// Mistral reports usage only in the invocation metrics
+		modelID: "mistral.mistral-7b-instruct-v0:2",
+		params:  testParams(),
+		request: map[string]string{
+			"prompt":     `"<s>[INST] Be brief.\n\nHi [/INST]"`,
+			"max_tokens": `64`,
+			"top_k":      `5`,
+			"stop":       `["END"]`,
+		},
+		response: `{"outputs": [{"text": "Hello", "stop_reason": "stop"}]}`,
+		chunks: []string{
+			`{"outputs": [{"text": "Hel"}]}`,
+			`{"outputs": [{"text": "lo", "stop_reason": "stop"}]}`,
+		},
+		want: InvokeResult{ResponseContent: "Hello", StopReason: "stop"},
+	},
+	{
+		modelID: "deepseek.r1-v1:0",
+		params:  testParams(),
+		request: map[string]string{
+			"messages":   `[{"role": "system", "content": "Be brief."}, {"role": "user", "content": "Hi"}]`,
+			"max_tokens": `64`,
+			"stop":       `["END"]`,
+			"top_k":      ``,
+		},
+		response: `{"choices": [{"message": {"content": "Hello", "reasoning_content": "Greet."}, "finish_reason": "stop"}], "usage": {"prompt_tokens": 12, "completion_tokens": 3}}`,
+		chunks: []string{
+			`{"choices": [{"delta": {"reasoning_content": "Greet."}}]}`,
+			`{"choices": [{"delta": {"content": "Hel"}}]}`,
+			`{"choices": [{"delta": {"content": "lo"}, "finish_reason": "stop"}], "usage": {"prompt_tokens": 12, "completion_tokens": 3}}`,
+		},
+		want: InvokeResult{ResponseContent: "Hello", ThinkingContent: "Greet.", InputTokens: 12, OutputTokens: 3, StopReason: "stop"},
+	},
+	{
+		modelID: "qwen.qwen3-32b-v1:0",
+		params:  testParams(),
+		request: map[string]string{
+			"messages":   `[{"role": "system", "content": "Be brief."}, {"role": "user", "content": "Hi"}]`,
+			"max_tokens": `64`,
+		},
+		response: `{"choices": [{"message": {"content": "Hello"}, "finish_reason": "stop"}], "usage": {"prompt_tokens": 12, "completion_tokens": 3}}`,
+		chunks: []string{
+			`{"choices": [{"delta": {"content": "Hello"}, "finish_reason": "stop"}], "usage": {"prompt_tokens": 12, "completion_tokens": 3}}`,
+		},
+		want: InvokeResult{ResponseContent: "Hello", InputTokens: 12, OutputTokens: 3, StopReason: "stop"},
+	},
+	{
+		modelID: "amazon.nova-lite-v1:0",
+		params:  testParams(),
+		request: map[string]string{
+			"schemaVersion":   `"messages-v1"`,
+			"system":          `[{"text": "Be brief."}]`,
+			"messages":        `[{"role": "user", "content": [{"text": "Hi"}]}]`,
+			"inferenceConfig": `{"maxTokens": 64, "temperature": 0.5, "topK": 5, "stopSequences": ["END"]}`,
+		},
+		response: `{"output": {"message": {"role": "assistant", "content": [{"text": "Hello"}]}}, "stopReason": "end_turn", "usage": {"inputTokens": 12, "outputTokens": 3}}`,
+		chunks: []string{
+			`{"contentBlockDelta": {"delta": {"text": "Hel"}}}`,
+			`{"contentBlockDelta": {"delta": {"text": "lo"}}}`,
+			`{"messageStop": {"stopReason": "end_turn"}}`,
+			`{"metadata": {"usage": {"inputTokens": 12, "outputTokens": 3}}}`,
+		},
+		want: InvokeResult{ResponseContent: "Hello", InputTokens: 12, OutputTokens: 3, StopReason: "end_turn"},
+	},
+	{
+		modelID: "amazon.titan-text-express-v1",
+		params:  testParams(),
+		request: map[string]string{
+			"inputText":            `"Be brief.\n\nUser: Hi\nBot:"`,
+			"textGenerationConfig": `{"maxTokenCount": 64, "temperature": 0.5, "stopSequences": ["END"]}`,
+		},
+		response: `{"inputTextTokenCount": 12, "results": [{"tokenCount": 3, "outputText": "Hello", "completionReason": "FINISH"}]}`,
+		chunks: []string{
+			`{"outputText": "Hel", "inputTextTokenCount": 12, "totalOutputTextTokenCount": 1}`,
+			`{"outputText": "lo", "totalOutputTextTokenCount": 3, "completionReason": "FINISH"}`,
+		},
+		want: InvokeResult{ResponseContent: "Hello", InputTokens: 12, OutputTokens: 3, StopReason: "FINISH"},
+	},
+	{
+		modelID: "cohere.command-r-v1:0",
+		params:  testParams(),
+		request: map[string]string{
+			"message":        `"Hi"`,
+			"preamble":       `"Be brief."`,
+			"max_tokens":     `64`,
+			"k":              `5`,
+			"stop_sequences": `["END"]`,
+		},
+		response: `{"text": "Hello", "finish_reason": "COMPLETE", "meta": {"billed_units": {"input_tokens": 12, "output_tokens": 3}}}`,
+		chunks: []string{
+			`{"event_type": "stream-start"}`,
+			`{"event_type": "text-generation", "text": "Hel"}`,
+			`{"event_type": "text-generation", "text": "lo"}`,
+			`{"event_type": "stream-end", "is_finished": true, "finish_reason": "COMPLETE", "response": {"meta": {"billed_units": {"input_tokens": 12, "output_tokens": 3}}}}`,
+		},
+		want: InvokeResult{ResponseContent: "Hello", InputTokens: 12, OutputTokens: 3, StopReason: "COMPLETE"},
+	},
+	{
+		modelID: "ai21.jamba-1-5-mini-v1:0",
+		params:  testParams(),
+		request: map[string]string{
+			"messages":   `[{"role": "system", "content": "Be brief."}, {"role": "user", "content": "Hi"}]`,
+			"max_tokens": `64`,
+			"stop":       `["END"]`,
+		},
+		response: `{"choices": [{"message": {"content": "Hello"}, "finish_reason": "stop"}], "usage": {"prompt_tokens": 12, "completion_tokens": 3}}`,
+		chunks: []string{
+			`{"choices": [{"delta": {"content": "Hel"}}]}`,
+			`{"choices": [{"delta": {"content": "lo"}, "finish_reason": "stop"}], "usage": {"prompt_tokens": 12, "completion_tokens": 3}}`,
+		},
+		want: InvokeResult{ResponseContent: "Hello", InputTokens: 12, OutputTokens: 3, StopReason: "stop"},
+	},
+	{
+		// gpt-oss takes max_completion_tokens and a reasoning effort
+		modelID: "openai.gpt-oss-20b-1:0",
+		params: func() InferenceParams {
+			p := testParams()
+			p.ReasoningEffort = "low"
+			return p
+		}(),
+		request: map[string]string{
+			"max_completion_tokens": `64`,
+			"max_tokens":            ``,
+			"reasoning_effort":      `"low"`,
+		},
+		response: `{"choices": [{"message": {"content": "Hello", "reasoning_content": "Greet."}, "finish_reason": "stop"}], "usage": {"prompt_tokens": 12, "completion_tokens": 3}}`,
+		chunks: []string{
+			`{"choices": [{"delta": {"reasoning_content": "Greet."}}]}`,
+			`{"choices": [{"delta": {"content": "Hello"}, "finish_reason": "stop"}], "usage": {"prompt_tokens": 12, "completion_tokens": 3}}`,
+		},
+		want: InvokeResult{ResponseContent: "Hello", ThinkingContent: "Greet.", InputTokens: 12, OutputTokens: 3, StopReason: "stop"},
+	},
+}
+
+func TestAdapterFamilies(t *testing.T) {
+	for _, tt := range familyCases {
+		t.Run(tt.modelID, func(t *testing.T) {
+			adapter, err := ResolveAdapter(tt.modelID, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			body, err := adapter.BuildRequest(&tt.params)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var fields map[string]json.RawMessage
+			if err := json.Unmarshal(body, &fields); err != nil {
+				t.Fatalf("request %s: %v", body, err)
+			}
+			for field, want := range tt.request {
+				got, ok := fields[field]
+				switch {
+				case want == "" && ok:
+					t.Errorf("request has %s = %s, want it absent", field, got)
+				case want != "" && !sameJSON(t, got, want):
+					t.Errorf("request %s = %s, want %s", field, got, want)
+				}
+			}
+
+			result := &InvokeResult{}
+			if err := adapter.ParseResponse([]byte(tt.response), result); err != nil {
+				t.Fatalf("response: %v", err)
+			}
+			checkParsed(t, "response", result, tt.want)
+
+			streamed := &InvokeResult{}
+			var text, thinking strings.Builder
+			for _, chunk := range tt.chunks {
+				delta, err := adapter.ParseStreamChunk([]byte(chunk), streamed)
+				if err != nil {
+					t.Fatalf("chunk %s: %v", chunk, err)
+				}
+				text.WriteString(delta.Text)
+				thinking.WriteString(delta.Thinking)
+			}
+			streamed.ResponseContent = text.String()
+			streamed.ThinkingContent = thinking.String()
+			checkParsed(t, "stream", streamed, tt.want)
+
+			if _, err := adapter.ParseStreamChunk([]byte("not json"), &InvokeResult{}); err == nil {
+				t.Error("malformed chunk parsed without an error")
+			}
+		})
+	}
+}
+
+// sameJSON reports whether got and want encode the same JSON value
+func sameJSON(t *testing.T, got json.RawMessage, want string) bool {
+	t.Helper()
+	var g, w interface{}
+	if err := json.Unmarshal(got, &g); err != nil {
+		return false
+	}
+	if err := json.Unmarshal([]byte(want), &w); err != nil {
+		t.Fatalf("expected JSON %s: %v", want, err)
+	}
+	return reflect.DeepEqual(g, w)
+}
+
+// checkParsed compares the text and token usage parsed from a response or stream
+func checkParsed(t *testing.T, source string, got *InvokeResult, want InvokeResult) {
+	t.Helper()
+	if got.ResponseContent != want.ResponseContent || got.ThinkingContent != want.ThinkingContent {
+		t.Errorf("%s text = %q (thinking %q), want %q (thinking %q)", source, got.ResponseContent, got.ThinkingContent, want.ResponseContent, want.ThinkingContent)
+	}
+	if got.InputTokens != want.InputTokens || got.OutputTokens != want.OutputTokens {
+		t.Errorf("%s tokens = %d in / %d out, want %d / %d", source, got.InputTokens, got.OutputTokens, want.InputTokens, want.OutputTokens)
+	}
+	if got.StopReason != want.StopReason {
+		t.Errorf("%s stop reason = %q, want %q", source, got.StopReason, want.StopReason)
+	}
+}
@@ -0,0 +1,113 @@
+package bedrock
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+func init() {
+	RegisterAdapter(titanAdapter{}, "amazon.titan-text")
+}
+
+// titanAdapter implements the Amazon Titan Text format
+type titanAdapter struct{}
+
+func (titanAdapter) Name() string { return "titan" }
+
+func (titanAdapter) Capabilities() Capabilities {
+	return Capabilities{Streaming: true, StopSequences: true}
+}
+
+// TitanRequest represents a request to Titan Text models
+type TitanRequest struct {
+	InputText            string                    `json:"inputText"`
+	TextGenerationConfig TitanTextGenerationConfig `json:"textGenerationConfig"`
+}
+
+// TitanTextGenerationConfig represents Titan inference parameters
+type TitanTextGenerationConfig struct {
+	MaxTokenCount int      `json:"maxTokenCount"`
+	Temperature   float64  `json:"temperature"`
+	TopP          float64  `json:"topP,omitempty"`
+	StopSequences []string `json:"stopSequences,omitempty"`
+}
+
+// TitanResponse represents a response from Titan Text models
+type TitanResponse struct {
+	InputTextTokenCount int           `json:"inputTextTokenCount"`
+	Results             []TitanResult `json:"results"`
+}
+
+// TitanResult represents a single Titan generation
+type TitanResult struct {
+	TokenCount       int    `json:"tokenCount"`
+	OutputText       string `json:"outputText"`
+	CompletionReason string `json:"completionReason"`
+}
+
+// TitanStreamChunk represents a streaming chunk from Titan Text models
+type TitanStreamChunk struct {
+	OutputText                string `json:"outputText"`
+	TotalOutputTextTokenCount int    `json:"totalOutputTextTokenCount"`
+	InputTextTokenCount       int    `json:"inputTextTokenCount"`
+	CompletionReason          string `json:"completionReason"`
+}
+
+// BuildRequest prepares a request for Titan Text models using the
+// conversational "User:/Bot:" convention Titan is tuned for
+func (titanAdapter) BuildRequest(params *InferenceParams) ([]byte, error) {
+	// Titan has no system role; the system prompt precedes the conversation
+	inputText := "User: " + params.Prompt + "\nBot:"
+	if system := params.systemText(); system != "" {
+		inputText = system + "\n\n" + inputText
+	}
+	if params.Prefill != "" {
+		inputText += " " + params.Prefill
+	}
+
+	req := TitanRequest{
+		InputText: inputText,
+		TextGenerationConfig: TitanTextGenerationConfig{
+			MaxTokenCount: params.MaxTokens,
+			Temperature:   params.Temperature,
+			TopP:          params.TopP,
+			StopSequences: params.StopSequences,
+		},
+	}
+	return json.Marshal(req)
+}
+
+// ParseResponse parses a Titan Text response
+func (titanAdapter) ParseResponse(body []byte, result *InvokeResult) error {
+	var resp TitanResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return err
+	}
+
+	result.InputTokens = resp.InputTextTokenCount
+	if len(resp.Results) > 0 {
+		result.OutputTokens = resp.Results[0].TokenCount
+		result.ResponseContent = resp.Results[0].OutputText
+		result.StopReason = resp.Results[0].CompletionReason
+	}
+	return nil
+}
+
+// ParseStreamChunk parses a Titan Text stream chunk
+func (titanAdapter) ParseStreamChunk(chunk []byte, result *InvokeResult) (StreamDelta, error) {
+	var resp TitanStreamChunk
+	if err := json.Unmarshal(chunk, &resp); err != nil {
+		return StreamDelta{}, fmt.Errorf("invalid Titan stream event: %w", err)
+	}
+
+	if resp.InputTextTokenCount > 0 {
+		result.InputTokens = resp.InputTextTokenCount
+	}
+	if resp.TotalOutputTextTokenCount > 0 {
+		result.OutputTokens = resp.TotalOutputTextTokenCount
+	}
+	if resp.CompletionReason != "" {
+		result.StopReason = resp.CompletionReason
+	}
+	return StreamDelta{Text: resp.OutputText}, nil
+}
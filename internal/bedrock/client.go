@@ -2,18 +2,25 @@ package bedrock
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"io"
+	"net"
+	"net/http"
+	"net/url"
 	"os"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
 	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime/types"
+	smithyauth "github.com/aws/smithy-go/auth"
+	"github.com/aws/smithy-go/middleware"
 )
 
 // ServiceTier represents the Bedrock service tier
@@ -25,6 +32,14 @@ const (
 	ServiceTierFlex     ServiceTier = "flex"
 )
 
+// PerformanceLatency represents the Bedrock inference latency mode
+type PerformanceLatency string
+
+const (
+	PerformanceLatencyStandard  PerformanceLatency = "standard"
+	PerformanceLatencyOptimized PerformanceLatency = "optimized"
+)
+
 // ClientConfig holds the configuration needed to create a Bedrock client
 type ClientConfig struct {
 	Region      string
@@ -33,7 +48,51 @@ type ClientConfig struct {
 	ModelID     string
 	MaxTokens   int
 	Temperature float64
-	ServiceTier ServiceTier
+	// EndpointURL overrides the regional endpoint (VPC endpoint or local server)
+	EndpointURL  string
+	UseFIPS      bool
+	UseDualStack bool
+	// Anonymous sends unsigned requests, for local stand-in servers
+	Anonymous bool
+	// WrapTransport, when set, wraps each client's HTTP transport, e.g. to
+	// record or replay traffic
+	WrapTransport func(http.RoundTripper) http.RoundTripper
+	// MaxAttempts, RetryMode and MaxBackoff override the SDK retry policy
+	// when set; RequestTimeout bounds each invocation, retries and stream
+	// reading included
+	MaxAttempts    int
+	RetryMode      string
+	MaxBackoff     time.Duration
+	RequestTimeout time.Duration
+	// Optional prompt and sampling settings; zero values are not sent
+	SystemPrompt  string
+	TopP          float64
+	TopK          int
+	StopSequences []string
+	Prefill       string
+	// ThinkingBudget enables extended thinking with this many tokens (0 disables it)
+	ThinkingBudget int
+	// ReasoningEffort is "low", "medium" or "high" for models with effort control
+	ReasoningEffort string
+	ServiceTier     ServiceTier
+	// PerformanceLatency requests latency-optimized inference when "optimized"
+	PerformanceLatency PerformanceLatency
+	API                API
+	// Format overrides adapter resolution from the model ID (e.g. for ARNs)
+	Format string
+	// SharedPrefix is sent ahead of every prompt as a system prompt/document
+	SharedPrefix string
+	// PromptCaching places a cache breakpoint after SharedPrefix
+	PromptCaching bool
+	// Tools are offered with every request; ToolChoice is "auto", "any" or a
+	// tool name. ToolResult, when set, is the canned result that workers send
+	// back in a second turn for every tool call.
+	Tools      []ToolSpec
+	ToolChoice string
+	ToolResult string
+	// Images and Documents are attached to the user message of every request
+	Images    []Image
+	Documents []Document
 }
 
 // Client wraps the AWS Bedrock Runtime client
@@ -43,672 +102,446 @@ type Client struct {
 	maxTokens   int
 	temperature float64
 	serviceTier ServiceTier
+	latency     PerformanceLatency
+	api         API
+	adapter     ModelAdapter
+	adapterErr  error
+
+	systemPrompt  string
+	topP          float64
+	topK          int
+	stopSequences []string
+	prefill       string
+	thinking      int
+	effort        string
+
+	sharedPrefix  string
+	promptCaching bool
+
+	tools      []ToolSpec
+	toolChoice string
+	toolResult string
+
+	images    []Image
+	documents []Document
+
+	requestTimeout time.Duration
 }
 
 // NewClient creates a new Bedrock client
 func NewClient(region, accessKey, secretKey, modelID string, maxTokens int, temperature float64) *Client {
-	var cfg aws.Config
-	var err error
-
-	// If credentials are empty, use default credential chain (env, shared credentials, IAM role, etc.)
-	if accessKey == "" || secretKey == "" {
-		cfg, err = config.LoadDefaultConfig(context.Background(), config.WithRegion(region))
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to load default AWS config: %v\n", err)
-			// Fallback to empty config
-			cfg = aws.Config{Region: region}
-		}
-	} else {
-		cfg = aws.Config{
-			Region: region,
-			Credentials: credentials.NewStaticCredentialsProvider(
-				accessKey,
-				secretKey,
-				"",
-			),
-		}
-	}
-
-	return &Client{
-		client:      bedrockruntime.NewFromConfig(cfg),
-		modelID:     modelID,
-		maxTokens:   maxTokens,
-		temperature: temperature,
-		serviceTier: ServiceTierDefault,
-	}
+	return NewClientFromConfig(&ClientConfig{
+		Region:      region,
+		AccessKey:   accessKey,
+		SecretKey:   secretKey,
+		ModelID:     modelID,
+		MaxTokens:   maxTokens,
+		Temperature: temperature,
+	})
 }
 
 // NewClientFromConfig creates a new Bedrock client from ClientConfig
 func NewClientFromConfig(cfg *ClientConfig) *Client {
-	client := NewClient(cfg.Region, cfg.AccessKey, cfg.SecretKey, cfg.ModelID, cfg.MaxTokens, cfg.Temperature)
+	awsCfg := loadAWSConfig(cfg)
+
+	client := &Client{
+		client: bedrockruntime.NewFromConfig(awsCfg, func(o *bedrockruntime.Options) {
+			if cfg.EndpointURL != "" {
+				o.BaseEndpoint = aws.String(cfg.EndpointURL)
+			}
+			if cfg.UseFIPS {
+				o.EndpointOptions.UseFIPSEndpoint = aws.FIPSEndpointStateEnabled
+			}
+			if cfg.UseDualStack {
+				o.EndpointOptions.UseDualStackEndpoint = aws.DualStackEndpointStateEnabled
+			}
+			if cfg.Anonymous {
+				o.AuthSchemeResolver = anonymousAuthResolver{}
+			}
+			if cfg.WrapTransport != nil {
+				o.HTTPClient = &http.Client{Transport: cfg.WrapTransport(awshttp.NewBuildableClient().GetTransport())}
+			}
+			if cfg.MaxAttempts > 0 || cfg.RetryMode != "" || cfg.MaxBackoff > 0 {
+				o.Retryer = newRetryer(cfg)
+			}
+			o.APIOptions = append(o.APIOptions, addAttemptCounter)
+		}),
+		modelID:     cfg.ModelID,
+		maxTokens:   cfg.MaxTokens,
+		temperature: cfg.Temperature,
+		serviceTier: ServiceTierDefault,
+		latency:     PerformanceLatencyStandard,
+		api:         APIInvoke,
+	}
 	if cfg.ServiceTier != "" {
 		client.serviceTier = cfg.ServiceTier
 	}
+	if cfg.PerformanceLatency != "" {
+		client.latency = cfg.PerformanceLatency
+	}
+	if cfg.API != "" {
+		client.api = cfg.API
+	}
+	client.adapter, client.adapterErr = ResolveAdapter(cfg.ModelID, cfg.Format)
+	client.systemPrompt = cfg.SystemPrompt
+	client.topP = cfg.TopP
+	client.topK = cfg.TopK
+	client.stopSequences = cfg.StopSequences
+	client.prefill = cfg.Prefill
+	client.thinking = cfg.ThinkingBudget
+	client.effort = cfg.ReasoningEffort
+	client.sharedPrefix = cfg.SharedPrefix
+	client.promptCaching = cfg.PromptCaching
+	client.tools = cfg.Tools
+	client.toolChoice = cfg.ToolChoice
+	client.toolResult = cfg.ToolResult
+	client.images = cfg.Images
+	client.documents = cfg.Documents
+	client.requestTimeout = cfg.RequestTimeout
 	return client
 }
 
+// newRetryer returns the SDK retryer for the retry settings of cfg; unset
+// settings keep the SDK defaults
+func newRetryer(cfg *ClientConfig) aws.Retryer {
+	standard := func(o *retry.StandardOptions) {
+		if cfg.MaxAttempts > 0 {
+			o.MaxAttempts = cfg.MaxAttempts
+		}
+		if cfg.MaxBackoff > 0 {
+			o.MaxBackoff = cfg.MaxBackoff
+		}
+	}
+	if cfg.RetryMode == "adaptive" {
+		return retry.NewAdaptiveMode(func(o *retry.AdaptiveModeOptions) {
+			o.StandardOptions = append(o.StandardOptions, standard)
+		})
+	}
+	return retry.NewStandard(standard)
+}
+
+// attemptsKey is the context key of an invocation's attempt counter
+type attemptsKey struct{}
+
+// addAttemptCounter counts each attempt the retryer makes in the counter
+// that invoke placed in the context
+func addAttemptCounter(stack *middleware.Stack) error {
+	return stack.Finalize.Insert(middleware.FinalizeMiddlewareFunc("CountAttempts",
+		func(ctx context.Context, in middleware.FinalizeInput, next middleware.FinalizeHandler) (middleware.FinalizeOutput, middleware.Metadata, error) {
+			if attempts, ok := ctx.Value(attemptsKey{}).(*atomic.Int32); ok {
+				attempts.Add(1)
+			}
+			return next.HandleFinalize(ctx, in)
+		}), "Retry", middleware.After)
+}
+
+// invoke runs one invocation under the request timeout and records how many
+// attempts the SDK made for it
+func (c *Client) invoke(ctx context.Context, call func(context.Context) *InvokeResult) *InvokeResult {
+	if c.requestTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.requestTimeout)
+		defer cancel()
+	}
+	attempts := new(atomic.Int32)
+	result := call(context.WithValue(ctx, attemptsKey{}, attempts))
+	result.Attempts = int(attempts.Load())
+	return result
+}
+
+// loadAWSConfig returns the AWS configuration for cfg. Static keys take
+// precedence; anonymous mode and keyless loopback endpoints (a local stand-in
+// server) use unsigned or dummy credentials so no credential chain is consulted.
+// Otherwise the default credential chain (env, shared credentials, IAM role,
+// etc.) is used.
+func loadAWSConfig(cfg *ClientConfig) aws.Config {
+	switch {
+	case cfg.AccessKey != "" && cfg.SecretKey != "":
+		return aws.Config{
+			Region:      cfg.Region,
+			Credentials: credentials.NewStaticCredentialsProvider(cfg.AccessKey, cfg.SecretKey, ""),
+		}
+	case cfg.Anonymous:
+		return aws.Config{Region: cfg.Region, Credentials: aws.AnonymousCredentials{}}
+	case IsLocalEndpoint(cfg.EndpointURL):
+		return aws.Config{
+			Region:      cfg.Region,
+			Credentials: credentials.NewStaticCredentialsProvider("local", "local", ""),
+		}
+	}
+
+	awsCfg, err := config.LoadDefaultConfig(context.Background(), config.WithRegion(cfg.Region))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load default AWS config: %v\n", err)
+		// Fallback to empty config
+		awsCfg = aws.Config{Region: cfg.Region}
+	}
+	return awsCfg
+}
+
+// anonymousAuthResolver selects no authentication for every operation.
+// Anonymous credentials alone are not enough: the SDK then skips SigV4 and
+// falls through to bearer token auth without a token provider.
+type anonymousAuthResolver struct{}
+
+func (anonymousAuthResolver) ResolveAuthSchemes(context.Context, *bedrockruntime.AuthResolverParameters) ([]*smithyauth.Option, error) {
+	return []*smithyauth.Option{{SchemeID: smithyauth.SchemeIDAnonymous}}, nil
+}
+
+// IsLocalEndpoint reports whether endpointURL points at this machine
+func IsLocalEndpoint(endpointURL string) bool {
+	if endpointURL == "" {
+		return false
+	}
+	u, err := url.Parse(endpointURL)
+	if err != nil {
+		return false
+	}
+	host := u.Hostname()
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
 // InvokeNonStreaming invokes the model without streaming
 func (c *Client) InvokeNonStreaming(ctx context.Context, prompt string) *InvokeResult {
-	result := &InvokeResult{
-		StartTime: time.Now(),
+	return c.invoke(ctx, func(ctx context.Context) *InvokeResult {
+		return c.invokeNonStreaming(ctx, prompt, nil)
+	})
+}
+
+// InvokeStreaming invokes the model with streaming
+func (c *Client) InvokeStreaming(ctx context.Context, prompt string) *InvokeResult {
+	return c.invoke(ctx, func(ctx context.Context) *InvokeResult {
+		return c.invokeStreaming(ctx, prompt, nil)
+	})
+}
+
+// invokeNonStreaming invokes the model without streaming. toolCalls, when
+// set, are the previous turn's tool calls to answer with the canned result.
+func (c *Client) invokeNonStreaming(ctx context.Context, prompt string, toolCalls []ToolCall) *InvokeResult {
+	if c.api == APIConverse {
+		return c.converseNonStreaming(ctx, prompt, toolCalls)
 	}
 
-	// Prepare request body based on model type
-	var requestBody []byte
-	var err error
-
-	if c.isClaudeModel() {
-		requestBody, err = c.prepareClaudeRequest(prompt)
-	} else if c.isDeepSeekModel() {
-		requestBody, err = c.prepareDeepSeekRequest(prompt)
-	} else if c.isMistralModel() {
-		requestBody, err = c.prepareMistralRequest(prompt)
-	} else if c.isQwenModel() {
-		requestBody, err = c.prepareQwenRequest(prompt)
-	} else if c.isLlamaModel() {
-		requestBody, err = c.prepareLlamaRequest(prompt)
-	} else {
-		result.Error = fmt.Errorf("unsupported model: %s", c.modelID)
-		result.ErrorType = "UnsupportedModel"
-		result.EndTime = time.Now()
-		return result
+	result := &InvokeResult{
+		StartTime: time.Now(),
 	}
 
-	if err != nil {
-		result.Error = fmt.Errorf("failed to prepare request: %w", err)
-		result.ErrorType = "RequestPreparationError"
-		result.EndTime = time.Now()
+	// Prepare request body using the model family's adapter
+	requestBody, ok := c.buildRequest(prompt, toolCalls, result)
+	if !ok {
 		return result
 	}
 
 	// Invoke the model
 	input := &bedrockruntime.InvokeModelInput{
-		ModelId:     aws.String(c.modelID),
-		ContentType: aws.String("application/json"),
-		Accept:      aws.String("application/json"),
-		Body:        requestBody,
+		ModelId:                  aws.String(c.modelID),
+		ContentType:              aws.String("application/json"),
+		Accept:                   aws.String("application/json"),
+		Body:                     requestBody,
+		ServiceTier:              c.serviceTierType(),
+		PerformanceConfigLatency: c.performanceLatency(),
 	}
 
 	output, err := c.client.InvokeModel(ctx, input)
 	result.EndTime = time.Now()
 
 	if err != nil {
-		result.Error = err
-		result.ErrorType = c.categorizeError(err)
-		result.HTTPStatusCode = c.extractHTTPStatusFromError(err)
+		setError(result, err)
 		return result
 	}
 
-	// Set HTTP status code (200 for successful invocation)
-	result.HTTPStatusCode = 200
-
-	// Parse response based on model type
-	if c.isClaudeModel() {
-		c.parseClaudeResponse(output.Body, result)
-	} else if c.isDeepSeekModel() {
-		c.parseDeepSeekResponse(output.Body, result)
-	} else if c.isMistralModel() {
-		c.parseMistralResponse(output.Body, result)
-	} else if c.isQwenModel() {
-		c.parseQwenResponse(output.Body, result)
-	} else if c.isLlamaModel() {
-		c.parseLlamaResponse(output.Body, result)
+	setResponseMetadata(result, output.ResultMetadata)
+
+	// Parse response using the model family's adapter
+	if err := c.adapter.ParseResponse(output.Body, result); err != nil {
+		result.Error = fmt.Errorf("failed to parse response: %w", err)
+		result.ErrorType = "ResponseParseError"
 	}
+	applyInvocationMetrics(output.Body, result)
+	applyResponseHeaders(result, output.ResultMetadata)
+	result.PerformanceLatency = string(output.PerformanceConfigLatency)
+	result.estimateThinkingTokens()
 
 	result.Success = result.Error == nil
 	return result
 }
 
-// InvokeStreaming invokes the model with streaming
-func (c *Client) InvokeStreaming(ctx context.Context, prompt string) *InvokeResult {
+// invokeStreaming invokes the model with streaming. toolCalls, when set,
+// are the previous turn's tool calls to answer with the canned result.
+func (c *Client) invokeStreaming(ctx context.Context, prompt string, toolCalls []ToolCall) *InvokeResult {
+	if c.api == APIConverse {
+		return c.converseStreaming(ctx, prompt, toolCalls)
+	}
+
 	result := &InvokeResult{
 		StartTime: time.Now(),
 	}
 
-	// Prepare request body (Claude, DeepSeek, Mistral, and Qwen support streaming)
-	if !c.isClaudeModel() && !c.isDeepSeekModel() && !c.isMistralModel() && !c.isQwenModel() {
-		result.Error = fmt.Errorf("streaming only supported for Claude, DeepSeek, Mistral, and Qwen models")
+	if c.adapter != nil && !c.adapter.Capabilities().Streaming {
+		result.Error = fmt.Errorf("streaming is not supported for %s models", c.adapter.Name())
 		result.ErrorType = "UnsupportedOperation"
 		result.EndTime = time.Now()
 		return result
 	}
 
-	var requestBody []byte
-	var err error
-	if c.isClaudeModel() {
-		requestBody, err = c.prepareClaudeRequest(prompt)
-	} else if c.isMistralModel() {
-		requestBody, err = c.prepareMistralRequest(prompt)
-	} else if c.isQwenModel() {
-		requestBody, err = c.prepareQwenRequest(prompt)
-	} else {
-		requestBody, err = c.prepareDeepSeekRequest(prompt)
-	}
-	if err != nil {
-		result.Error = fmt.Errorf("failed to prepare request: %w", err)
-		result.ErrorType = "RequestPreparationError"
-		result.EndTime = time.Now()
+	requestBody, ok := c.buildRequest(prompt, toolCalls, result)
+	if !ok {
 		return result
 	}
 
 	// Invoke the model with streaming
 	input := &bedrockruntime.InvokeModelWithResponseStreamInput{
-		ModelId:     aws.String(c.modelID),
-		ContentType: aws.String("application/json"),
-		Accept:      aws.String("application/json"),
-		Body:        requestBody,
-	}
-
-	// Set service tier if specified
-	if c.serviceTier != "" && c.serviceTier != ServiceTierDefault {
-		input.ServiceTier = types.ServiceTierType(c.serviceTier)
+		ModelId:                  aws.String(c.modelID),
+		ContentType:              aws.String("application/json"),
+		Accept:                   aws.String("application/json"),
+		Body:                     requestBody,
+		ServiceTier:              c.serviceTierType(),
+		PerformanceConfigLatency: c.performanceLatency(),
 	}
 
 	output, err := c.client.InvokeModelWithResponseStream(ctx, input)
 	if err != nil {
-		result.Error = err
-		result.ErrorType = c.categorizeError(err)
-		result.HTTPStatusCode = c.extractHTTPStatusFromError(err)
+		setError(result, err)
+		result.FailurePhase = FailurePhaseRequest
+		result.FailedAfter = time.Since(result.StartTime)
 		result.EndTime = time.Now()
 		return result
 	}
 
-	// Set HTTP status code (200 for successful streaming start)
-	result.HTTPStatusCode = 200
-
-	// Process the stream based on model type
-	if c.isClaudeModel() {
-		c.processClaudeStream(output.GetStream(), result)
-	} else if c.isMistralModel() {
-		c.processMistralStream(output.GetStream(), result)
-	} else if c.isQwenModel() {
-		c.processQwenStream(output.GetStream(), result)
-	} else if c.isDeepSeekModel() {
-		c.processDeepSeekStream(output.GetStream(), result)
-	}
+	setResponseMetadata(result, output.ResultMetadata)
+	result.PerformanceLatency = string(output.PerformanceConfigLatency)
+
+	c.processStream(output.GetStream(), result)
 
 	result.EndTime = time.Now()
 	result.Success = result.Error == nil
 	return result
 }
 
-// prepareClaudeRequest prepares a request for Claude models
-func (c *Client) prepareClaudeRequest(prompt string) ([]byte, error) {
-	req := ClaudeRequest{
-		AnthropicVersion: "bedrock-2023-05-31",
-		MaxTokens:        c.maxTokens,
-		Messages: []ClaudeMessage{
-			{
-				Role:    "user",
-				Content: prompt,
-			},
-		},
-		Temperature: c.temperature,
+// serviceTierType returns the tier to request, or "" to let Bedrock use the default tier
+func (c *Client) serviceTierType() types.ServiceTierType {
+	if c.serviceTier == "" || c.serviceTier == ServiceTierDefault {
+		return ""
 	}
-	return json.Marshal(req)
+	return types.ServiceTierType(c.serviceTier)
 }
 
-// prepareDeepSeekRequest prepares a request for DeepSeek models (without anthropic_version)
-func (c *Client) prepareDeepSeekRequest(prompt string) ([]byte, error) {
-	// DeepSeek uses Messages API like Claude but without the anthropic_version field
-	req := map[string]interface{}{
-		"messages": []map[string]string{
-			{
-				"role":    "user",
-				"content": prompt,
-			},
-		},
-		"max_tokens":  c.maxTokens,
-		"temperature": c.temperature,
+// performanceLatency returns the latency mode to request, or "" for standard latency
+func (c *Client) performanceLatency() types.PerformanceConfigLatency {
+	if c.latency == "" || c.latency == PerformanceLatencyStandard {
+		return ""
 	}
-	return json.Marshal(req)
+	return types.PerformanceConfigLatency(c.latency)
 }
 
-// prepareLlamaRequest prepares a request for Llama models
-func (c *Client) prepareLlamaRequest(prompt string) ([]byte, error) {
-	req := LlamaRequest{
-		Prompt:      prompt,
-		MaxGenLen:   c.maxTokens,
-		Temperature: c.temperature,
-	}
-	return json.Marshal(req)
-}
-
-// parseClaudeResponse parses a Claude response
-func (c *Client) parseClaudeResponse(body []byte, result *InvokeResult) {
-	var resp ClaudeResponse
-	if err := json.Unmarshal(body, &resp); err != nil {
-		result.Error = fmt.Errorf("failed to parse response: %w", err)
-		result.ErrorType = "ResponseParseError"
-		return
+// buildRequest builds the InvokeModel body for prompt, followed by the tool
+// turn when toolCalls is set. On failure it records the error on result and
+// returns false.
+func (c *Client) buildRequest(prompt string, toolCalls []ToolCall, result *InvokeResult) ([]byte, bool) {
+	if c.adapterErr != nil {
+		result.Error = c.adapterErr
+		result.ErrorType = "UnsupportedModel"
+		result.EndTime = time.Now()
+		return nil, false
 	}
-
-	result.InputTokens = resp.Usage.InputTokens
-	result.OutputTokens = resp.Usage.OutputTokens
-
-	if len(resp.Content) > 0 {
-		result.ResponseContent = resp.Content[0].Text
+	if len(c.tools) > 0 && !c.adapter.Capabilities().ToolUse {
+		result.Error = fmt.Errorf("tool use is not supported for %s models over InvokeModel; use test.api \"converse\"", c.adapter.Name())
+		result.ErrorType = "UnsupportedOperation"
+		result.EndTime = time.Now()
+		return nil, false
+	}
+
+	requestBody, err := c.adapter.BuildRequest(&InferenceParams{
+		Prompt:          prompt,
+		MaxTokens:       c.maxTokens,
+		Temperature:     c.temperature,
+		SystemPrompt:    c.systemPrompt,
+		TopP:            c.topP,
+		TopK:            c.topK,
+		StopSequences:   c.stopSequences,
+		Prefill:         c.prefill,
+		ThinkingBudget:  c.thinking,
+		ReasoningEffort: c.effort,
+		SharedPrefix:    c.sharedPrefix,
+		CachePrefix:     c.promptCaching,
+		Tools:           c.tools,
+		ToolChoice:      c.toolChoice,
+		ToolCalls:       toolCalls,
+		ToolResult:      c.toolResult,
+		Images:          c.images,
+		Documents:       c.documents,
+	})
+	if err != nil {
+		result.Error = fmt.Errorf("failed to prepare request: %w", err)
+		result.ErrorType = "RequestPreparationError"
+		result.EndTime = time.Now()
+		return nil, false
 	}
+	return requestBody, true
 }
 
-// parseDeepSeekResponse parses a DeepSeek response (OpenAI format)
-func (c *Client) parseDeepSeekResponse(body []byte, result *InvokeResult) {
-	var resp DeepSeekResponse
-	if err := json.Unmarshal(body, &resp); err != nil {
-		result.Error = fmt.Errorf("failed to parse response: %w", err)
-		result.ErrorType = "ResponseParseError"
-		return
-	}
+// processStream processes an InvokeModelWithResponseStream response, handing
+// each chunk to the model family's adapter
+func (c *Client) processStream(stream *bedrockruntime.InvokeModelWithResponseStreamEventStream, result *InvokeResult) {
+	defer stream.Close()
 
-	result.InputTokens = resp.Usage.PromptTokens
-	result.OutputTokens = resp.Usage.CompletionTokens
-
-	if len(resp.Choices) > 0 {
-		result.ResponseContent = resp.Choices[0].Message.Content
-	}
-}
-
-// parseLlamaResponse parses a Llama response
-func (c *Client) parseLlamaResponse(body []byte, result *InvokeResult) {
-	var resp LlamaResponse
-	if err := json.Unmarshal(body, &resp); err != nil {
-		result.Error = fmt.Errorf("failed to parse response: %w", err)
-		result.ErrorType = "ResponseParseError"
-		return
-	}
-
-	result.InputTokens = resp.PromptTokenCount
-	result.OutputTokens = resp.GenerationTokenCount
-	result.ResponseContent = resp.Generation
-}
-
-// processClaudeStream processes a Claude streaming response
-func (c *Client) processClaudeStream(stream *bedrockruntime.InvokeModelWithResponseStreamEventStream, result *InvokeResult) {
-	firstToken := true
-	var contentBuilder strings.Builder
+	var lastChunk time.Time
+	contentChunks := 0
+	var contentBuilder, thinkingBuilder strings.Builder
+	var tools toolTracker
 
 	for event := range stream.Events() {
 		switch e := event.(type) {
 		case *types.ResponseStreamMemberChunk:
-			var streamEvent ClaudeStreamEvent
-			if err := json.Unmarshal(e.Value.Bytes, &streamEvent); err != nil {
+			delta, err := c.adapter.ParseStreamChunk(e.Value.Bytes, result)
+			if err != nil {
 				result.Error = fmt.Errorf("failed to parse stream event: %w", err)
 				result.ErrorType = "StreamParseError"
 				return
 			}
+			applyInvocationMetrics(e.Value.Bytes, result)
 
-			// Record TTFT on first content
-			if firstToken && streamEvent.Type == "content_block_delta" {
-				result.TTFT = time.Since(result.StartTime)
-				firstToken = false
+			// Record TTFT on first content and inter-chunk gaps afterwards
+			if delta.Thinking != "" {
+				result.recordChunk(&lastChunk, true)
+				contentChunks++
+				thinkingBuilder.WriteString(delta.Thinking)
 			}
-
-			// Accumulate content
-			if streamEvent.Delta != nil && streamEvent.Delta.Text != "" {
-				contentBuilder.WriteString(streamEvent.Delta.Text)
+			if delta.Text != "" {
+				result.recordChunk(&lastChunk, false)
+				contentChunks++
+				contentBuilder.WriteString(delta.Text)
 			}
-
-			// Capture usage information
-			if streamEvent.Type == "message_delta" && streamEvent.Usage != nil {
-				result.OutputTokens = streamEvent.Usage.OutputTokens
+			if delta.ToolUse != nil {
+				tools.start(*delta.ToolUse, result)
 			}
-
-			if streamEvent.Type == "message_start" && streamEvent.Message != nil {
-				result.InputTokens = streamEvent.Message.Usage.InputTokens
+			if delta.ToolInput != "" {
+				result.recordChunk(&lastChunk, false)
+				contentChunks++
+				tools.appendInput(delta.ToolInput)
 			}
-
-		default:
-			// Handle any error types that are not chunk events
-			// AWS SDK error types are handled here
-		}
-	}
-
-	// Check for stream errors
-	if err := stream.Err(); err != nil {
-		if err != io.EOF {
-			// Log full error for debugging StreamError cases
-			fmt.Fprintf(os.Stderr, "[DEBUG] StreamError details: %v\n", err)
-			result.Error = fmt.Errorf("stream error: %w", err)
-			result.ErrorType = "StreamError"
-			return
-		}
-	}
-
-	result.ResponseContent = contentBuilder.String()
-}
-
-// processDeepSeekStream processes a DeepSeek streaming response (OpenAI format with AWS Bedrock metrics)
-func (c *Client) processDeepSeekStream(stream *bedrockruntime.InvokeModelWithResponseStreamEventStream, result *InvokeResult) {
-	firstToken := true
-	var contentBuilder strings.Builder
-
-	for event := range stream.Events() {
-		switch e := event.(type) {
-		case *types.ResponseStreamMemberChunk:
-			// Parse as generic JSON to access all fields
-			var genericEvent map[string]interface{}
-			if err := json.Unmarshal(e.Value.Bytes, &genericEvent); err == nil {
-				// Check for AWS Bedrock invocation metrics (in final chunk)
-				if metrics, ok := genericEvent["amazon-bedrock-invocationMetrics"].(map[string]interface{}); ok {
-					if inputTokens, ok := metrics["inputTokenCount"].(float64); ok {
-						result.InputTokens = int(inputTokens)
-					}
-					if outputTokens, ok := metrics["outputTokenCount"].(float64); ok {
-						result.OutputTokens = int(outputTokens)
-					}
-				}
-
-				// Check for content in choices
-				if choices, ok := genericEvent["choices"].([]interface{}); ok && len(choices) > 0 {
-					if choice, ok := choices[0].(map[string]interface{}); ok {
-						if delta, ok := choice["delta"].(map[string]interface{}); ok {
-							if content, ok := delta["content"].(string); ok && content != "" {
-								if firstToken {
-									result.TTFT = time.Since(result.StartTime)
-									firstToken = false
-								}
-								contentBuilder.WriteString(content)
-							}
-						}
-					}
-				}
+			if delta.BlockStop {
+				tools.finish(result)
 			}
 
-		default:
-			// Handle any error types that are not chunk events
-		}
-	}
-
-	// Check for stream errors
-	if err := stream.Err(); err != nil {
-		if err != io.EOF {
-			// Log full error for debugging StreamError cases
-			fmt.Fprintf(os.Stderr, "[DEBUG] StreamError (DeepSeek) details: %v\n", err)
-			result.Error = fmt.Errorf("stream error: %w", err)
-			result.ErrorType = "StreamError"
-			return
+		case *types.UnknownUnionMember:
+			// An event type newer than this SDK version; exceptions never arrive
+			// here because the SDK turns them into stream errors, so it is skipped
 		}
 	}
 
+	// Exceptions delivered inside the stream end it with a typed error
 	result.ResponseContent = contentBuilder.String()
-}
-
-// isClaudeModel checks if the model is a Claude model
-func (c *Client) isClaudeModel() bool {
-	return strings.Contains(strings.ToLower(c.modelID), "claude") ||
-		strings.Contains(strings.ToLower(c.modelID), "anthropic")
-}
-
-// isDeepSeekModel checks if the model is a DeepSeek model
-func (c *Client) isDeepSeekModel() bool {
-	return strings.Contains(strings.ToLower(c.modelID), "deepseek")
-}
-
-// isLlamaModel checks if the model is a Llama model
-func (c *Client) isLlamaModel() bool {
-	return strings.Contains(strings.ToLower(c.modelID), "llama") ||
-		strings.Contains(strings.ToLower(c.modelID), "meta")
-}
-
-// isMistralModel checks if the model is a Mistral model
-func (c *Client) isMistralModel() bool {
-	return strings.Contains(strings.ToLower(c.modelID), "mistral") ||
-		strings.Contains(strings.ToLower(c.modelID), "mixtral")
-}
-
-// isQwenModel checks if the model is a Qwen model
-func (c *Client) isQwenModel() bool {
-	return strings.Contains(strings.ToLower(c.modelID), "qwen")
-}
-
-// prepareQwenRequest prepares a request for Qwen models (OpenAI-compatible format)
-func (c *Client) prepareQwenRequest(prompt string) ([]byte, error) {
-	req := map[string]interface{}{
-		"messages": []map[string]string{
-			{
-				"role":    "user",
-				"content": prompt,
-			},
-		},
-		"max_tokens":  c.maxTokens,
-		"temperature": c.temperature,
-	}
-	return json.Marshal(req)
-}
-
-// parseQwenResponse parses a Qwen response (OpenAI format)
-func (c *Client) parseQwenResponse(body []byte, result *InvokeResult) {
-	var resp map[string]interface{}
-	if err := json.Unmarshal(body, &resp); err != nil {
-		result.Error = fmt.Errorf("failed to parse response: %w", err)
-		result.ErrorType = "ResponseParseError"
+	result.ThinkingContent = thinkingBuilder.String()
+	if err := stream.Err(); err != nil && err != io.EOF {
+		setStreamError(result, err, contentChunks)
 		return
 	}
 
-	// Check for choices array (OpenAI format)
-	if choices, ok := resp["choices"].([]interface{}); ok && len(choices) > 0 {
-		if choice, ok := choices[0].(map[string]interface{}); ok {
-			if message, ok := choice["message"].(map[string]interface{}); ok {
-				if content, ok := message["content"].(string); ok {
-					result.ResponseContent = content
-				}
-			}
-		}
-	}
-
-	// Token usage from usage field or Bedrock metrics
-	if usage, ok := resp["usage"].(map[string]interface{}); ok {
-		if promptTokens, ok := usage["prompt_tokens"].(float64); ok {
-			result.InputTokens = int(promptTokens)
-		}
-		if completionTokens, ok := usage["completion_tokens"].(float64); ok {
-			result.OutputTokens = int(completionTokens)
-		}
-	}
-
-	// Also check for Bedrock metrics
-	if metrics, ok := resp["amazon-bedrock-invocationMetrics"].(map[string]interface{}); ok {
-		if inputTokens, ok := metrics["inputTokenCount"].(float64); ok {
-			result.InputTokens = int(inputTokens)
-		}
-		if outputTokens, ok := metrics["outputTokenCount"].(float64); ok {
-			result.OutputTokens = int(outputTokens)
-		}
-	}
-}
-
-// processQwenStream processes a Qwen streaming response (OpenAI format)
-func (c *Client) processQwenStream(stream *bedrockruntime.InvokeModelWithResponseStreamEventStream, result *InvokeResult) {
-	firstToken := true
-	var contentBuilder strings.Builder
-
-	for event := range stream.Events() {
-		switch e := event.(type) {
-		case *types.ResponseStreamMemberChunk:
-			var genericEvent map[string]interface{}
-			if err := json.Unmarshal(e.Value.Bytes, &genericEvent); err == nil {
-				// Check for AWS Bedrock invocation metrics (in final chunk)
-				if metrics, ok := genericEvent["amazon-bedrock-invocationMetrics"].(map[string]interface{}); ok {
-					if inputTokens, ok := metrics["inputTokenCount"].(float64); ok {
-						result.InputTokens = int(inputTokens)
-					}
-					if outputTokens, ok := metrics["outputTokenCount"].(float64); ok {
-						result.OutputTokens = int(outputTokens)
-					}
-				}
-
-				// Check for content in choices (OpenAI streaming format)
-				if choices, ok := genericEvent["choices"].([]interface{}); ok && len(choices) > 0 {
-					if choice, ok := choices[0].(map[string]interface{}); ok {
-						if delta, ok := choice["delta"].(map[string]interface{}); ok {
-							if content, ok := delta["content"].(string); ok && content != "" {
-								if firstToken {
-									result.TTFT = time.Since(result.StartTime)
-									firstToken = false
-								}
-								contentBuilder.WriteString(content)
-							}
-						}
-					}
-				}
-			}
-
-		default:
-			// Handle any error types that are not chunk events
-		}
-	}
-
-	// Check for stream errors
-	if err := stream.Err(); err != nil {
-		if err != io.EOF {
-			fmt.Fprintf(os.Stderr, "[DEBUG] StreamError (Qwen) details: %v\n", err)
-			result.Error = fmt.Errorf("stream error: %w", err)
-			result.ErrorType = "StreamError"
-			return
-		}
-	}
-
-	result.ResponseContent = contentBuilder.String()
-}
-
-// prepareMistralRequest prepares a request for Mistral models
-func (c *Client) prepareMistralRequest(prompt string) ([]byte, error) {
-	req := map[string]interface{}{
-		"prompt":      "<s>[INST] " + prompt + " [/INST]",
-		"max_tokens":  c.maxTokens,
-		"temperature": c.temperature,
-	}
-	return json.Marshal(req)
-}
-
-// parseMistralResponse parses a Mistral response
-func (c *Client) parseMistralResponse(body []byte, result *InvokeResult) {
-	var resp map[string]interface{}
-	if err := json.Unmarshal(body, &resp); err != nil {
-		result.Error = fmt.Errorf("failed to parse response: %w", err)
-		result.ErrorType = "ResponseParseError"
-		return
-	}
-
-	// Mistral response format: {"outputs": [{"text": "...", "stop_reason": "..."}]}
-	if outputs, ok := resp["outputs"].([]interface{}); ok && len(outputs) > 0 {
-		if output, ok := outputs[0].(map[string]interface{}); ok {
-			if text, ok := output["text"].(string); ok {
-				result.ResponseContent = text
-			}
-		}
-	}
-
-	// Token usage from Bedrock metrics
-	if metrics, ok := resp["amazon-bedrock-invocationMetrics"].(map[string]interface{}); ok {
-		if inputTokens, ok := metrics["inputTokenCount"].(float64); ok {
-			result.InputTokens = int(inputTokens)
-		}
-		if outputTokens, ok := metrics["outputTokenCount"].(float64); ok {
-			result.OutputTokens = int(outputTokens)
-		}
-	}
-}
-
-// processMistralStream processes a Mistral streaming response
-func (c *Client) processMistralStream(stream *bedrockruntime.InvokeModelWithResponseStreamEventStream, result *InvokeResult) {
-	firstToken := true
-	var contentBuilder strings.Builder
-
-	for event := range stream.Events() {
-		switch e := event.(type) {
-		case *types.ResponseStreamMemberChunk:
-			var genericEvent map[string]interface{}
-			if err := json.Unmarshal(e.Value.Bytes, &genericEvent); err == nil {
-				// Check for AWS Bedrock invocation metrics (in final chunk)
-				if metrics, ok := genericEvent["amazon-bedrock-invocationMetrics"].(map[string]interface{}); ok {
-					if inputTokens, ok := metrics["inputTokenCount"].(float64); ok {
-						result.InputTokens = int(inputTokens)
-					}
-					if outputTokens, ok := metrics["outputTokenCount"].(float64); ok {
-						result.OutputTokens = int(outputTokens)
-					}
-				}
-
-				// Check for outputs array (Mistral format)
-				if outputs, ok := genericEvent["outputs"].([]interface{}); ok && len(outputs) > 0 {
-					if output, ok := outputs[0].(map[string]interface{}); ok {
-						if text, ok := output["text"].(string); ok && text != "" {
-							if firstToken {
-								result.TTFT = time.Since(result.StartTime)
-								firstToken = false
-							}
-							contentBuilder.WriteString(text)
-						}
-					}
-				}
-			}
-
-		default:
-			// Handle any error types that are not chunk events
-		}
-	}
-
-	// Check for stream errors
-	if err := stream.Err(); err != nil {
-		if err != io.EOF {
-			fmt.Fprintf(os.Stderr, "[DEBUG] StreamError (Mistral) details: %v\n", err)
-			result.Error = fmt.Errorf("stream error: %w", err)
-			result.ErrorType = "StreamError"
-			return
-		}
-	}
-
-	result.ResponseContent = contentBuilder.String()
-}
-
-// categorizeError categorizes AWS errors
-func (c *Client) categorizeError(err error) string {
-	errStr := strings.ToLower(err.Error())
-	switch {
-	case strings.Contains(errStr, "throttling") || strings.Contains(errStr, "too many"):
-		return "ThrottlingError"
-	case strings.Contains(errStr, "validation"):
-		return "ValidationError"
-	case strings.Contains(errStr, "access denied"):
-		return "AccessDeniedError"
-	case strings.Contains(errStr, "not found"):
-		return "ModelNotFoundError"
-	case strings.Contains(errStr, "service quota"):
-		return "QuotaExceededError"
-	case strings.Contains(errStr, "timeout"):
-		return "TimeoutError"
-	default:
-		// Log full error for debugging UnknownError cases
-		fmt.Fprintf(os.Stderr, "[DEBUG] UnknownError details: %v\n", err)
-		return "UnknownError"
-	}
-}
-
-// extractHTTPStatusFromError attempts to extract HTTP status code from AWS error
-func (c *Client) extractHTTPStatusFromError(err error) int {
-	if err == nil {
-		return 200
-	}
-
-	errStr := err.Error()
-	// Common AWS error status codes based on error type
-	switch {
-	case strings.Contains(errStr, "throttling"):
-		return 429
-	case strings.Contains(errStr, "validation"):
-		return 400
-	case strings.Contains(errStr, "access denied"):
-		return 403
-	case strings.Contains(errStr, "not found"):
-		return 404
-	case strings.Contains(errStr, "service quota"):
-		return 429
-	case strings.Contains(errStr, "timeout"):
-		return 504
-	case strings.Contains(errStr, "internal"):
-		return 500
-	default:
-		return 0 // Unknown error, no HTTP status available
-	}
+	tools.finish(result)
+	result.estimateThinkingTokens()
 }
@@ -0,0 +1,45 @@
+package bedrock_test
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"bedrock-performance/internal/bedrock"
+	"bedrock-performance/internal/mock"
+)
+
+func TestStreamExceptionKeepsPartialOutput(t *testing.T) {
+	// The mock fails every stream with a modelStreamErrorException after
+	// half of the 40 output tokens, sent four tokens per chunk
+	cfg := mock.DefaultConfig()
+	cfg.TTFT = mock.LatencyConfig{P50: 5, P99: 5}
+	cfg.TokensPerSecond = 2000
+	cfg.OutputTokens = mock.RangeConfig{Min: 40, Max: 40}
+	cfg.ChunkTokens = 4
+	cfg.MidStreamErrorRate = 1
+	cfg.Seed = 1
+	srv := httptest.NewServer(mock.NewServer(cfg))
+	defer srv.Close()
+
+	client := bedrock.NewClientFromConfig(&bedrock.ClientConfig{
+		Region: "us-east-1", ModelID: "anthropic.claude-3-haiku-20240307-v1:0", MaxTokens: 100,
+		EndpointURL: srv.URL, MaxAttempts: 1,
+	})
+	result := client.InvokeStreaming(context.Background(), "hello")
+
+	if result.Success || result.ErrorType != "ModelStreamError" {
+		t.Fatalf("result = %v (%s), want a ModelStreamError", result.Error, result.ErrorType)
+	}
+	if result.FailurePhase != bedrock.FailurePhaseMidStream || result.TTFT <= 0 || result.FailedAfter < result.TTFT {
+		t.Errorf("failure phase %q after %v with TTFT %v, want mid_stream after the first token", result.FailurePhase, result.FailedAfter, result.TTFT)
+	}
+	if result.ErrorCode != "ModelStreamErrorException" || result.RequestID == "" {
+		t.Errorf("error code %q, request ID %q", result.ErrorCode, result.RequestID)
+	}
+	// Usage only arrives at the end of the stream, so the five content
+	// chunks received are the lower bound on the tokens generated
+	if result.ResponseContent == "" || result.PartialOutputTokens != 5 {
+		t.Errorf("kept %q and %d partial output tokens, want the 5 chunks received before the exception", result.ResponseContent, result.PartialOutputTokens)
+	}
+}
@@ -0,0 +1,406 @@
+package bedrock
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime/document"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime/types"
+)
+
+// API selects which Bedrock runtime operations are used to call the model
+type API string
+
+const (
+	// APIInvoke uses InvokeModel / InvokeModelWithResponseStream with model-specific bodies
+	APIInvoke API = "invoke"
+	// APIConverse uses the unified Converse / ConverseStream operations
+	APIConverse API = "converse"
+)
+
+// converseMessages builds the message list for a Converse request, ending
+// with the assistant prefill if one is configured, or with the tool turn
+// when toolCalls is set
+func (c *Client) converseMessages(prompt string, toolCalls []ToolCall) []types.Message {
+	messages := []types.Message{
+		{
+			Role:    types.ConversationRoleUser,
+			Content: c.converseUserContent(prompt),
+		},
+	}
+	if len(toolCalls) > 0 {
+		return append(messages, c.converseToolTurn(toolCalls)...)
+	}
+	if c.prefill != "" && c.thinking == 0 {
+		messages = append(messages, types.Message{
+			Role: types.ConversationRoleAssistant,
+			Content: []types.ContentBlock{
+				&types.ContentBlockMemberText{Value: c.prefill},
+			},
+		})
+	}
+	return messages
+}
+
+// converseToolTurn returns the assistant tool use message and the user
+// message answering every tool call with the canned result
+func (c *Client) converseToolTurn(toolCalls []ToolCall) []types.Message {
+	var uses, results []types.ContentBlock
+	for _, call := range toolCalls {
+		uses = append(uses, &types.ContentBlockMemberToolUse{Value: types.ToolUseBlock{
+			ToolUseId: aws.String(call.ID),
+			Name:      aws.String(call.Name),
+			Input:     document.NewLazyDocument(call.inputObject()),
+		}})
+		results = append(results, &types.ContentBlockMemberToolResult{Value: types.ToolResultBlock{
+			ToolUseId: aws.String(call.ID),
+			Content: []types.ToolResultContentBlock{
+				&types.ToolResultContentBlockMemberText{Value: c.toolResult},
+			},
+		}})
+	}
+	return []types.Message{
+		{Role: types.ConversationRoleAssistant, Content: uses},
+		{Role: types.ConversationRoleUser, Content: results},
+	}
+}
+
+// converseToolConfig returns the tool definitions and tool choice, or nil when no tools are configured
+func (c *Client) converseToolConfig() (*types.ToolConfiguration, error) {
+	if len(c.tools) == 0 {
+		return nil, nil
+	}
+
+	config := &types.ToolConfiguration{}
+	for _, tool := range c.tools {
+		var schema map[string]interface{}
+		if err := json.Unmarshal(tool.InputSchema, &schema); err != nil {
+			return nil, fmt.Errorf("invalid input schema for tool %s: %w", tool.Name, err)
+		}
+		spec := types.ToolSpecification{
+			Name:        aws.String(tool.Name),
+			InputSchema: &types.ToolInputSchemaMemberJson{Value: document.NewLazyDocument(schema)},
+		}
+		if tool.Description != "" {
+			spec.Description = aws.String(tool.Description)
+		}
+		config.Tools = append(config.Tools, &types.ToolMemberToolSpec{Value: spec})
+	}
+
+	switch c.toolChoice {
+	case "", "auto":
+		config.ToolChoice = &types.ToolChoiceMemberAuto{Value: types.AutoToolChoice{}}
+	case "any":
+		config.ToolChoice = &types.ToolChoiceMemberAny{Value: types.AnyToolChoice{}}
+	default:
+		config.ToolChoice = &types.ToolChoiceMemberTool{Value: types.SpecificToolChoice{Name: aws.String(c.toolChoice)}}
+	}
+	return config, nil
+}
+
+// converseSystem returns the system prompt and shared prefix as system
+// content, followed by a cache point when prompt caching is enabled
+func (c *Client) converseSystem() []types.SystemContentBlock {
+	var system []types.SystemContentBlock
+	if c.systemPrompt != "" {
+		system = append(system, &types.SystemContentBlockMemberText{Value: c.systemPrompt})
+	}
+	if c.sharedPrefix == "" {
+		return system
+	}
+	system = append(system, &types.SystemContentBlockMemberText{Value: c.sharedPrefix})
+	if c.promptCaching {
+		system = append(system, &types.SystemContentBlockMemberCachePoint{
+			Value: types.CachePointBlock{Type: types.CachePointTypeDefault},
+		})
+	}
+	return system
+}
+
+// applyConverseUsage copies token usage, including cache reads and writes, onto result
+func applyConverseUsage(usage *types.TokenUsage, result *InvokeResult) {
+	if usage == nil {
+		return
+	}
+	result.InputTokens = int(aws.ToInt32(usage.InputTokens))
+	result.OutputTokens = int(aws.ToInt32(usage.OutputTokens))
+	result.CacheReadInputTokens = int(aws.ToInt32(usage.CacheReadInputTokens))
+	result.CacheWriteInputTokens = int(aws.ToInt32(usage.CacheWriteInputTokens))
+}
+
+// applyConversePerformance records the latency mode Bedrock served the request with
+func applyConversePerformance(config *types.PerformanceConfiguration, result *InvokeResult) {
+	if config != nil {
+		result.PerformanceLatency = string(config.Latency)
+	}
+}
+
+// converseInferenceConfig builds the inference configuration shared by both Converse paths
+func (c *Client) converseInferenceConfig() *types.InferenceConfiguration {
+	config := &types.InferenceConfiguration{
+		MaxTokens:     aws.Int32(int32(c.maxTokens)),
+		StopSequences: c.stopSequences,
+	}
+	// Extended thinking only runs at the default temperature
+	if c.thinking == 0 {
+		config.Temperature = aws.Float32(float32(c.temperature))
+	}
+	if c.topP > 0 {
+		config.TopP = aws.Float32(float32(c.topP))
+	}
+	return config
+}
+
+// converseTopKFields maps top_k to the additionalModelRequestFields of each
+// model family that accepts it over Converse
+var converseTopKFields = map[string]func(topK int) map[string]interface{}{
+	"claude": func(topK int) map[string]interface{} { return map[string]interface{}{"top_k": topK} },
+	"nova": func(topK int) map[string]interface{} {
+		return map[string]interface{}{"inferenceConfig": map[string]interface{}{"topK": topK}}
+	},
+}
+
+// ConverseSupportsTopK reports whether top_k can be sent over Converse to
+// models of the given family
+func ConverseSupportsTopK(family string) bool {
+	_, ok := converseTopKFields[family]
+	return ok
+}
+
+// converseAdditionalFields returns model-specific fields that Converse has no
+// common parameter for: top_k in the field the model family expects, the
+// Anthropic thinking field and the reasoning effort of models with effort
+// control. Families without them get none; the runner rejects such
+// configurations up front.
+func (c *Client) converseAdditionalFields() document.Interface {
+	if c.adapter == nil {
+		return nil
+	}
+	switch {
+	case c.effort != "" && c.adapter.Capabilities().ReasoningEffort:
+		return document.NewLazyDocument(map[string]interface{}{"reasoning_effort": c.effort})
+	case c.thinking > 0 && c.adapter.Capabilities().Thinking:
+		return document.NewLazyDocument(map[string]interface{}{
+			"thinking": map[string]interface{}{"type": "enabled", "budget_tokens": c.thinking},
+		})
+	case c.topK > 0 && c.thinking == 0:
+		if topK, ok := converseTopKFields[c.adapter.Name()]; ok {
+			return document.NewLazyDocument(topK(c.topK))
+		}
+	}
+	return nil
+}
+
+// prepareToolConfig builds the tool configuration. On failure it records the
+// error on result and returns false.
+func (c *Client) prepareToolConfig(result *InvokeResult) (*types.ToolConfiguration, bool) {
+	toolConfig, err := c.converseToolConfig()
+	if err != nil {
+		result.Error = fmt.Errorf("failed to prepare request: %w", err)
+		result.ErrorType = "RequestPreparationError"
+		result.EndTime = time.Now()
+		return nil, false
+	}
+	return toolConfig, true
+}
+
+// converseServiceTier returns the tier to request, or nil for the default tier
+func (c *Client) converseServiceTier() *types.ServiceTier {
+	if tier := c.serviceTierType(); tier != "" {
+		return &types.ServiceTier{Type: tier}
+	}
+	return nil
+}
+
+// conversePerformanceConfig returns the latency mode to request, or nil for standard latency
+func (c *Client) conversePerformanceConfig() *types.PerformanceConfiguration {
+	if latency := c.performanceLatency(); latency != "" {
+		return &types.PerformanceConfiguration{Latency: latency}
+	}
+	return nil
+}
+
+// converseNonStreaming invokes the model through the Converse API
+func (c *Client) converseNonStreaming(ctx context.Context, prompt string, toolCalls []ToolCall) *InvokeResult {
+	result := &InvokeResult{
+		StartTime: time.Now(),
+	}
+
+	toolConfig, ok := c.prepareToolConfig(result)
+	if !ok {
+		return result
+	}
+
+	input := &bedrockruntime.ConverseInput{
+		ModelId:                      aws.String(c.modelID),
+		Messages:                     c.converseMessages(prompt, toolCalls),
+		ToolConfig:                   toolConfig,
+		System:                       c.converseSystem(),
+		InferenceConfig:              c.converseInferenceConfig(),
+		AdditionalModelRequestFields: c.converseAdditionalFields(),
+		ServiceTier:                  c.converseServiceTier(),
+		PerformanceConfig:            c.conversePerformanceConfig(),
+	}
+
+	output, err := c.client.Converse(ctx, input)
+	result.EndTime = time.Now()
+
+	if err != nil {
+		setError(result, err)
+		return result
+	}
+
+	setResponseMetadata(result, output.ResultMetadata)
+
+	applyConverseUsage(output.Usage, result)
+	applyConversePerformance(output.PerformanceConfig, result)
+	result.StopReason = string(output.StopReason)
+	if output.Metrics != nil {
+		result.ServerLatency = time.Duration(aws.ToInt64(output.Metrics.LatencyMs)) * time.Millisecond
+	}
+
+	if message, ok := output.Output.(*types.ConverseOutputMemberMessage); ok {
+		var contentBuilder, thinkingBuilder strings.Builder
+		for _, block := range message.Value.Content {
+			switch b := block.(type) {
+			case *types.ContentBlockMemberText:
+				contentBuilder.WriteString(b.Value)
+			case *types.ContentBlockMemberReasoningContent:
+				if text, ok := b.Value.(*types.ReasoningContentBlockMemberReasoningText); ok {
+					thinkingBuilder.WriteString(aws.ToString(text.Value.Text))
+				}
+			case *types.ContentBlockMemberToolUse:
+				call := ToolCall{ID: aws.ToString(b.Value.ToolUseId), Name: aws.ToString(b.Value.Name)}
+				if b.Value.Input != nil {
+					if input, err := b.Value.Input.MarshalSmithyDocument(); err == nil {
+						call.Input = string(input)
+					}
+				}
+				result.addToolCall(call)
+			}
+		}
+		result.ResponseContent = contentBuilder.String()
+		result.ThinkingContent = thinkingBuilder.String()
+		result.estimateThinkingTokens()
+	}
+
+	result.Success = true
+	return result
+}
+
+// converseStreaming invokes the model through the ConverseStream API
+func (c *Client) converseStreaming(ctx context.Context, prompt string, toolCalls []ToolCall) *InvokeResult {
+	result := &InvokeResult{
+		StartTime: time.Now(),
+	}
+
+	toolConfig, ok := c.prepareToolConfig(result)
+	if !ok {
+		return result
+	}
+
+	input := &bedrockruntime.ConverseStreamInput{
+		ModelId:                      aws.String(c.modelID),
+		Messages:                     c.converseMessages(prompt, toolCalls),
+		ToolConfig:                   toolConfig,
+		System:                       c.converseSystem(),
+		InferenceConfig:              c.converseInferenceConfig(),
+		AdditionalModelRequestFields: c.converseAdditionalFields(),
+		ServiceTier:                  c.converseServiceTier(),
+		PerformanceConfig:            c.conversePerformanceConfig(),
+	}
+
+	output, err := c.client.ConverseStream(ctx, input)
+	if err != nil {
+		setError(result, err)
+		result.FailurePhase = FailurePhaseRequest
+		result.FailedAfter = time.Since(result.StartTime)
+		result.EndTime = time.Now()
+		return result
+	}
+
+	setResponseMetadata(result, output.ResultMetadata)
+
+	c.processConverseStream(output.GetStream(), result)
+
+	result.EndTime = time.Now()
+	result.Success = result.Error == nil
+	return result
+}
+
+// processConverseStream processes a ConverseStream event stream
+func (c *Client) processConverseStream(stream *bedrockruntime.ConverseStreamEventStream, result *InvokeResult) {
+	defer stream.Close()
+
+	var lastChunk time.Time
+	contentChunks := 0
+	var contentBuilder, thinkingBuilder strings.Builder
+	var tools toolTracker
+
+	for event := range stream.Events() {
+		switch e := event.(type) {
+		case *types.ConverseStreamOutputMemberContentBlockStart:
+			if start, ok := e.Value.Start.(*types.ContentBlockStartMemberToolUse); ok {
+				tools.start(ToolCall{ID: aws.ToString(start.Value.ToolUseId), Name: aws.ToString(start.Value.Name)}, result)
+			}
+
+		case *types.ConverseStreamOutputMemberContentBlockStop:
+			tools.finish(result)
+
+		case *types.ConverseStreamOutputMemberContentBlockDelta:
+			switch delta := e.Value.Delta.(type) {
+			case *types.ContentBlockDeltaMemberText:
+				if delta.Value != "" {
+					result.recordChunk(&lastChunk, false)
+					contentChunks++
+					contentBuilder.WriteString(delta.Value)
+				}
+			case *types.ContentBlockDeltaMemberReasoningContent:
+				if text, ok := delta.Value.(*types.ReasoningContentBlockDeltaMemberText); ok && text.Value != "" {
+					result.recordChunk(&lastChunk, true)
+					contentChunks++
+					thinkingBuilder.WriteString(text.Value)
+				}
+			case *types.ContentBlockDeltaMemberToolUse:
+				if input := aws.ToString(delta.Value.Input); input != "" {
+					result.recordChunk(&lastChunk, false)
+					contentChunks++
+					tools.appendInput(input)
+				}
+			}
+
+		case *types.ConverseStreamOutputMemberMetadata:
+			applyConverseUsage(e.Value.Usage, result)
+			applyConversePerformance(e.Value.PerformanceConfig, result)
+			if e.Value.Metrics != nil {
+				result.ServerLatency = time.Duration(aws.ToInt64(e.Value.Metrics.LatencyMs)) * time.Millisecond
+			}
+
+		case *types.ConverseStreamOutputMemberMessageStop:
+			result.StopReason = string(e.Value.StopReason)
+
+		case *types.UnknownUnionMember:
+			// An event type newer than this SDK version; skipped
+
+		default:
+			// Message start carries no metrics
+		}
+	}
+
+	// Exceptions delivered inside the stream end it with a typed error
+	result.ResponseContent = contentBuilder.String()
+	result.ThinkingContent = thinkingBuilder.String()
+	if err := stream.Err(); err != nil && err != io.EOF {
+		setStreamError(result, err, contentChunks)
+		return
+	}
+
+	tools.finish(result)
+	result.estimateThinkingTokens()
+}
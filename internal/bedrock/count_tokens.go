@@ -0,0 +1,57 @@
+package bedrock
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime/types"
+)
+
+// CountTokens returns the input tokens Bedrock counts for the request that
+// would be sent for prompt, without running the model. The request is built
+// exactly as for an invocation on the client's API. Only some models support
+// token counting; CountTokens takes the foundation model ID, so inference
+// profiles are counted against their base model.
+func (c *Client) CountTokens(ctx context.Context, prompt string) (int, error) {
+	var input types.CountTokensInput
+	if c.api == APIConverse {
+		toolConfig, err := c.converseToolConfig()
+		if err != nil {
+			return 0, fmt.Errorf("failed to prepare request: %w", err)
+		}
+		input = &types.CountTokensInputMemberConverse{Value: types.ConverseTokensRequest{
+			Messages:                     c.converseMessages(prompt, nil),
+			System:                       c.converseSystem(),
+			ToolConfig:                   toolConfig,
+			AdditionalModelRequestFields: c.converseAdditionalFields(),
+		}}
+	} else {
+		result := &InvokeResult{}
+		body, ok := c.buildRequest(prompt, nil, result)
+		if !ok {
+			return 0, result.Error
+		}
+		input = &types.CountTokensInputMemberInvokeModel{Value: types.InvokeModelTokensRequest{Body: body}}
+	}
+
+	modelID := c.modelID
+	if base := ParseModelID(c.modelID).BaseModelID; base != "" {
+		modelID = base
+	}
+
+	if c.requestTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.requestTimeout)
+		defer cancel()
+	}
+	output, err := c.client.CountTokens(ctx, &bedrockruntime.CountTokensInput{
+		ModelId: aws.String(modelID),
+		Input:   input,
+	})
+	if err != nil {
+		return 0, err
+	}
+	return int(aws.ToInt32(output.InputTokens)), nil
+}
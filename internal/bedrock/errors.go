@@ -0,0 +1,185 @@
+package bedrock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime/types"
+	"github.com/aws/smithy-go"
+	"github.com/aws/smithy-go/middleware"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+)
+
+// errorClass maps a Bedrock runtime exception to the error type used in
+// reports and the HTTP status Bedrock documents for it
+type errorClass struct {
+	errorType  string
+	statusCode int
+}
+
+// classifyException matches err against the typed bedrockruntime exceptions
+func classifyException(err error) (errorClass, bool) {
+	var (
+		throttling         *types.ThrottlingException
+		serviceUnavailable *types.ServiceUnavailableException
+		modelTimeout       *types.ModelTimeoutException
+		modelNotReady      *types.ModelNotReadyException
+		modelError         *types.ModelErrorException
+		modelStreamError   *types.ModelStreamErrorException
+		validation         *types.ValidationException
+		accessDenied       *types.AccessDeniedException
+		notFound           *types.ResourceNotFoundException
+		quotaExceeded      *types.ServiceQuotaExceededException
+		internalServer     *types.InternalServerException
+		conflict           *types.ConflictException
+	)
+
+	switch {
+	case errors.As(err, &throttling):
+		return errorClass{"ThrottlingError", http.StatusTooManyRequests}, true
+	case errors.As(err, &serviceUnavailable):
+		return errorClass{"ServiceUnavailableError", http.StatusServiceUnavailable}, true
+	case errors.As(err, &modelTimeout):
+		return errorClass{"ModelTimeoutError", http.StatusRequestTimeout}, true
+	case errors.As(err, &modelNotReady):
+		return errorClass{"ModelNotReadyError", http.StatusTooManyRequests}, true
+	case errors.As(err, &modelError):
+		return errorClass{"ModelError", http.StatusFailedDependency}, true
+	case errors.As(err, &modelStreamError):
+		return errorClass{"ModelStreamError", http.StatusFailedDependency}, true
+	case errors.As(err, &validation):
+		return errorClass{"ValidationError", http.StatusBadRequest}, true
+	case errors.As(err, &accessDenied):
+		return errorClass{"AccessDeniedError", http.StatusForbidden}, true
+	case errors.As(err, &notFound):
+		return errorClass{"ModelNotFoundError", http.StatusNotFound}, true
+	case errors.As(err, &quotaExceeded):
+		return errorClass{"QuotaExceededError", http.StatusBadRequest}, true
+	case errors.As(err, &internalServer):
+		return errorClass{"InternalServerError", http.StatusInternalServerError}, true
+	case errors.As(err, &conflict):
+		return errorClass{"ConflictError", http.StatusConflict}, true
+	}
+	return errorClass{}, false
+}
+
+// setError records err on result with its classification, HTTP status,
+// AWS error code and request ID
+func setError(result *InvokeResult, err error) {
+	setErrorWithFallback(result, err, "UnknownError")
+}
+
+// setStreamError records an error that ended an open response stream.
+// Exceptions Bedrock delivers mid-stream (modelStreamErrorException,
+// throttlingException, internalServerException, ...) surface from the SDK as
+// typed errors and are classified like request errors; anything else is a
+// StreamError. contentChunks is the number of content chunks received before
+// the failure.
+func setStreamError(result *InvokeResult, err error, contentChunks int) {
+	setErrorWithFallback(result, fmt.Errorf("stream error: %w", err), "StreamError")
+
+	result.FailedAfter = time.Since(result.StartTime)
+	if result.TTFT > 0 {
+		result.FailurePhase = FailurePhaseMidStream
+	} else {
+		result.FailurePhase = FailurePhaseBeforeFirstToken
+	}
+
+	// Usage is normally only reported at the end of the stream, so fall back
+	// to the chunk count as a lower bound on the tokens already generated
+	result.PartialOutputTokens = result.OutputTokens
+	if result.PartialOutputTokens == 0 {
+		result.PartialOutputTokens = contentChunks
+	}
+}
+
+// setErrorWithFallback records err on result, using fallback as the error
+// type when err is not a recognised AWS or network error
+func setErrorWithFallback(result *InvokeResult, err error, fallback string) {
+	result.Error = err
+
+	class, ok := classifyException(err)
+
+	// Real HTTP status and request ID from the transport, when a response was received
+	var respErr *awshttp.ResponseError
+	if errors.As(err, &respErr) {
+		result.HTTPStatusCode = respErr.HTTPStatusCode()
+		result.RequestID = respErr.ServiceRequestID()
+	} else if ok {
+		result.HTTPStatusCode = class.statusCode
+	}
+
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		result.ErrorCode = apiErr.ErrorCode()
+	}
+
+	switch {
+	case ok:
+		result.ErrorType = class.errorType
+	case isTimeout(err):
+		result.ErrorType = "TimeoutError"
+	case errors.Is(err, context.Canceled):
+		result.ErrorType = "CanceledError"
+	case result.ErrorCode != "":
+		// An AWS error this client does not know by type; keep its code
+		result.ErrorType = result.ErrorCode
+	default:
+		// The full error stays in result.Error for the report
+		result.ErrorType = fallback
+	}
+}
+
+// isTimeout reports whether err is a client-side or network timeout
+func isTimeout(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// setResponseMetadata records the HTTP status and request ID of a successful call
+func setResponseMetadata(result *InvokeResult, metadata middleware.Metadata) {
+	result.HTTPStatusCode = http.StatusOK
+	if resp, ok := awsmiddleware.GetRawResponse(metadata).(*smithyhttp.Response); ok && resp != nil {
+		result.HTTPStatusCode = resp.StatusCode
+	}
+	if requestID, ok := awsmiddleware.GetRequestIDMetadata(metadata); ok {
+		result.RequestID = requestID
+	}
+}
+
+// Headers Bedrock adds to InvokeModel responses
+const (
+	headerInvocationLatency = "X-Amzn-Bedrock-Invocation-Latency"
+	headerInputTokenCount   = "X-Amzn-Bedrock-Input-Token-Count"
+	headerOutputTokenCount  = "X-Amzn-Bedrock-Output-Token-Count"
+)
+
+// applyResponseHeaders copies the server-side latency and token counts that
+// Bedrock reports in InvokeModel response headers onto result. Values already
+// set from the body are kept.
+func applyResponseHeaders(result *InvokeResult, metadata middleware.Metadata) {
+	resp, ok := awsmiddleware.GetRawResponse(metadata).(*smithyhttp.Response)
+	if !ok || resp == nil {
+		return
+	}
+
+	if ms, err := strconv.Atoi(resp.Header.Get(headerInvocationLatency)); err == nil && result.ServerLatency == 0 {
+		result.ServerLatency = time.Duration(ms) * time.Millisecond
+	}
+	if n, err := strconv.Atoi(resp.Header.Get(headerInputTokenCount)); err == nil && result.InputTokens == 0 {
+		result.InputTokens = n
+	}
+	if n, err := strconv.Atoi(resp.Header.Get(headerOutputTokenCount)); err == nil && result.OutputTokens == 0 {
+		result.OutputTokens = n
+	}
+}
@@ -0,0 +1,138 @@
+package bedrock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime/types"
+	"github.com/aws/smithy-go"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+)
+
+// responseError wraps err the way the SDK does when a response was received
+func responseError(status int, requestID string, err error) error {
+	return &smithy.OperationError{
+		ServiceID:     "Bedrock Runtime",
+		OperationName: "InvokeModel",
+		Err: &awshttp.ResponseError{
+			ResponseError: &smithyhttp.ResponseError{
+				Response: &smithyhttp.Response{Response: &http.Response{StatusCode: status}},
+				Err:      err,
+			},
+			RequestID: requestID,
+		},
+	}
+}
+
+func TestClassifyException(t *testing.T) {
+	tests := []struct {
+		err        error
+		wantType   string
+		wantCode   int
+		classified bool
+	}{
+		{&types.ThrottlingException{Message: aws.String("slow down")}, "ThrottlingError", http.StatusTooManyRequests, true},
+		{&types.ValidationException{}, "ValidationError", http.StatusBadRequest, true},
+		{&types.ModelTimeoutException{}, "ModelTimeoutError", http.StatusRequestTimeout, true},
+		{&types.ServiceUnavailableException{}, "ServiceUnavailableError", http.StatusServiceUnavailable, true},
+		{&types.ModelStreamErrorException{}, "ModelStreamError", http.StatusFailedDependency, true},
+		// Wrapped exceptions are found through the error chain
+		{responseError(http.StatusTooManyRequests, "req-1", &types.ThrottlingException{}), "ThrottlingError", http.StatusTooManyRequests, true},
+		{fmt.Errorf("stream error: %w", &types.InternalServerException{}), "InternalServerError", http.StatusInternalServerError, true},
+		{&smithy.GenericAPIError{Code: "SomethingNewException"}, "", 0, false},
+		{context.DeadlineExceeded, "", 0, false},
+	}
+	for _, tt := range tests {
+		class, ok := classifyException(tt.err)
+		if ok != tt.classified || class.errorType != tt.wantType || class.statusCode != tt.wantCode {
+			t.Errorf("classifyException(%v) = %+v, %v, want {%s %d}, %v", tt.err, class, ok, tt.wantType, tt.wantCode, tt.classified)
+		}
+	}
+}
+
+func TestSetErrorWithFallback(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		fallback  string
+		errorType string
+		status    int
+		code      string
+		requestID string
+	}{
+		{
+			name:      "throttling",
+			err:       responseError(http.StatusTooManyRequests, "req-1", &types.ThrottlingException{Message: aws.String("Too many requests")}),
+			fallback:  "UnknownError",
+			errorType: "ThrottlingError", status: http.StatusTooManyRequests, code: "ThrottlingException", requestID: "req-1",
+		},
+		{
+			name:      "validation",
+			err:       responseError(http.StatusBadRequest, "req-2", &types.ValidationException{}),
+			fallback:  "UnknownError",
+			errorType: "ValidationError", status: http.StatusBadRequest, code: "ValidationException", requestID: "req-2",
+		},
+		{
+			// Without a response the documented status of the exception is used
+			name:      "model timeout",
+			err:       &types.ModelTimeoutException{},
+			fallback:  "UnknownError",
+			errorType: "ModelTimeoutError", status: http.StatusRequestTimeout, code: "ModelTimeoutException",
+		},
+		{
+			name:      "service unavailable",
+			err:       responseError(http.StatusServiceUnavailable, "req-3", &types.ServiceUnavailableException{}),
+			fallback:  "UnknownError",
+			errorType: "ServiceUnavailableError", status: http.StatusServiceUnavailable, code: "ServiceUnavailableException", requestID: "req-3",
+		},
+		{
+			name:      "unknown AWS error",
+			err:       responseError(http.StatusBadRequest, "req-4", &smithy.GenericAPIError{Code: "SomethingNewException"}),
+			fallback:  "UnknownError",
+			errorType: "SomethingNewException", status: http.StatusBadRequest, code: "SomethingNewException", requestID: "req-4",
+		},
+		{
+			name:      "response without an API error",
+			err:       responseError(http.StatusBadGateway, "req-5", errors.New("unexpected EOF")),
+			fallback:  "UnknownError",
+			errorType: "UnknownError", status: http.StatusBadGateway, requestID: "req-5",
+		},
+		{
+			name:      "context deadline",
+			err:       fmt.Errorf("operation error: %w", context.DeadlineExceeded),
+			fallback:  "UnknownError",
+			errorType: "TimeoutError",
+		},
+		{
+			name:      "canceled",
+			err:       fmt.Errorf("operation error: %w", context.Canceled),
+			fallback:  "UnknownError",
+			errorType: "CanceledError",
+		},
+		{
+			name:      "stream fallback",
+			err:       errors.New("connection reset by peer"),
+			fallback:  "StreamError",
+			errorType: "StreamError",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := &InvokeResult{}
+			setErrorWithFallback(result, tt.err, tt.fallback)
+			if result.Error != tt.err {
+				t.Errorf("Error = %v, want %v", result.Error, tt.err)
+			}
+			if result.ErrorType != tt.errorType || result.HTTPStatusCode != tt.status || result.ErrorCode != tt.code || result.RequestID != tt.requestID {
+				t.Errorf("recorded type %q, status %d, code %q, request ID %q; want %q, %d, %q, %q",
+					result.ErrorType, result.HTTPStatusCode, result.ErrorCode, result.RequestID,
+					tt.errorType, tt.status, tt.code, tt.requestID)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package bedrock
+
+import (
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime/types"
+)
+
+// Image is an image attached to the user message
+type Image struct {
+	// Format is jpeg, png, gif or webp
+	Format string
+	Data   []byte
+	// Width and Height are the pixel dimensions, 0 if unknown
+	Width  int
+	Height int
+}
+
+// MediaType returns the MIME type of the image
+func (i *Image) MediaType() string {
+	return "image/" + i.Format
+}
+
+// Document is a document attached to the user message
+type Document struct {
+	Name string
+	// Format is the file type, e.g. pdf, txt, csv, docx or html
+	Format string
+	Data   []byte
+}
+
+// converseUserContent returns the attachments followed by the prompt text,
+// the order recommended for vision and document models
+func (c *Client) converseUserContent(prompt string) []types.ContentBlock {
+	var content []types.ContentBlock
+	for _, image := range c.images {
+		content = append(content, &types.ContentBlockMemberImage{Value: types.ImageBlock{
+			Format: types.ImageFormat(image.Format),
+			Source: &types.ImageSourceMemberBytes{Value: image.Data},
+		}})
+	}
+	for _, doc := range c.documents {
+		content = append(content, &types.ContentBlockMemberDocument{Value: types.DocumentBlock{
+			Format: types.DocumentFormat(doc.Format),
+			Name:   aws.String(converseDocumentName(doc.Name)),
+			Source: &types.DocumentSourceMemberBytes{Value: doc.Data},
+		}})
+	}
+	return append(content, &types.ContentBlockMemberText{Value: prompt})
+}
+
+// converseDocumentName strips the extension and the characters Converse does
+// not allow in document names (only letters, digits, whitespace, hyphens,
+// parentheses and square brackets are accepted)
+func converseDocumentName(name string) string {
+	if i := strings.LastIndex(name, "."); i > 0 {
+		name = name[:i]
+	}
+	clean := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		case r == ' ', r == '-', r == '(', r == ')', r == '[', r == ']':
+			return r
+		default:
+			return '-'
+		}
+	}, name)
+	if clean == "" {
+		return "document"
+	}
+	return clean
+}
@@ -0,0 +1,128 @@
+package bedrock
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ModelKind classifies what a model identifier refers to
+type ModelKind string
+
+const (
+	// ModelKindFoundation is a base model ID such as anthropic.claude-3-haiku-20240307-v1:0
+	ModelKindFoundation ModelKind = "foundation-model"
+	// ModelKindCrossRegionProfile is a system-defined inference profile (us./eu./apac./global. prefix)
+	ModelKindCrossRegionProfile ModelKind = "cross-region-profile"
+	// ModelKindApplicationProfile is a user-created application inference profile ARN
+	ModelKindApplicationProfile ModelKind = "application-inference-profile"
+	// ModelKindProvisioned is a provisioned throughput ARN
+	ModelKindProvisioned ModelKind = "provisioned-model"
+	// ModelKindImported is a custom model import ARN
+	ModelKindImported ModelKind = "imported-model"
+	// ModelKindCustom is a fine-tuned custom model ARN
+	ModelKindCustom ModelKind = "custom-model"
+)
+
+// crossRegionPrefixes are the geography prefixes of cross-region inference profile IDs
+var crossRegionPrefixes = []string{"us-gov.", "us.", "eu.", "apac.", "global.", "jp.", "au.", "ca."}
+
+// ModelRef is a parsed model identifier, inference profile ID or ARN
+type ModelRef struct {
+	// ID is the identifier exactly as configured; it is what gets sent to Bedrock
+	ID   string
+	Kind ModelKind
+	// Geo is the geography of a cross-region profile (e.g. "us", "global")
+	Geo string
+	// Region and AccountID are taken from an ARN, if ID is one
+	Region    string
+	AccountID string
+	// ResourceID is the last path segment of an ARN resource
+	ResourceID string
+	// BaseModelID is the underlying foundation model, when it can be derived from ID
+	BaseModelID string
+}
+
+// ParseModelID classifies a model ID, inference profile ID or Bedrock ARN
+func ParseModelID(id string) ModelRef {
+	ref := ModelRef{ID: id, Kind: ModelKindFoundation}
+
+	resource := id
+	if strings.HasPrefix(id, "arn:") {
+		// arn:partition:bedrock:region:account:resource-type/resource-id
+		parts := strings.SplitN(id, ":", 6)
+		if len(parts) == 6 {
+			ref.Region = parts[3]
+			ref.AccountID = parts[4]
+			resource = parts[5]
+		}
+
+		resourceType, resourceID, _ := strings.Cut(resource, "/")
+		ref.ResourceID = resourceID
+		if i := strings.LastIndex(resourceID, "/"); i >= 0 {
+			ref.ResourceID = resourceID[i+1:]
+		}
+
+		switch resourceType {
+		case "foundation-model":
+			ref.BaseModelID = resourceID
+			return ref
+		case "inference-profile":
+			// System-defined profile ARN; the resource ID is a geo-prefixed model ID
+			resource = resourceID
+		case "application-inference-profile":
+			ref.Kind = ModelKindApplicationProfile
+			return ref
+		case "provisioned-model":
+			ref.Kind = ModelKindProvisioned
+			return ref
+		case "imported-model":
+			ref.Kind = ModelKindImported
+			return ref
+		case "custom-model":
+			// custom-model/<base-model-id>/<suffix>
+			ref.Kind = ModelKindCustom
+			if base, _, ok := strings.Cut(resourceID, "/"); ok {
+				ref.BaseModelID = base
+			}
+			return ref
+		default:
+			return ref
+		}
+	}
+
+	lower := strings.ToLower(resource)
+	for _, prefix := range crossRegionPrefixes {
+		if strings.HasPrefix(lower, prefix) {
+			ref.Kind = ModelKindCrossRegionProfile
+			ref.Geo = strings.TrimSuffix(prefix, ".")
+			ref.BaseModelID = resource[len(prefix):]
+			return ref
+		}
+	}
+
+	ref.BaseModelID = resource
+	return ref
+}
+
+// DisplayName returns a short human-readable name for reports
+func (r ModelRef) DisplayName() string {
+	switch r.Kind {
+	case ModelKindFoundation:
+		return r.BaseModelID
+	case ModelKindCrossRegionProfile:
+		return fmt.Sprintf("%s (%s cross-region)", r.BaseModelID, r.Geo)
+	case ModelKindApplicationProfile:
+		return fmt.Sprintf("application profile %s", r.ResourceID)
+	case ModelKindProvisioned:
+		return fmt.Sprintf("provisioned %s", r.ResourceID)
+	case ModelKindImported:
+		return fmt.Sprintf("imported %s", r.ResourceID)
+	case ModelKindCustom:
+		if r.BaseModelID != "" {
+			return fmt.Sprintf("custom %s (%s)", r.ResourceID, r.BaseModelID)
+		}
+		return fmt.Sprintf("custom %s", r.ResourceID)
+	default:
+		return r.ID
+	}
+}
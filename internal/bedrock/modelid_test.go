@@ -0,0 +1,83 @@
+package bedrock
+
+import "testing"
+
+func TestParseModelID(t *testing.T) {
+	tests := []struct {
+		id   string
+		want ModelRef
+		name string
+	}{
+		{
+			id:   "anthropic.claude-3-haiku-20240307-v1:0",
+			want: ModelRef{Kind: ModelKindFoundation, BaseModelID: "anthropic.claude-3-haiku-20240307-v1:0"},
+			name: "anthropic.claude-3-haiku-20240307-v1:0",
+		},
+		{
+			id:   "us.anthropic.claude-3-5-haiku-20241022-v1:0",
+			want: ModelRef{Kind: ModelKindCrossRegionProfile, Geo: "us", BaseModelID: "anthropic.claude-3-5-haiku-20241022-v1:0"},
+			name: "anthropic.claude-3-5-haiku-20241022-v1:0 (us cross-region)",
+		},
+		{
+			// us-gov. must not be read as us. followed by "gov.anthropic..."
+			id:   "us-gov.anthropic.claude-3-haiku-20240307-v1:0",
+			want: ModelRef{Kind: ModelKindCrossRegionProfile, Geo: "us-gov", BaseModelID: "anthropic.claude-3-haiku-20240307-v1:0"},
+			name: "anthropic.claude-3-haiku-20240307-v1:0 (us-gov cross-region)",
+		},
+		{
+			id:   "apac.amazon.nova-lite-v1:0",
+			want: ModelRef{Kind: ModelKindCrossRegionProfile, Geo: "apac", BaseModelID: "amazon.nova-lite-v1:0"},
+			name: "amazon.nova-lite-v1:0 (apac cross-region)",
+		},
+		{
+			id:   "global.anthropic.claude-sonnet-4-20250514-v1:0",
+			want: ModelRef{Kind: ModelKindCrossRegionProfile, Geo: "global", BaseModelID: "anthropic.claude-sonnet-4-20250514-v1:0"},
+			name: "anthropic.claude-sonnet-4-20250514-v1:0 (global cross-region)",
+		},
+		{
+			id:   "JP.anthropic.claude-3-5-sonnet-20240620-v1:0",
+			want: ModelRef{Kind: ModelKindCrossRegionProfile, Geo: "jp", BaseModelID: "anthropic.claude-3-5-sonnet-20240620-v1:0"},
+			name: "anthropic.claude-3-5-sonnet-20240620-v1:0 (jp cross-region)",
+		},
+		{
+			id:   "arn:aws:bedrock:us-west-2::foundation-model/meta.llama3-8b-instruct-v1:0",
+			want: ModelRef{Kind: ModelKindFoundation, Region: "us-west-2", ResourceID: "meta.llama3-8b-instruct-v1:0", BaseModelID: "meta.llama3-8b-instruct-v1:0"},
+			name: "meta.llama3-8b-instruct-v1:0",
+		},
+		{
+			id:   "arn:aws:bedrock:eu-west-1:123456789012:inference-profile/eu.amazon.nova-pro-v1:0",
+			want: ModelRef{Kind: ModelKindCrossRegionProfile, Geo: "eu", Region: "eu-west-1", AccountID: "123456789012", ResourceID: "eu.amazon.nova-pro-v1:0", BaseModelID: "amazon.nova-pro-v1:0"},
+			name: "amazon.nova-pro-v1:0 (eu cross-region)",
+		},
+		{
+			id:   "arn:aws:bedrock:us-east-1:123456789012:application-inference-profile/a1b2c3d4e5f6",
+			want: ModelRef{Kind: ModelKindApplicationProfile, Region: "us-east-1", AccountID: "123456789012", ResourceID: "a1b2c3d4e5f6"},
+			name: "application profile a1b2c3d4e5f6",
+		},
+		{
+			id:   "arn:aws:bedrock:us-east-1:123456789012:provisioned-model/pt0123abcd",
+			want: ModelRef{Kind: ModelKindProvisioned, Region: "us-east-1", AccountID: "123456789012", ResourceID: "pt0123abcd"},
+			name: "provisioned pt0123abcd",
+		},
+		{
+			id:   "arn:aws:bedrock:us-east-1:123456789012:imported-model/im0123abcd",
+			want: ModelRef{Kind: ModelKindImported, Region: "us-east-1", AccountID: "123456789012", ResourceID: "im0123abcd"},
+			name: "imported im0123abcd",
+		},
+		{
+			id:   "arn:aws:bedrock:us-east-1:123456789012:custom-model/anthropic.claude-3-haiku-20240307-v1:0/cm0123abcd",
+			want: ModelRef{Kind: ModelKindCustom, Region: "us-east-1", AccountID: "123456789012", ResourceID: "cm0123abcd", BaseModelID: "anthropic.claude-3-haiku-20240307-v1:0"},
+			name: "custom cm0123abcd (anthropic.claude-3-haiku-20240307-v1:0)",
+		},
+	}
+	for _, tt := range tests {
+		tt.want.ID = tt.id
+		got := ParseModelID(tt.id)
+		if got != tt.want {
+			t.Errorf("ParseModelID(%q) = %+v, want %+v", tt.id, got, tt.want)
+		}
+		if name := got.DisplayName(); name != tt.name {
+			t.Errorf("DisplayName of %q = %q, want %q", tt.id, name, tt.name)
+		}
+	}
+}
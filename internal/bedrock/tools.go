@@ -0,0 +1,99 @@
+package bedrock
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// ToolSpec describes a tool the model may call. InputSchema is a JSON Schema object.
+type ToolSpec struct {
+	Name        string
+	Description string
+	InputSchema json.RawMessage
+}
+
+// ToolCall is a tool_use block emitted by the model
+type ToolCall struct {
+	ID   string
+	Name string
+	// Input is the raw tool input JSON as generated by the model
+	Input string
+}
+
+// ValidInput reports whether the tool input is a JSON object
+func (t *ToolCall) ValidInput() bool {
+	var input map[string]interface{}
+	return json.Unmarshal([]byte(t.Input), &input) == nil
+}
+
+// inputObject returns the tool input as a JSON value, or an empty object if it does not parse
+func (t *ToolCall) inputObject() map[string]interface{} {
+	input := make(map[string]interface{})
+	_ = json.Unmarshal([]byte(t.Input), &input)
+	return input
+}
+
+// toolTracker assembles tool_use blocks from stream events and timestamps them
+type toolTracker struct {
+	current *ToolCall
+	input   strings.Builder
+}
+
+// start opens a tool_use block, setting TimeToFirstToolUse on the first one
+func (t *toolTracker) start(call ToolCall, result *InvokeResult) {
+	t.finish(result)
+	if result.TimeToFirstToolUse == 0 {
+		result.TimeToFirstToolUse = time.Since(result.StartTime)
+	}
+	t.current = &call
+	t.input.Reset()
+}
+
+// appendInput adds a fragment of the tool input JSON to the open block
+func (t *toolTracker) appendInput(fragment string) {
+	if t.current != nil {
+		t.input.WriteString(fragment)
+	}
+}
+
+// finish closes the open tool_use block, if any, setting
+// TimeToToolInputComplete when the first tool input is complete
+func (t *toolTracker) finish(result *InvokeResult) {
+	if t.current == nil {
+		return
+	}
+	t.current.Input = t.input.String()
+	if t.current.Input == "" {
+		t.current.Input = "{}"
+	}
+	if result.TimeToToolInputComplete == 0 {
+		result.TimeToToolInputComplete = time.Since(result.StartTime)
+	}
+	result.addToolCall(*t.current)
+	t.current = nil
+}
+
+// addToolCall records a complete tool call and counts invalid input
+func (r *InvokeResult) addToolCall(call ToolCall) {
+	r.ToolCalls = append(r.ToolCalls, call)
+	if !call.ValidInput() {
+		r.InvalidToolInputs++
+	}
+}
+
+// InvokeToolFollowUp runs the second turn of a tool-use conversation: the
+// original prompt, the assistant's tool calls from first, and the configured
+// canned tool result for each call. It returns nil if first made no tool calls.
+func (c *Client) InvokeToolFollowUp(ctx context.Context, prompt string, first *InvokeResult, streaming bool) *InvokeResult {
+	if first == nil || len(first.ToolCalls) == 0 {
+		return nil
+	}
+	return c.invoke(ctx, func(ctx context.Context) *InvokeResult {
+		if streaming {
+			return c.invokeStreaming(ctx, prompt, first.ToolCalls)
+		}
+		return c.invokeNonStreaming(ctx, prompt, first.ToolCalls)
+	})
+}
@@ -1,19 +1,95 @@
 package bedrock
 
-import "time"
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
 
 // InvokeResult contains the result of a Bedrock API invocation
 type InvokeResult struct {
 	Success         bool
 	StartTime       time.Time
 	EndTime         time.Time
-	TTFT            time.Duration // Time to first token (only for streaming)
+	TTFT            time.Duration // Time to first token of any kind, thinking or answer (only for streaming)
 	InputTokens     int
 	OutputTokens    int
 	Error           error
 	ErrorType       string
-	HTTPStatusCode  int    // HTTP response status code
+	HTTPStatusCode  int           // HTTP response status code
+	ErrorCode       string        // AWS error code (e.g. ThrottlingException)
+	RequestID       string        // AWS request ID, for correlating with CloudTrail and support cases
+	ServerLatency   time.Duration // Server-side latency reported by Bedrock (0 if unavailable)
 	ResponseContent string
+	Attempts        int    // HTTP attempts the SDK made, retries included
+	StopReason      string // why generation stopped, as reported by the model (e.g. end_turn, max_tokens, length)
+
+	// PerformanceLatency is the latency mode Bedrock served the request with
+	// ("standard" or "optimized"; empty if not reported)
+	PerformanceLatency string
+
+	// ServerFirstByteLatency is Bedrock's firstByteLatency for streams (0 if unavailable)
+	ServerFirstByteLatency time.Duration
+
+	// Reasoning models: time to the first thinking and first answer token
+	// (only for streaming), the thinking text, and the thinking tokens included
+	// in OutputTokens (estimated from text length when not reported)
+	TimeToFirstThinking time.Duration
+	TimeToFirstAnswer   time.Duration
+	ThinkingContent     string
+	ThinkingTokens      int
+
+	// Prompt caching token counts; InputTokens excludes both
+	CacheReadInputTokens  int
+	CacheWriteInputTokens int
+
+	// InterChunkGaps holds the time between consecutive content chunks (only for streaming)
+	InterChunkGaps []time.Duration
+
+	// Streaming failure details (only set when a streaming request fails)
+	FailurePhase        FailurePhase
+	FailedAfter         time.Duration // time from request start to the failure
+	PartialOutputTokens int           // tokens generated before a mid-stream failure (lower bound)
+
+	// Tool use: the tool calls made, the time to the first tool_use block and
+	// to its complete input JSON (only for streaming), and the number of calls
+	// whose input did not parse as a JSON object
+	ToolCalls               []ToolCall
+	TimeToFirstToolUse      time.Duration
+	TimeToToolInputComplete time.Duration
+	InvalidToolInputs       int
+
+	// Content validation: Validated is set when the checks ran on a
+	// successful response, QualityFailure names the check it failed (empty
+	// if it passed) and QualityDetail says why
+	Validated      bool
+	QualityFailure string
+	QualityDetail  string
+
+	// FollowUp is the second turn that returned the canned tool result, if one was run
+	FollowUp *InvokeResult
+}
+
+// FailurePhase describes when a streaming request failed
+type FailurePhase string
+
+const (
+	// FailurePhaseRequest means the call was rejected before a stream was opened
+	FailurePhaseRequest FailurePhase = "request"
+	// FailurePhaseBeforeFirstToken means the stream opened but failed before any content arrived
+	FailurePhaseBeforeFirstToken FailurePhase = "before_first_token"
+	// FailurePhaseMidStream means the stream failed after content had started arriving
+	FailurePhaseMidStream FailurePhase = "mid_stream"
+)
+
+// HitMaxTokens reports whether generation stopped at the max_tokens limit,
+// i.e. the response is truncated
+func (r *InvokeResult) HitMaxTokens() bool {
+	switch strings.ToLower(r.StopReason) {
+	case "max_tokens", "length":
+		return true
+	}
+	return false
 }
 
 // Duration returns the total duration of the request
@@ -21,58 +97,192 @@ func (r *InvokeResult) Duration() time.Duration {
 	return r.EndTime.Sub(r.StartTime)
 }
 
+// TPOT returns the time per output token after the first one,
+// (duration - TTFT) / (output tokens - 1), or 0 if it cannot be computed
+func (r *InvokeResult) TPOT() time.Duration {
+	if r.TTFT <= 0 || r.OutputTokens < 2 {
+		return 0
+	}
+	return (r.Duration() - r.TTFT) / time.Duration(r.OutputTokens-1)
+}
+
+// ClientOverhead returns the part of the request duration not spent inside
+// Bedrock (network, TLS, SDK and client time), or 0 if the server latency is unknown
+func (r *InvokeResult) ClientOverhead() time.Duration {
+	if r.ServerLatency <= 0 {
+		return 0
+	}
+	overhead := r.Duration() - r.ServerLatency
+	if overhead < 0 {
+		return 0
+	}
+	return overhead
+}
+
+// recordChunk timestamps a content chunk, setting TTFT on the first one and
+// recording the gap since the previous chunk otherwise. last is the arrival
+// time of the previous chunk and is updated in place. thinking marks
+// reasoning content, which sets TimeToFirstThinking instead of TimeToFirstAnswer.
+func (r *InvokeResult) recordChunk(last *time.Time, thinking bool) {
+	now := time.Now()
+	elapsed := now.Sub(r.StartTime)
+	if last.IsZero() {
+		r.TTFT = elapsed
+	} else {
+		r.InterChunkGaps = append(r.InterChunkGaps, now.Sub(*last))
+	}
+	*last = now
+
+	if thinking && r.TimeToFirstThinking == 0 {
+		r.TimeToFirstThinking = elapsed
+	}
+	if !thinking && r.TimeToFirstAnswer == 0 {
+		r.TimeToFirstAnswer = elapsed
+	}
+}
+
+// AnswerTokens returns the output tokens that were not thinking tokens
+func (r *InvokeResult) AnswerTokens() int {
+	return r.OutputTokens - r.ThinkingTokens
+}
+
+// estimateThinkingTokens splits OutputTokens by the share of thinking text
+// when the model reported thinking content but not a thinking token count
+func (r *InvokeResult) estimateThinkingTokens() {
+	if r.ThinkingTokens > 0 || r.ThinkingContent == "" || r.OutputTokens == 0 {
+		return
+	}
+	thinking := len(r.ThinkingContent)
+	total := thinking + len(r.ResponseContent)
+	r.ThinkingTokens = r.OutputTokens * thinking / total
+}
+
+// CacheHit reports whether any input tokens were read from the prompt cache
+func (r *InvokeResult) CacheHit() bool {
+	return r.CacheReadInputTokens > 0
+}
+
 // ClaudeRequest represents a request to Claude models
 type ClaudeRequest struct {
-	AnthropicVersion string          `json:"anthropic_version"`
-	MaxTokens        int             `json:"max_tokens"`
-	Messages         []ClaudeMessage `json:"messages"`
-	Temperature      float64         `json:"temperature,omitempty"`
+	AnthropicVersion string              `json:"anthropic_version"`
+	MaxTokens        int                 `json:"max_tokens"`
+	System           []ClaudeSystemBlock `json:"system,omitempty"`
+	Messages         []ClaudeMessage     `json:"messages"`
+	Temperature      float64             `json:"temperature,omitempty"`
+	TopP             float64             `json:"top_p,omitempty"`
+	TopK             int                 `json:"top_k,omitempty"`
+	StopSequences    []string            `json:"stop_sequences,omitempty"`
+	Thinking         *ClaudeThinking     `json:"thinking,omitempty"`
+	Tools            []ClaudeTool        `json:"tools,omitempty"`
+	ToolChoice       *ClaudeToolChoice   `json:"tool_choice,omitempty"`
 }
 
-// ClaudeMessage represents a message in Claude request
+// ClaudeTool is a tool definition in a Claude request
+type ClaudeTool struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	InputSchema json.RawMessage `json:"input_schema"`
+}
+
+// ClaudeToolChoice controls whether and which tool the model must call
+type ClaudeToolChoice struct {
+	Type string `json:"type"`
+	Name string `json:"name,omitempty"`
+}
+
+// ClaudeThinking enables extended thinking with a token budget
+type ClaudeThinking struct {
+	Type         string `json:"type"`
+	BudgetTokens int    `json:"budget_tokens"`
+}
+
+// ClaudeSystemBlock is a text block of the Claude system prompt
+type ClaudeSystemBlock struct {
+	Type         string              `json:"type"`
+	Text         string              `json:"text"`
+	CacheControl *ClaudeCacheControl `json:"cache_control,omitempty"`
+}
+
+// ClaudeCacheControl marks the end of a cacheable prompt prefix
+type ClaudeCacheControl struct {
+	Type string `json:"type"`
+}
+
+// ClaudeMessage represents a message in Claude request. Content is either a
+// string or a list of ClaudeRequestBlock.
 type ClaudeMessage struct {
-	Role    string `json:"role"`
-	Content string `json:"content"`
+	Role    string      `json:"role"`
+	Content interface{} `json:"content"`
+}
+
+// ClaudeRequestBlock is a content block of a request message: text, an
+// image or document, an assistant tool_use, or a user tool_result
+type ClaudeRequestBlock struct {
+	Type      string          `json:"type"`
+	Text      string          `json:"text,omitempty"`
+	ID        string          `json:"id,omitempty"`
+	Name      string          `json:"name,omitempty"`
+	Input     json.RawMessage `json:"input,omitempty"`
+	ToolUseID string          `json:"tool_use_id,omitempty"`
+	Content   string          `json:"content,omitempty"`
+	Source    *ClaudeSource   `json:"source,omitempty"`
+}
+
+// ClaudeSource is the data of an image or document block
+type ClaudeSource struct {
+	Type      string `json:"type"`
+	MediaType string `json:"media_type"`
+	Data      string `json:"data"`
 }
 
 // ClaudeResponse represents a response from Claude models
 type ClaudeResponse struct {
-	ID           string              `json:"id"`
-	Type         string              `json:"type"`
-	Role         string              `json:"role"`
-	Content      []ClaudeContent     `json:"content"`
-	Model        string              `json:"model"`
-	StopReason   string              `json:"stop_reason"`
-	Usage        ClaudeUsage         `json:"usage"`
+	ID         string          `json:"id"`
+	Type       string          `json:"type"`
+	Role       string          `json:"role"`
+	Content    []ClaudeContent `json:"content"`
+	Model      string          `json:"model"`
+	StopReason string          `json:"stop_reason"`
+	Usage      ClaudeUsage     `json:"usage"`
 }
 
 // ClaudeContent represents content in Claude response
 type ClaudeContent struct {
-	Type string `json:"type"`
-	Text string `json:"text"`
+	Type     string `json:"type"`
+	Text     string `json:"text"`
+	Thinking string `json:"thinking,omitempty"`
+	// Tool use blocks
+	ID    string          `json:"id,omitempty"`
+	Name  string          `json:"name,omitempty"`
+	Input json.RawMessage `json:"input,omitempty"`
 }
 
 // ClaudeUsage represents token usage in Claude response
 type ClaudeUsage struct {
-	InputTokens  int `json:"input_tokens"`
-	OutputTokens int `json:"output_tokens"`
+	InputTokens              int `json:"input_tokens"`
+	OutputTokens             int `json:"output_tokens"`
+	CacheReadInputTokens     int `json:"cache_read_input_tokens"`
+	CacheCreationInputTokens int `json:"cache_creation_input_tokens"`
 }
 
 // ClaudeStreamEvent represents a streaming event from Claude
 type ClaudeStreamEvent struct {
-	Type         string              `json:"type"`
-	Index        int                 `json:"index,omitempty"`
-	Delta        *ClaudeStreamDelta  `json:"delta,omitempty"`
-	ContentBlock *ClaudeContent      `json:"content_block,omitempty"`
-	Message      *ClaudeResponse     `json:"message,omitempty"`
-	Usage        *ClaudeUsage        `json:"usage,omitempty"`
+	Type         string             `json:"type"`
+	Index        int                `json:"index,omitempty"`
+	Delta        *ClaudeStreamDelta `json:"delta,omitempty"`
+	ContentBlock *ClaudeContent     `json:"content_block,omitempty"`
+	Message      *ClaudeResponse    `json:"message,omitempty"`
+	Usage        *ClaudeUsage       `json:"usage,omitempty"`
 }
 
 // ClaudeStreamDelta represents a delta in streaming response
 type ClaudeStreamDelta struct {
-	Type       string `json:"type"`
-	Text       string `json:"text,omitempty"`
-	StopReason string `json:"stop_reason,omitempty"`
+	Type     string `json:"type"`
+	Text     string `json:"text,omitempty"`
+	Thinking string `json:"thinking,omitempty"`
+	// PartialJSON is a fragment of tool input in an input_json_delta
+	PartialJSON string `json:"partial_json,omitempty"`
+	StopReason  string `json:"stop_reason,omitempty"`
 }
 
 // LlamaRequest represents a request to Llama models
@@ -80,6 +290,9 @@ type LlamaRequest struct {
 	Prompt      string  `json:"prompt"`
 	MaxGenLen   int     `json:"max_gen_len"`
 	Temperature float64 `json:"temperature,omitempty"`
+	TopP        float64 `json:"top_p,omitempty"`
+	// Images are base64 encoded by encoding/json; the prompt holds one <|image|> tag per image
+	Images [][]byte `json:"images,omitempty"`
 }
 
 // LlamaResponse represents a response from Llama models
@@ -92,39 +305,45 @@ type LlamaResponse struct {
 
 // DeepSeekResponse represents a response from DeepSeek models (OpenAI format)
 type DeepSeekResponse struct {
-	ID      string                 `json:"id"`
-	Object  string                 `json:"object"`
-	Created int64                  `json:"created"`
-	Model   string                 `json:"model"`
-	Choices []DeepSeekChoice       `json:"choices"`
-	Usage   DeepSeekUsage          `json:"usage"`
+	ID      string           `json:"id"`
+	Object  string           `json:"object"`
+	Created int64            `json:"created"`
+	Model   string           `json:"model"`
+	Choices []DeepSeekChoice `json:"choices"`
+	Usage   DeepSeekUsage    `json:"usage"`
 }
 
 // DeepSeekChoice represents a choice in DeepSeek response
 type DeepSeekChoice struct {
-	Index        int                   `json:"index"`
-	Message      DeepSeekMessage       `json:"message"`
-	FinishReason string                `json:"finish_reason"`
-	Delta        *DeepSeekStreamDelta  `json:"delta,omitempty"`
+	Index        int                  `json:"index"`
+	Message      DeepSeekMessage      `json:"message"`
+	FinishReason string               `json:"finish_reason"`
+	Delta        *DeepSeekStreamDelta `json:"delta,omitempty"`
 }
 
 // DeepSeekMessage represents a message in DeepSeek response
 type DeepSeekMessage struct {
 	Role    string `json:"role"`
 	Content string `json:"content"`
+	// ReasoningContent carries the chain of thought of reasoning models
+	ReasoningContent string `json:"reasoning_content,omitempty"`
 }
 
 // DeepSeekUsage represents token usage in DeepSeek response
 type DeepSeekUsage struct {
-	PromptTokens     int `json:"prompt_tokens"`
-	CompletionTokens int `json:"completion_tokens"`
-	TotalTokens      int `json:"total_tokens"`
+	PromptTokens            int `json:"prompt_tokens"`
+	CompletionTokens        int `json:"completion_tokens"`
+	TotalTokens             int `json:"total_tokens"`
+	CompletionTokensDetails *struct {
+		ReasoningTokens int `json:"reasoning_tokens"`
+	} `json:"completion_tokens_details,omitempty"`
 }
 
 // DeepSeekStreamDelta represents a streaming delta from DeepSeek
 type DeepSeekStreamDelta struct {
-	Role    string `json:"role,omitempty"`
-	Content string `json:"content,omitempty"`
+	Role             string `json:"role,omitempty"`
+	Content          string `json:"content,omitempty"`
+	ReasoningContent string `json:"reasoning_content,omitempty"`
 }
 
 // DeepSeekStreamEvent represents a streaming event from DeepSeek (OpenAI format)
@@ -136,3 +355,16 @@ type DeepSeekStreamEvent struct {
 	Choices []DeepSeekChoice `json:"choices"`
 	Usage   *DeepSeekUsage   `json:"usage,omitempty"`
 }
+
+// InvocationMetrics contains the amazon-bedrock-invocationMetrics reported by Bedrock
+type InvocationMetrics struct {
+	InputTokenCount   int `json:"inputTokenCount"`
+	OutputTokenCount  int `json:"outputTokenCount"`
+	InvocationLatency int `json:"invocationLatency"`
+	FirstByteLatency  int `json:"firstByteLatency"`
+}
+
+// InvocationMetricsEnvelope wraps the invocation metrics field of a payload
+type InvocationMetricsEnvelope struct {
+	Metrics *InvocationMetrics `json:"amazon-bedrock-invocationMetrics,omitempty"`
+}
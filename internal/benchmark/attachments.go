@@ -0,0 +1,161 @@
+package benchmark
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	_ "image/gif" // registers the GIF decoder for image.Decode
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"bedrock-performance/internal/bedrock"
+	"bedrock-performance/internal/config"
+)
+
+// imageFormats maps image file extensions to Bedrock image formats
+var imageFormats = map[string]string{
+	".jpg":  "jpeg",
+	".jpeg": "jpeg",
+	".png":  "png",
+	".gif":  "gif",
+	".webp": "webp",
+}
+
+// documentFormats lists the document file types Bedrock accepts
+var documentFormats = map[string]bool{
+	"pdf": true, "csv": true, "doc": true, "docx": true, "xls": true,
+	"xlsx": true, "html": true, "txt": true, "md": true,
+}
+
+// attachments holds the image and document files of test.multimodal and the
+// resized images derived from them
+type attachments struct {
+	images    []sourceImage
+	documents []bedrock.Document
+	resized   map[resizeKey]bedrock.Image
+}
+
+// sourceImage is an image file as read from disk
+type sourceImage struct {
+	name   string
+	format string
+	data   []byte
+}
+
+// resizeKey identifies an image file resized to a resolution
+type resizeKey struct {
+	index      int
+	resolution int
+}
+
+// loadAttachments reads the configured image and document files
+func loadAttachments(cfg config.MultimodalConfig) (*attachments, error) {
+	a := &attachments{resized: make(map[resizeKey]bedrock.Image)}
+
+	for _, path := range cfg.Images {
+		format, ok := imageFormats[strings.ToLower(filepath.Ext(path))]
+		if !ok {
+			return nil, fmt.Errorf("unsupported image file %s (use jpeg, png, gif or webp)", path)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read image: %w", err)
+		}
+		a.images = append(a.images, sourceImage{name: path, format: format, data: data})
+	}
+
+	for _, path := range cfg.Documents {
+		format := strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+		if !documentFormats[format] {
+			return nil, fmt.Errorf("unsupported document file %s (use pdf, csv, doc, docx, xls, xlsx, html, txt or md)", path)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read document: %w", err)
+		}
+		a.documents = append(a.documents, bedrock.Document{Name: filepath.Base(path), Format: format, Data: data})
+	}
+
+	return a, nil
+}
+
+// imageSet returns count images at the given resolution, cycling through the
+// image files. Resized images are cached so every variant reuses them.
+func (a *attachments) imageSet(count, resolution int) ([]bedrock.Image, error) {
+	if count == 0 || len(a.images) == 0 {
+		return nil, nil
+	}
+
+	images := make([]bedrock.Image, 0, count)
+	for i := 0; i < count; i++ {
+		key := resizeKey{index: i % len(a.images), resolution: resolution}
+		img, ok := a.resized[key]
+		if !ok {
+			var err error
+			img, err = resizeImage(a.images[key.index], resolution)
+			if err != nil {
+				return nil, err
+			}
+			a.resized[key] = img
+		}
+		images = append(images, img)
+	}
+	return images, nil
+}
+
+// resizeImage scales src so its longest side is resolution pixels, using
+// nearest-neighbour sampling; the token cost of an image depends on its
+// dimensions, not its quality. Resolution 0 returns the file unchanged.
+// JPEG files stay JPEG and other formats are re-encoded as PNG.
+func resizeImage(src sourceImage, resolution int) (bedrock.Image, error) {
+	if resolution == 0 {
+		img := bedrock.Image{Format: src.format, Data: src.data}
+		if cfg, _, err := image.DecodeConfig(bytes.NewReader(src.data)); err == nil {
+			img.Width, img.Height = cfg.Width, cfg.Height
+		}
+		return img, nil
+	}
+	if src.format == "webp" {
+		return bedrock.Image{}, fmt.Errorf("cannot resize %s: webp images can only be sent at resolution 0", src.name)
+	}
+
+	decoded, _, err := image.Decode(bytes.NewReader(src.data))
+	if err != nil {
+		return bedrock.Image{}, fmt.Errorf("failed to decode image %s: %w", src.name, err)
+	}
+
+	bounds := decoded.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width >= height {
+		height = max(1, height*resolution/width)
+		width = resolution
+	} else {
+		width = max(1, width*resolution/height)
+		height = resolution
+	}
+
+	scaled := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		srcY := bounds.Min.Y + y*bounds.Dy()/height
+		for x := 0; x < width; x++ {
+			srcX := bounds.Min.X + x*bounds.Dx()/width
+			scaled.Set(x, y, decoded.At(srcX, srcY))
+		}
+	}
+
+	var buf bytes.Buffer
+	format := "png"
+	if src.format == "jpeg" {
+		format = "jpeg"
+		err = jpeg.Encode(&buf, scaled, &jpeg.Options{Quality: 90})
+	} else {
+		err = png.Encode(&buf, scaled)
+	}
+	if err != nil {
+		return bedrock.Image{}, fmt.Errorf("failed to encode image %s: %w", src.name, err)
+	}
+	return bedrock.Image{Format: format, Data: buf.Bytes(), Width: width, Height: height}, nil
+}
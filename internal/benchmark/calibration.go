@@ -0,0 +1,225 @@
+package benchmark
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+
+	"bedrock-performance/internal/bedrock"
+	"bedrock-performance/internal/types"
+)
+
+// maxCalibrationProbes bounds the probe requests sent per model
+const maxCalibrationProbes = 8
+
+// calibrationEntry is the cached prompt size of one model and request format
+type calibrationEntry struct {
+	TargetTokens int    `json:"target_tokens"`
+	PromptSize   int    `json:"prompt_size"`
+	ProbeTokens  int    `json:"probe_tokens"`
+	Converged    bool   `json:"converged"`
+	Fingerprint  string `json:"fingerprint"`
+}
+
+// calibrator finds the prompt size in characters that makes a model report
+// the target number of input tokens, and caches the result per model and
+// request format.
+//
+// The input token count of a probe includes everything sent with the prompt
+// (system prompt, shared prefix, tool definitions and the model's chat
+// template), so the target is the total input of a text-only request.
+type calibrator struct {
+	newInvoker InvokerFactory
+	template   string
+	target     int
+	tolerance  float64 // fraction of target
+	file       string
+	entries    map[string]calibrationEntry
+	// countTokens probes with the CountTokens API instead of invocations
+	countTokens bool
+}
+
+// newCalibrator creates a calibrator for target input tokens within
+// tolerancePercent, loading previous results from file when it is set
+func newCalibrator(newInvoker InvokerFactory, template string, target int, tolerancePercent float64, file string) (*calibrator, error) {
+	c := &calibrator{
+		newInvoker: newInvoker,
+		template:   template,
+		target:     target,
+		tolerance:  tolerancePercent / 100,
+		file:       file,
+		entries:    make(map[string]calibrationEntry),
+	}
+	if file == "" {
+		return c, nil
+	}
+	data, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read calibration file: %w", err)
+	}
+	if err := json.Unmarshal(data, &c.entries); err != nil {
+		return nil, fmt.Errorf("failed to parse calibration file %s: %w", file, err)
+	}
+	return c, nil
+}
+
+// calibrate returns the prompt size for the model of cfg, probing the model
+// unless a size for the same target and request content is cached
+func (c *calibrator) calibrate(ctx context.Context, cfg *bedrock.ClientConfig) (*types.PromptCalibration, error) {
+	key := calibrationKey(cfg)
+	fingerprint := c.fingerprint(cfg)
+	if entry, ok := c.entries[key]; ok && entry.TargetTokens == c.target && entry.Fingerprint == fingerprint {
+		return &types.PromptCalibration{
+			TargetTokens: c.target,
+			PromptSize:   entry.PromptSize,
+			ProbeTokens:  entry.ProbeTokens,
+			Converged:    entry.Converged,
+		}, nil
+	}
+
+	// Probe with the text-only request: no images, cache breakpoint or
+	// thinking, and just enough output to get a valid response
+	probe := *cfg
+	probe.Images = nil
+	probe.PromptCaching = false
+	probe.ThinkingBudget = 0
+	probe.ReasoningEffort = ""
+	probe.ServiceTier = bedrock.ServiceTierDefault
+	probe.PerformanceLatency = bedrock.PerformanceLatencyStandard
+	probe.ToolResult = ""
+	probe.MaxTokens = 16
+	invoker := c.newInvoker(&probe)
+
+	result := &types.PromptCalibration{TargetTokens: c.target}
+	best := math.MaxInt
+	// Start from ~4 characters per token, then fit a line through the last
+	// two probes so fixed overhead tokens do not skew the estimate
+	size := c.target * 4
+	var prevSize, prevTokens int
+	for result.Probes < maxCalibrationProbes {
+		tokens, err := c.probe(ctx, invoker, size)
+		if err != nil {
+			return nil, fmt.Errorf("calibration probe for %s failed: %w", cfg.ModelID, err)
+		}
+		result.Probes++
+
+		if diff := absInt(tokens - c.target); diff < best {
+			best = diff
+			result.PromptSize, result.ProbeTokens = size, tokens
+		}
+		if float64(best) <= c.tolerance*float64(c.target) {
+			result.Converged = true
+			break
+		}
+
+		next := size * c.target / tokens
+		if prevTokens > 0 && tokens != prevTokens && size != prevSize {
+			slope := float64(tokens-prevTokens) / float64(size-prevSize)
+			if slope > 0 {
+				next = size + int(math.Round(float64(c.target-tokens)/slope))
+			}
+		}
+		next = max(next, 1)
+		if next == size {
+			// The text cannot get any closer, e.g. the fixed overhead alone
+			// exceeds the target
+			break
+		}
+		prevSize, prevTokens = size, tokens
+		size = next
+	}
+
+	c.entries[key] = calibrationEntry{
+		TargetTokens: c.target,
+		PromptSize:   result.PromptSize,
+		ProbeTokens:  result.ProbeTokens,
+		Converged:    result.Converged,
+		Fingerprint:  fingerprint,
+	}
+	return result, nil
+}
+
+// calibrationKey identifies the model and the request format a size applies
+// to: the same prompt is wrapped differently over InvokeModel and Converse
+// and by each adapter format, so it counts as different input tokens
+func calibrationKey(cfg *bedrock.ClientConfig) string {
+	api := cfg.API
+	if api == "" {
+		api = bedrock.APIInvoke
+	}
+	return cfg.ModelID + "|" + string(api) + "|" + cfg.Format
+}
+
+// probe sends or counts the prompt of size characters and returns its input tokens
+func (c *calibrator) probe(ctx context.Context, invoker Invoker, size int) (int, error) {
+	prompt := GeneratePrompt(c.template, size)
+	if c.countTokens {
+		tokens, err := invoker.CountTokens(ctx, prompt)
+		if err != nil {
+			return 0, err
+		}
+		if tokens <= 0 {
+			return 0, fmt.Errorf("CountTokens reported %d input tokens", tokens)
+		}
+		return tokens, nil
+	}
+	result := invoker.InvokeNonStreaming(ctx, prompt)
+	if !result.Success {
+		return 0, result.Error
+	}
+	tokens := result.InputTokens + result.CacheReadInputTokens + result.CacheWriteInputTokens
+	if tokens == 0 {
+		return 0, fmt.Errorf("the response did not report input tokens")
+	}
+	return tokens, nil
+}
+
+// fingerprint identifies the request content besides the prompt text that
+// counts toward input tokens, and the probe method, so a cached size is not
+// reused for different content or a size from CountTokens for a real run
+func (c *calibrator) fingerprint(cfg *bedrock.ClientConfig) string {
+	h := sha256.New()
+	if c.countTokens {
+		h.Write([]byte("count-tokens:"))
+	}
+	for _, part := range []string{c.template, cfg.SystemPrompt, cfg.SharedPrefix, cfg.Prefill} {
+		fmt.Fprintf(h, "%d:%s", len(part), part)
+	}
+	tools, _ := json.Marshal(cfg.Tools)
+	h.Write(tools)
+	for _, doc := range cfg.Documents {
+		fmt.Fprintf(h, "%s:%d", doc.Name, len(doc.Data))
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// save writes the cached sizes to the calibration file, if one is configured
+func (c *calibrator) save() error {
+	if c.file == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(c.entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(c.file, data, 0644); err != nil {
+		return fmt.Errorf("failed to write calibration file: %w", err)
+	}
+	return nil
+}
+
+// absInt returns the absolute value of n
+func absInt(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
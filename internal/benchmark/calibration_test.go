@@ -0,0 +1,158 @@
+package benchmark
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"unicode/utf8"
+
+	"bedrock-performance/internal/bedrock"
+)
+
+// tokenCounter is an Invoker that reports overhead tokens plus one token per
+// 3.7 characters of prompt, like a model with a fixed chat template
+type tokenCounter struct {
+	overhead int
+	probes   int
+	err      error
+	noCount  bool // CountTokens reports 0 tokens
+}
+
+func (c *tokenCounter) InvokeNonStreaming(ctx context.Context, prompt string) *bedrock.InvokeResult {
+	c.probes++
+	if c.err != nil {
+		return &bedrock.InvokeResult{Error: c.err}
+	}
+	return &bedrock.InvokeResult{Success: true, InputTokens: c.overhead + utf8.RuneCountInString(prompt)*10/37}
+}
+
+func (c *tokenCounter) InvokeStreaming(ctx context.Context, prompt string) *bedrock.InvokeResult {
+	return c.InvokeNonStreaming(ctx, prompt)
+}
+
+func (c *tokenCounter) InvokeToolFollowUp(ctx context.Context, prompt string, first *bedrock.InvokeResult, streaming bool) *bedrock.InvokeResult {
+	return nil
+}
+
+func (c *tokenCounter) CountTokens(ctx context.Context, prompt string) (int, error) {
+	result := c.InvokeNonStreaming(ctx, prompt)
+	if c.noCount {
+		return 0, result.Error
+	}
+	return result.InputTokens, result.Error
+}
+
+func (c *tokenCounter) factory(cfg *bedrock.ClientConfig) Invoker {
+	return c
+}
+
+func TestCalibrationConvergesAndCaches(t *testing.T) {
+	counter := &tokenCounter{overhead: 120}
+	file := filepath.Join(t.TempDir(), "calibration.json")
+	cfg := &bedrock.ClientConfig{ModelID: "anthropic.claude-3-haiku-20240307-v1:0", SystemPrompt: "Be brief."}
+
+	calibrator, err := newCalibrator(counter.factory, "", 2000, 2, file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, err := calibrator.calibrate(context.Background(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !first.Converged || first.ProbeTokens < 1960 || first.ProbeTokens > 2040 {
+		t.Fatalf("calibrated to %d tokens (converged %v), want 2000 ±2%%", first.ProbeTokens, first.Converged)
+	}
+	if first.Probes != counter.probes || first.Probes > 4 {
+		t.Errorf("Probes = %d, counter saw %d, want at most 4", first.Probes, counter.probes)
+	}
+
+	// Other variants of the same model reuse the size without probing
+	again, err := calibrator.calibrate(context.Background(), cfg)
+	if err != nil || again.Probes != 0 || again.PromptSize != first.PromptSize {
+		t.Fatalf("second calibration = %+v, %v; want the cached size", again, err)
+	}
+	if err := calibrator.save(); err != nil {
+		t.Fatal(err)
+	}
+
+	// A new run loads the cache from the file
+	counter.probes = 0
+	reloaded, err := newCalibrator(counter.factory, "", 2000, 2, file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cached, err := reloaded.calibrate(context.Background(), cfg); err != nil || cached.PromptSize != first.PromptSize || counter.probes != 0 {
+		t.Errorf("calibration from file = %+v after %d probes, %v", cached, counter.probes, err)
+	}
+
+	// A different system prompt changes the overhead, so it is calibrated again
+	cfg.SystemPrompt = "Answer in detail."
+	if _, err := reloaded.calibrate(context.Background(), cfg); err != nil || counter.probes == 0 {
+		t.Errorf("changed system prompt reused the cached size (%d probes, %v)", counter.probes, err)
+	}
+}
+
+func TestCalibrationUnreachableTarget(t *testing.T) {
+	// The fixed overhead alone exceeds the target
+	counter := &tokenCounter{overhead: 500}
+	calibrator, err := newCalibrator(counter.factory, "", 100, 5, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := calibrator.calibrate(context.Background(), &bedrock.ClientConfig{ModelID: "m"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Converged || result.Probes > maxCalibrationProbes {
+		t.Errorf("result = %+v, want an unconverged result within %d probes", result, maxCalibrationProbes)
+	}
+}
+
+func TestCalibrationProbeFailure(t *testing.T) {
+	counter := &tokenCounter{err: errors.New("access denied")}
+	calibrator, err := newCalibrator(counter.factory, "", 100, 5, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := calibrator.calibrate(context.Background(), &bedrock.ClientConfig{ModelID: "m"}); err == nil {
+		t.Fatalf("calibrate succeeded although the probe failed")
+	}
+}
+
+func TestCalibrationProbeMethod(t *testing.T) {
+	cfg := &bedrock.ClientConfig{ModelID: "m"}
+
+	// A CountTokens probe that reports no tokens fails instead of dividing by zero
+	empty, err := newCalibrator((&tokenCounter{noCount: true}).factory, "", 100, 5, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	empty.countTokens = true
+	if _, err := empty.calibrate(context.Background(), cfg); err == nil {
+		t.Errorf("calibrate succeeded although CountTokens reported 0 tokens")
+	}
+
+	// A size found with CountTokens in a dry run is not reused by a real run
+	counter := &tokenCounter{overhead: 20}
+	file := filepath.Join(t.TempDir(), "calibration.json")
+	dryRun, err := newCalibrator(counter.factory, "", 500, 5, file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dryRun.countTokens = true
+	if _, err := dryRun.calibrate(context.Background(), cfg); err != nil {
+		t.Fatal(err)
+	}
+	if err := dryRun.save(); err != nil {
+		t.Fatal(err)
+	}
+	counter.probes = 0
+	run, err := newCalibrator(counter.factory, "", 500, 5, file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := run.calibrate(context.Background(), cfg); err != nil || counter.probes == 0 {
+		t.Errorf("invocation calibration reused the CountTokens size (%d probes, %v)", counter.probes, err)
+	}
+}
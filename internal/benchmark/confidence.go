@@ -0,0 +1,81 @@
+package benchmark
+
+import (
+	"math/rand"
+	"sort"
+
+	"bedrock-performance/internal/types"
+)
+
+// bootstrapIterations is the number of resamples used for confidence intervals
+const bootstrapIterations = 2000
+
+// bootstrapSeed keeps intervals reproducible for the same set of trials
+const bootstrapSeed = 42
+
+// summarizeTrials computes the mean and 95% bootstrap confidence interval of
+// the key metrics across repeated runs of the same concurrency level
+func summarizeTrials(trials []*types.Stats) *types.TrialSummary {
+	summary := &types.TrialSummary{
+		Repetitions: len(trials),
+	}
+	if len(trials) == 0 {
+		return summary
+	}
+
+	rng := rand.New(rand.NewSource(bootstrapSeed))
+	collect := func(value func(s *types.Stats) float64) types.ConfidenceInterval {
+		values := make([]float64, 0, len(trials))
+		for _, s := range trials {
+			values = append(values, value(s))
+		}
+		return bootstrapCI(values, rng)
+	}
+
+	summary.RequestsPerSecond = collect(func(s *types.Stats) float64 { return s.RequestsPerSecond })
+	summary.TokenThroughput = collect(func(s *types.Stats) float64 { return s.TokenThroughput })
+	summary.P50Latency = collect(func(s *types.Stats) float64 { return s.P50Latency })
+	summary.P95Latency = collect(func(s *types.Stats) float64 { return s.P95Latency })
+	summary.P99Latency = collect(func(s *types.Stats) float64 { return s.P99Latency })
+
+	// TTFT is only meaningful if every repetition produced it
+	summary.HasTTFT = true
+	for _, s := range trials {
+		if !s.HasTTFT {
+			summary.HasTTFT = false
+			break
+		}
+	}
+	if summary.HasTTFT {
+		summary.P50TTFT = collect(func(s *types.Stats) float64 { return s.P50TTFT })
+		summary.P95TTFT = collect(func(s *types.Stats) float64 { return s.P95TTFT })
+		summary.P99TTFT = collect(func(s *types.Stats) float64 { return s.P99TTFT })
+	}
+
+	return summary
+}
+
+// bootstrapCI estimates the 95% confidence interval of the mean of values by
+// percentile bootstrap. With a single value the interval collapses to it.
+func bootstrapCI(values []float64, rng *rand.Rand) types.ConfidenceInterval {
+	ci := types.ConfidenceInterval{Mean: average(values)}
+	if len(values) < 2 {
+		ci.Lower = ci.Mean
+		ci.Upper = ci.Mean
+		return ci
+	}
+
+	means := make([]float64, bootstrapIterations)
+	for i := range means {
+		sum := 0.0
+		for range values {
+			sum += values[rng.Intn(len(values))]
+		}
+		means[i] = sum / float64(len(values))
+	}
+	sort.Float64s(means)
+
+	ci.Lower = percentile(means, 2.5)
+	ci.Upper = percentile(means, 97.5)
+	return ci
+}
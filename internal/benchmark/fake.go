@@ -0,0 +1,195 @@
+package benchmark
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"bedrock-performance/internal/bedrock"
+)
+
+// FakeResponse is one scripted outcome of a FakeInvoker call
+type FakeResponse struct {
+	// Latency is the total request time and TTFT the time to first token
+	// (streaming only; 0 uses a tenth of Latency)
+	Latency      time.Duration
+	TTFT         time.Duration
+	InputTokens  int
+	OutputTokens int
+	Content      string
+	StopReason   string
+	// ToolCalls makes the worker run the tool result turn when a tool result is configured
+	ToolCalls []bedrock.ToolCall
+	// Attempts is the number of SDK attempts reported (0 reports one)
+	Attempts int
+	// PerformanceLatency is the latency mode reported as served ("" reports none)
+	PerformanceLatency string
+	// CountedTokens is what CountTokens reports while this response is
+	// next (0 reports InputTokens); CountErr fails CountTokens instead
+	CountedTokens int
+	CountErr      error
+
+	// Err fails the request with the given classification. A streaming
+	// failure after TTFT is recorded as a mid-stream failure.
+	Err        error
+	ErrorType  string
+	ErrorCode  string
+	StatusCode int
+}
+
+// FakeInvoker is an in-memory Invoker that returns scripted responses in
+// order, cycling through them, and records the prompts it was sent. One
+// FakeInvoker is safe to share across workers.
+type FakeInvoker struct {
+	mu        sync.Mutex
+	responses []FakeResponse
+	calls     int
+	prompts   []string
+	streaming int
+	counts    int
+}
+
+// NewFakeInvoker creates a fake that returns responses in turn; with no
+// responses every call succeeds immediately with one output token
+func NewFakeInvoker(responses ...FakeResponse) *FakeInvoker {
+	if len(responses) == 0 {
+		responses = []FakeResponse{{InputTokens: 1, OutputTokens: 1}}
+	}
+	return &FakeInvoker{responses: responses}
+}
+
+// Factory returns an InvokerFactory that hands this fake to every worker
+func (f *FakeInvoker) Factory() InvokerFactory {
+	return func(*bedrock.ClientConfig) Invoker { return f }
+}
+
+// Calls returns the number of requests made so far
+func (f *FakeInvoker) Calls() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls
+}
+
+// StreamingCalls returns the number of streaming requests made so far
+func (f *FakeInvoker) StreamingCalls() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.streaming
+}
+
+// CountCalls returns the number of CountTokens calls made so far
+func (f *FakeInvoker) CountCalls() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.counts
+}
+
+// Prompts returns the prompts sent so far, in call order
+func (f *FakeInvoker) Prompts() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.prompts...)
+}
+
+// InvokeStreaming returns the next scripted response as a streaming result
+func (f *FakeInvoker) InvokeStreaming(ctx context.Context, prompt string) *bedrock.InvokeResult {
+	return f.invoke(ctx, prompt, true)
+}
+
+// InvokeNonStreaming returns the next scripted response
+func (f *FakeInvoker) InvokeNonStreaming(ctx context.Context, prompt string) *bedrock.InvokeResult {
+	return f.invoke(ctx, prompt, false)
+}
+
+// InvokeToolFollowUp returns the next scripted response when first made tool calls
+func (f *FakeInvoker) InvokeToolFollowUp(ctx context.Context, prompt string, first *bedrock.InvokeResult, streaming bool) *bedrock.InvokeResult {
+	if len(first.ToolCalls) == 0 {
+		return nil
+	}
+	return f.invoke(ctx, prompt, streaming)
+}
+
+// CountTokens reports the token count of the response the next call would
+// return; it does not count as a call
+func (f *FakeInvoker) CountTokens(ctx context.Context, prompt string) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.counts++
+	resp := f.responses[f.calls%len(f.responses)]
+	if resp.CountErr != nil {
+		return 0, resp.CountErr
+	}
+	if resp.CountedTokens > 0 {
+		return resp.CountedTokens, nil
+	}
+	return resp.InputTokens, nil
+}
+
+// next returns the scripted response for the next call and records it
+func (f *FakeInvoker) next(prompt string, streaming bool) FakeResponse {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	resp := f.responses[f.calls%len(f.responses)]
+	f.calls++
+	f.prompts = append(f.prompts, prompt)
+	if streaming {
+		f.streaming++
+	}
+	return resp
+}
+
+// invoke waits out the scripted latency and builds the result
+func (f *FakeInvoker) invoke(ctx context.Context, prompt string, streaming bool) *bedrock.InvokeResult {
+	resp := f.next(prompt, streaming)
+	result := &bedrock.InvokeResult{StartTime: time.Now(), Attempts: max(resp.Attempts, 1)}
+
+	ttft := resp.TTFT
+	if ttft == 0 {
+		ttft = resp.Latency / 10
+	}
+
+	select {
+	case <-time.After(resp.Latency):
+	case <-ctx.Done():
+		result.EndTime = time.Now()
+		result.Error = ctx.Err()
+		result.ErrorType = "CanceledError"
+		return result
+	}
+	result.EndTime = result.StartTime.Add(resp.Latency)
+
+	if streaming {
+		result.TTFT = ttft
+	}
+
+	if resp.Err != nil {
+		result.Error = resp.Err
+		result.ErrorType = resp.ErrorType
+		result.ErrorCode = resp.ErrorCode
+		result.HTTPStatusCode = resp.StatusCode
+		if streaming {
+			result.FailedAfter = resp.Latency
+			result.FailurePhase = bedrock.FailurePhaseRequest
+			if resp.TTFT > 0 && resp.TTFT < resp.Latency {
+				result.FailurePhase = bedrock.FailurePhaseMidStream
+				result.PartialOutputTokens = resp.OutputTokens
+			} else {
+				result.TTFT = 0
+			}
+		}
+		return result
+	}
+
+	result.Success = true
+	result.HTTPStatusCode = http.StatusOK
+	result.InputTokens = resp.InputTokens
+	result.OutputTokens = resp.OutputTokens
+	result.ResponseContent = resp.Content
+	result.StopReason = resp.StopReason
+	result.ToolCalls = resp.ToolCalls
+	result.PerformanceLatency = resp.PerformanceLatency
+	return result
+}
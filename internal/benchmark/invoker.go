@@ -0,0 +1,30 @@
+package benchmark
+
+import (
+	"context"
+
+	"bedrock-performance/internal/bedrock"
+)
+
+// Invoker sends benchmark requests. *bedrock.Client is the production
+// implementation; FakeInvoker and decorators wrapping another Invoker can be
+// injected through an InvokerFactory.
+type Invoker interface {
+	InvokeStreaming(ctx context.Context, prompt string) *bedrock.InvokeResult
+	InvokeNonStreaming(ctx context.Context, prompt string) *bedrock.InvokeResult
+	// InvokeToolFollowUp sends the tool result turn for the tool calls in
+	// first, returning nil if there were none
+	InvokeToolFollowUp(ctx context.Context, prompt string, first *bedrock.InvokeResult, streaming bool) *bedrock.InvokeResult
+	// CountTokens returns the input tokens of the request for prompt without
+	// running the model
+	CountTokens(ctx context.Context, prompt string) (int, error)
+}
+
+// InvokerFactory creates the Invoker used by one worker for a variant's client settings
+type InvokerFactory func(cfg *bedrock.ClientConfig) Invoker
+
+// NewBedrockInvoker is the default InvokerFactory; it creates a dedicated
+// Bedrock client so workers do not contend for one connection pool
+func NewBedrockInvoker(cfg *bedrock.ClientConfig) Invoker {
+	return bedrock.NewClientFromConfig(cfg)
+}
@@ -15,33 +15,105 @@ type Metrics struct {
 	mu sync.Mutex
 
 	// Raw data
-	results       []*bedrock.InvokeResult
-	startTime     time.Time
-	endTime       time.Time
+	results   []*bedrock.InvokeResult
+	startTime time.Time
+	endTime   time.Time
+
+	// mergedDuration is the summed test time of merged collectors
+	mergedDuration time.Duration
 
 	// Counters
-	totalRequests   int
-	successCount    int
-	failureCount    int
+	totalRequests     int
+	successCount      int
+	failureCount      int
 	totalInputTokens  int
 	totalOutputTokens int
 
+	// Reasoning
+	totalThinkingTokens int
+	firstThinking       []float64 // ms, streaming only
+	firstAnswer         []float64 // ms, streaming only
+	answerTPS           []float64 // per-request answer tokens/sec
+
+	// Prompt caching
+	totalCacheRead  int
+	totalCacheWrite int
+	cacheHits       int
+	cacheWrites     int
+
+	// Tool use
+	toolCallRequests  int
+	totalToolCalls    int
+	invalidToolInputs int
+	firstToolUse      []float64 // ms, streaming only
+	toolInputComplete []float64 // ms, streaming only
+	followUps         int
+	followUpFailures  int
+	followUpLatencies []float64
+	followUpTTFTs     []float64
+
+	// Retries, counted from the SDK attempts of each request
+	totalAttempts    int
+	retriedRequests  int
+	retriedSuccesses int
+
+	// Latency mode Bedrock reported serving successful requests with
+	latencyReported int
+	optimizedServed int
+
+	// Content validation
+	validated        int
+	qualityFailures  map[string]int    // by failed check
+	qualitySamples   map[string]string // first failure detail per check
+	validInputTokens int
+	validOutTokens   int
+
 	// Error tracking
-	errorsByType map[string]int
+	errorsByType    map[string]int
+	errorsByStatus  map[int]int
+	errorRequestIDs map[string]string // first AWS request ID seen per error type
+
+	// Streaming failure tracking
+	failuresByPhase     map[bedrock.FailurePhase]int
+	midStreamFailures   []float64 // ms from request start to failure
+	partialOutputTokens int
 
 	// Latency data (in milliseconds)
-	latencies []float64
-	ttfts     []float64 // Only for streaming
+	latencies       []float64
+	ttfts           []float64 // Only for streaming
+	serverLatencies []float64 // Only when Bedrock reports it
+	overheads       []float64 // Total minus server latency, only when Bedrock reports it
+	serverFirstByte []float64 // Only for streaming, when Bedrock reports it
+	tpots           []float64 // Only for streaming
+	itls            []float64 // Only for streaming; every inter-chunk gap
+	outputTPS       []float64 // Per-request output tokens/sec
+
+	// Latency split by prompt cache outcome (in milliseconds)
+	hitLatencies  []float64
+	missLatencies []float64
+	hitTTFTs      []float64
+	missTTFTs     []float64
 }
 
 // NewMetrics creates a new Metrics collector
 func NewMetrics() *Metrics {
 	return &Metrics{
-		results:      make([]*bedrock.InvokeResult, 0),
-		errorsByType: make(map[string]int),
-		latencies:    make([]float64, 0),
-		ttfts:        make([]float64, 0),
-		startTime:    time.Now(),
+		results:         make([]*bedrock.InvokeResult, 0),
+		qualityFailures: make(map[string]int),
+		qualitySamples:  make(map[string]string),
+		errorsByType:    make(map[string]int),
+		errorsByStatus:  make(map[int]int),
+		errorRequestIDs: make(map[string]string),
+		failuresByPhase: make(map[bedrock.FailurePhase]int),
+		latencies:       make([]float64, 0),
+		ttfts:           make([]float64, 0),
+		serverLatencies: make([]float64, 0),
+		overheads:       make([]float64, 0),
+		serverFirstByte: make([]float64, 0),
+		tpots:           make([]float64, 0),
+		itls:            make([]float64, 0),
+		outputTPS:       make([]float64, 0),
+		startTime:       time.Now(),
 	}
 }
 
@@ -50,9 +122,35 @@ func (m *Metrics) AddResult(result *bedrock.InvokeResult) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	m.addResultLocked(result)
+}
+
+// addResultLocked records a result; the caller must hold m.mu
+func (m *Metrics) addResultLocked(result *bedrock.InvokeResult) {
 	m.results = append(m.results, result)
 	m.totalRequests++
 
+	if result.Validated {
+		m.validated++
+		if result.QualityFailure != "" {
+			m.qualityFailures[result.QualityFailure]++
+			if _, ok := m.qualitySamples[result.QualityFailure]; !ok {
+				m.qualitySamples[result.QualityFailure] = result.QualityDetail
+			}
+		} else {
+			m.validInputTokens += result.InputTokens
+			m.validOutTokens += result.OutputTokens
+		}
+	}
+
+	m.totalAttempts += result.Attempts
+	if result.Attempts > 1 {
+		m.retriedRequests++
+		if result.Success {
+			m.retriedSuccesses++
+		}
+	}
+
 	if result.Success {
 		m.successCount++
 		m.totalInputTokens += result.InputTokens
@@ -63,16 +161,112 @@ func (m *Metrics) AddResult(result *bedrock.InvokeResult) {
 		m.latencies = append(m.latencies, latencyMs)
 
 		// Record TTFT if available (streaming only)
+		ttftMs := float64(result.TTFT.Microseconds()) / 1000.0
 		if result.TTFT > 0 {
-			ttftMs := float64(result.TTFT.Microseconds()) / 1000.0
 			m.ttfts = append(m.ttfts, ttftMs)
 		}
+
+		// Record reasoning split (thinking tokens are included in OutputTokens)
+		m.totalThinkingTokens += result.ThinkingTokens
+		if result.TimeToFirstThinking > 0 {
+			m.firstThinking = append(m.firstThinking, float64(result.TimeToFirstThinking.Microseconds())/1000.0)
+		}
+		if result.TimeToFirstAnswer > 0 {
+			m.firstAnswer = append(m.firstAnswer, float64(result.TimeToFirstAnswer.Microseconds())/1000.0)
+		}
+		if seconds := result.Duration().Seconds(); seconds > 0 {
+			m.answerTPS = append(m.answerTPS, float64(result.AnswerTokens())/seconds)
+		}
+
+		// Record the latency mode the request was served with, if reported
+		if result.PerformanceLatency != "" {
+			m.latencyReported++
+			if result.PerformanceLatency == string(bedrock.PerformanceLatencyOptimized) {
+				m.optimizedServed++
+			}
+		}
+
+		// Record prompt cache usage and split latency by hit/miss
+		m.totalCacheRead += result.CacheReadInputTokens
+		m.totalCacheWrite += result.CacheWriteInputTokens
+		if result.CacheHit() {
+			m.cacheHits++
+			m.hitLatencies = append(m.hitLatencies, latencyMs)
+			if result.TTFT > 0 {
+				m.hitTTFTs = append(m.hitTTFTs, ttftMs)
+			}
+		} else {
+			if result.CacheWriteInputTokens > 0 {
+				m.cacheWrites++
+			}
+			m.missLatencies = append(m.missLatencies, latencyMs)
+			if result.TTFT > 0 {
+				m.missTTFTs = append(m.missTTFTs, ttftMs)
+			}
+		}
+
+		// Record tool calls and the follow-up turn, if one was run
+		if len(result.ToolCalls) > 0 {
+			m.toolCallRequests++
+		}
+		m.totalToolCalls += len(result.ToolCalls)
+		m.invalidToolInputs += result.InvalidToolInputs
+		if result.TimeToFirstToolUse > 0 {
+			m.firstToolUse = append(m.firstToolUse, float64(result.TimeToFirstToolUse.Microseconds())/1000.0)
+		}
+		if result.TimeToToolInputComplete > 0 {
+			m.toolInputComplete = append(m.toolInputComplete, float64(result.TimeToToolInputComplete.Microseconds())/1000.0)
+		}
+		if followUp := result.FollowUp; followUp != nil {
+			m.followUps++
+			if followUp.Success {
+				m.followUpLatencies = append(m.followUpLatencies, float64(followUp.Duration().Microseconds())/1000.0)
+				if followUp.TTFT > 0 {
+					m.followUpTTFTs = append(m.followUpTTFTs, float64(followUp.TTFT.Microseconds())/1000.0)
+				}
+			} else {
+				m.followUpFailures++
+			}
+		}
+
+		// Record server-side latency if Bedrock reported it
+		if result.ServerLatency > 0 {
+			serverMs := float64(result.ServerLatency.Microseconds()) / 1000.0
+			m.serverLatencies = append(m.serverLatencies, serverMs)
+			m.overheads = append(m.overheads, float64(result.ClientOverhead().Microseconds())/1000.0)
+		}
+		if result.ServerFirstByteLatency > 0 {
+			m.serverFirstByte = append(m.serverFirstByte, float64(result.ServerFirstByteLatency.Microseconds())/1000.0)
+		}
+
+		// Record decode speed (streaming only)
+		if tpot := result.TPOT(); tpot > 0 {
+			m.tpots = append(m.tpots, float64(tpot.Microseconds())/1000.0)
+		}
+		for _, gap := range result.InterChunkGaps {
+			m.itls = append(m.itls, float64(gap.Microseconds())/1000.0)
+		}
+		if seconds := result.Duration().Seconds(); seconds > 0 && result.OutputTokens > 0 {
+			m.outputTPS = append(m.outputTPS, float64(result.OutputTokens)/seconds)
+		}
 	} else {
 		m.failureCount++
-		if result.ErrorType != "" {
-			m.errorsByType[result.ErrorType]++
-		} else {
-			m.errorsByType["UnknownError"]++
+		errorType := result.ErrorType
+		if errorType == "" {
+			errorType = "UnknownError"
+		}
+		m.errorsByType[errorType]++
+		m.errorsByStatus[result.HTTPStatusCode]++
+		if _, ok := m.errorRequestIDs[errorType]; !ok && result.RequestID != "" {
+			m.errorRequestIDs[errorType] = result.RequestID
+		}
+
+		if result.FailurePhase != "" {
+			m.failuresByPhase[result.FailurePhase]++
+		}
+		if result.FailurePhase == bedrock.FailurePhaseMidStream {
+			m.midStreamFailures = append(m.midStreamFailures, float64(result.FailedAfter.Microseconds())/1000.0)
+			m.partialOutputTokens += result.PartialOutputTokens
 		}
 	}
 }
@@ -84,44 +278,164 @@ func (m *Metrics) Finalize() {
 	m.endTime = time.Now()
 }
 
+// Merge folds the results of another finished collector into m.
+// Test durations are summed so throughput remains a rate over the time
+// actually spent running requests rather than the wall clock between runs.
+func (m *Metrics) Merge(other *Metrics) {
+	other.mu.Lock()
+	results := make([]*bedrock.InvokeResult, len(other.results))
+	copy(results, other.results)
+	duration := other.durationLocked()
+	other.mu.Unlock()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, result := range results {
+		m.addResultLocked(result)
+	}
+	m.mergedDuration += duration
+}
+
+// durationLocked returns the collection duration; the caller must hold m.mu
+func (m *Metrics) durationLocked() time.Duration {
+	if m.mergedDuration > 0 {
+		return m.mergedDuration
+	}
+	if m.endTime.IsZero() {
+		return time.Since(m.startTime)
+	}
+	return m.endTime.Sub(m.startTime)
+}
+
 // ComputeStats computes statistics from collected metrics
 func (m *Metrics) ComputeStats() *types.Stats {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
 	stats := &types.Stats{
-		TotalRequests:     m.totalRequests,
-		SuccessCount:      m.successCount,
-		FailureCount:      m.failureCount,
-		TotalInputTokens:  m.totalInputTokens,
-		TotalOutputTokens: m.totalOutputTokens,
-		TotalTokens:       m.totalInputTokens + m.totalOutputTokens,
-		ErrorsByType:      make(map[string]int),
+		TotalRequests:         m.totalRequests,
+		SuccessCount:          m.successCount,
+		FailureCount:          m.failureCount,
+		TotalInputTokens:      m.totalInputTokens,
+		TotalOutputTokens:     m.totalOutputTokens,
+		TotalTokens:           m.totalInputTokens + m.totalOutputTokens,
+		TotalCacheReadTokens:  m.totalCacheRead,
+		TotalCacheWriteTokens: m.totalCacheWrite,
+		CacheHits:             m.cacheHits,
+		CacheWrites:           m.cacheWrites,
+		ErrorsByType:          make(map[string]int),
+		ErrorsByStatus:        make(map[int]int),
+		ErrorRequestIDs:       make(map[string]string),
 	}
 
 	// Copy error map
 	for k, v := range m.errorsByType {
 		stats.ErrorsByType[k] = v
 	}
+	for k, v := range m.errorsByStatus {
+		stats.ErrorsByStatus[k] = v
+	}
+	for k, v := range m.errorRequestIDs {
+		stats.ErrorRequestIDs[k] = v
+	}
+
+	// Streaming failure phases
+	stats.FailedAtRequest = m.failuresByPhase[bedrock.FailurePhaseRequest]
+	stats.FailedBeforeFirstToken = m.failuresByPhase[bedrock.FailurePhaseBeforeFirstToken]
+	stats.FailedMidStream = m.failuresByPhase[bedrock.FailurePhaseMidStream]
+	stats.AvgMidStreamFailureAfter = average(m.midStreamFailures)
+	stats.PartialOutputTokens = m.partialOutputTokens
 
 	// Calculate success rate
 	if m.totalRequests > 0 {
 		stats.SuccessRate = float64(m.successCount) / float64(m.totalRequests) * 100.0
+		stats.AvgAttempts = float64(m.totalAttempts) / float64(m.totalRequests)
+	}
+	stats.RetriedRequests = m.retriedRequests
+
+	// Quality failures and goodput (only when responses were validated)
+	if m.validated > 0 {
+		stats.HasValidation = true
+		stats.QualityFailuresByCheck = make(map[string]int)
+		stats.QualityFailureSamples = make(map[string]string)
+		for check, count := range m.qualityFailures {
+			stats.QualityFailuresByCheck[check] = count
+			stats.QualityFailures += count
+		}
+		for check, detail := range m.qualitySamples {
+			stats.QualityFailureSamples[check] = detail
+		}
+		stats.ValidCount = m.validated - stats.QualityFailures
+		stats.ValidRate = float64(stats.ValidCount) / float64(m.totalRequests) * 100.0
+	}
+	stats.RetriedSuccesses = m.retriedSuccesses
+	stats.LatencyModeReported = m.latencyReported
+	stats.OptimizedServed = m.optimizedServed
+
+	// Calculate reasoning statistics if any thinking was seen
+	if m.totalThinkingTokens > 0 || len(m.firstThinking) > 0 {
+		stats.HasThinking = true
+		stats.TotalThinkingTokens = m.totalThinkingTokens
+		if m.successCount > 0 {
+			stats.AvgThinkingTokens = float64(m.totalThinkingTokens) / float64(m.successCount)
+			stats.AvgAnswerTokens = float64(m.totalOutputTokens-m.totalThinkingTokens) / float64(m.successCount)
+		}
+		stats.P50TimeToFirstThinking = percentile(sortedCopy(m.firstThinking), 50)
+		firstAnswer := sortedCopy(m.firstAnswer)
+		stats.P50TimeToFirstAnswer = percentile(firstAnswer, 50)
+		stats.P95TimeToFirstAnswer = percentile(firstAnswer, 95)
+		stats.AnswerTokensPerSecPerQuery = average(m.answerTPS)
 	}
 
-	// Calculate duration
-	if m.endTime.IsZero() {
-		stats.Duration = time.Since(m.startTime)
-	} else {
-		stats.Duration = m.endTime.Sub(m.startTime)
+	// Calculate tool use statistics if any tool was called
+	if m.totalToolCalls > 0 || m.followUps > 0 {
+		stats.HasToolUse = true
+		stats.ToolCallRequests = m.toolCallRequests
+		stats.TotalToolCalls = m.totalToolCalls
+		stats.InvalidToolInputs = m.invalidToolInputs
+		if m.successCount > 0 {
+			stats.ToolCallRate = float64(m.toolCallRequests) / float64(m.successCount) * 100.0
+		}
+		firstToolUse := sortedCopy(m.firstToolUse)
+		stats.P50TimeToFirstToolUse = percentile(firstToolUse, 50)
+		stats.P95TimeToFirstToolUse = percentile(firstToolUse, 95)
+		inputComplete := sortedCopy(m.toolInputComplete)
+		stats.P50TimeToToolInputComplete = percentile(inputComplete, 50)
+		stats.P95TimeToToolInputComplete = percentile(inputComplete, 95)
+		stats.FollowUps = m.followUps
+		stats.FollowUpFailures = m.followUpFailures
+		followUpLatencies := sortedCopy(m.followUpLatencies)
+		stats.P50FollowUpLatency = percentile(followUpLatencies, 50)
+		stats.P95FollowUpLatency = percentile(followUpLatencies, 95)
+		stats.P50FollowUpTTFT = percentile(sortedCopy(m.followUpTTFTs), 50)
 	}
 
+	// Calculate prompt cache hit ratio and hit/miss latency
+	if m.successCount > 0 {
+		stats.CacheHitRatio = float64(m.cacheHits) / float64(m.successCount) * 100.0
+	}
+	stats.P50LatencyCacheHit = percentile(sortedCopy(m.hitLatencies), 50)
+	stats.P50LatencyCacheMiss = percentile(sortedCopy(m.missLatencies), 50)
+	if len(m.hitTTFTs) > 0 || len(m.missTTFTs) > 0 {
+		stats.HasCacheTTFT = true
+		stats.P50TTFTCacheHit = percentile(sortedCopy(m.hitTTFTs), 50)
+		stats.P50TTFTCacheMiss = percentile(sortedCopy(m.missTTFTs), 50)
+	}
+
+	// Calculate duration
+	stats.Duration = m.durationLocked()
+
 	durationSeconds := stats.Duration.Seconds()
 
 	// Calculate throughput
 	if durationSeconds > 0 {
 		stats.RequestsPerSecond = float64(m.successCount) / durationSeconds
 		stats.TokenThroughput = float64(stats.TotalTokens) / durationSeconds
+		if stats.HasValidation {
+			stats.GoodputRequestsPerSecond = float64(stats.ValidCount) / durationSeconds
+			stats.GoodputTokenThroughput = float64(m.validInputTokens+m.validOutTokens) / durationSeconds
+		}
 	}
 
 	// Calculate latency statistics
@@ -153,6 +467,58 @@ func (m *Metrics) ComputeStats() *types.Stats {
 		stats.P99TTFT = percentile(sortedTTFTs, 99)
 	}
 
+	// Calculate server-side latency statistics if available
+	if len(m.serverLatencies) > 0 {
+		stats.HasServerLatency = true
+		sortedServer := make([]float64, len(m.serverLatencies))
+		copy(sortedServer, m.serverLatencies)
+		sort.Float64s(sortedServer)
+
+		stats.AvgServerLatency = average(sortedServer)
+		stats.P50ServerLatency = percentile(sortedServer, 50)
+		stats.P95ServerLatency = percentile(sortedServer, 95)
+
+		sortedOverheads := sortedCopy(m.overheads)
+		stats.AvgClientOverhead = average(sortedOverheads)
+		stats.P50ClientOverhead = percentile(sortedOverheads, 50)
+		stats.P95ClientOverhead = percentile(sortedOverheads, 95)
+	}
+	if len(m.serverFirstByte) > 0 {
+		stats.HasServerFirstByte = true
+		stats.AvgServerFirstByte = average(m.serverFirstByte)
+	}
+
+	// Calculate TPOT statistics if available
+	if len(m.tpots) > 0 {
+		stats.HasTPOT = true
+		sortedTPOTs := sortedCopy(m.tpots)
+
+		stats.AvgTPOT = average(sortedTPOTs)
+		stats.P50TPOT = percentile(sortedTPOTs, 50)
+		stats.P95TPOT = percentile(sortedTPOTs, 95)
+		stats.P99TPOT = percentile(sortedTPOTs, 99)
+	}
+
+	// Calculate inter-token latency statistics if available
+	if len(m.itls) > 0 {
+		stats.HasITL = true
+		sortedITLs := sortedCopy(m.itls)
+
+		stats.P50ITL = percentile(sortedITLs, 50)
+		stats.P95ITL = percentile(sortedITLs, 95)
+		stats.P99ITL = percentile(sortedITLs, 99)
+		stats.MaxITL = sortedITLs[len(sortedITLs)-1]
+	}
+
+	// Calculate per-request output token rate distribution
+	if len(m.outputTPS) > 0 {
+		sortedTPS := sortedCopy(m.outputTPS)
+
+		stats.AvgOutputTokensPerSec = average(sortedTPS)
+		stats.P10OutputTokensPerSec = percentile(sortedTPS, 10)
+		stats.P50OutputTokensPerSec = percentile(sortedTPS, 50)
+	}
+
 	return stats
 }
 
@@ -172,11 +538,62 @@ func (m *Metrics) Reset() {
 	m.failureCount = 0
 	m.totalInputTokens = 0
 	m.totalOutputTokens = 0
+	m.totalThinkingTokens = 0
+	m.firstThinking = nil
+	m.firstAnswer = nil
+	m.answerTPS = nil
+	m.totalCacheRead = 0
+	m.totalCacheWrite = 0
+	m.cacheHits = 0
+	m.cacheWrites = 0
+	m.hitLatencies = nil
+	m.missLatencies = nil
+	m.hitTTFTs = nil
+	m.missTTFTs = nil
+	m.toolCallRequests = 0
+	m.totalToolCalls = 0
+	m.invalidToolInputs = 0
+	m.firstToolUse = nil
+	m.toolInputComplete = nil
+	m.followUps = 0
+	m.followUpFailures = 0
+	m.followUpLatencies = nil
+	m.followUpTTFTs = nil
+	m.validated = 0
+	m.qualityFailures = make(map[string]int)
+	m.qualitySamples = make(map[string]string)
+	m.validInputTokens = 0
+	m.validOutTokens = 0
+	m.totalAttempts = 0
+	m.retriedRequests = 0
+	m.retriedSuccesses = 0
+	m.latencyReported = 0
+	m.optimizedServed = 0
 	m.errorsByType = make(map[string]int)
+	m.errorsByStatus = make(map[int]int)
+	m.errorRequestIDs = make(map[string]string)
+	m.failuresByPhase = make(map[bedrock.FailurePhase]int)
+	m.midStreamFailures = nil
+	m.partialOutputTokens = 0
 	m.latencies = make([]float64, 0)
 	m.ttfts = make([]float64, 0)
+	m.serverLatencies = make([]float64, 0)
+	m.overheads = make([]float64, 0)
+	m.serverFirstByte = make([]float64, 0)
+	m.tpots = make([]float64, 0)
+	m.itls = make([]float64, 0)
+	m.outputTPS = make([]float64, 0)
 	m.startTime = time.Now()
 	m.endTime = time.Time{}
+	m.mergedDuration = 0
+}
+
+// sortedCopy returns a sorted copy of values
+func sortedCopy(values []float64) []float64 {
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+	return sorted
 }
 
 // average calculates the average of a slice of float64
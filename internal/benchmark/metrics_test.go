@@ -0,0 +1,185 @@
+package benchmark
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"bedrock-performance/internal/bedrock"
+)
+
+func TestPercentile(t *testing.T) {
+	values := []float64{10, 20, 30, 40, 50}
+	tests := []struct {
+		p    float64
+		want float64
+	}{
+		{0, 10},
+		{50, 30},
+		{100, 50},
+		{25, 20},
+		{90, 46}, // interpolated between 40 and 50
+		{-5, 10},
+		{150, 50},
+	}
+	for _, tt := range tests {
+		if got := percentile(values, tt.p); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("percentile(%v, %v) = %v, want %v", values, tt.p, got, tt.want)
+		}
+	}
+
+	if got := percentile(nil, 50); got != 0 {
+		t.Errorf("percentile(nil, 50) = %v, want 0", got)
+	}
+	if got := percentile([]float64{7}, 99); got != 7 {
+		t.Errorf("percentile([7], 99) = %v, want 7", got)
+	}
+}
+
+func TestAverageAndSortedCopy(t *testing.T) {
+	if got := average(nil); got != 0 {
+		t.Errorf("average(nil) = %v, want 0", got)
+	}
+	if got := average([]float64{1, 2, 6}); got != 3 {
+		t.Errorf("average([1 2 6]) = %v, want 3", got)
+	}
+
+	values := []float64{3, 1, 2}
+	sorted := sortedCopy(values)
+	if sorted[0] != 1 || sorted[2] != 3 {
+		t.Errorf("sortedCopy(%v) = %v, want ascending", values, sorted)
+	}
+	if values[0] != 3 {
+		t.Errorf("sortedCopy modified its input: %v", values)
+	}
+}
+
+// successResult returns a successful streaming result
+func successResult(latency, ttft time.Duration, inputTokens, outputTokens int) *bedrock.InvokeResult {
+	start := time.Now()
+	return &bedrock.InvokeResult{
+		Success:      true,
+		StartTime:    start,
+		EndTime:      start.Add(latency),
+		TTFT:         ttft,
+		InputTokens:  inputTokens,
+		OutputTokens: outputTokens,
+	}
+}
+
+func TestMetricsComputeStats(t *testing.T) {
+	m := NewMetrics()
+	m.AddResult(successResult(100*time.Millisecond, 10*time.Millisecond, 50, 11))
+	m.AddResult(successResult(200*time.Millisecond, 20*time.Millisecond, 50, 21))
+	m.AddResult(successResult(300*time.Millisecond, 30*time.Millisecond, 50, 31))
+	m.AddResult(&bedrock.InvokeResult{
+		Error:          errors.New("throttled"),
+		ErrorType:      "ThrottlingError",
+		HTTPStatusCode: 429,
+		RequestID:      "req-1",
+	})
+	m.Finalize()
+
+	stats := m.ComputeStats()
+	if stats.TotalRequests != 4 || stats.SuccessCount != 3 || stats.FailureCount != 1 {
+		t.Fatalf("counts = %d/%d/%d, want 4/3/1", stats.TotalRequests, stats.SuccessCount, stats.FailureCount)
+	}
+	if stats.SuccessRate != 75 {
+		t.Errorf("SuccessRate = %v, want 75", stats.SuccessRate)
+	}
+	if stats.TotalInputTokens != 150 || stats.TotalOutputTokens != 63 || stats.TotalTokens != 213 {
+		t.Errorf("tokens = %d/%d/%d, want 150/63/213", stats.TotalInputTokens, stats.TotalOutputTokens, stats.TotalTokens)
+	}
+	if stats.MinLatency != 100 || stats.MaxLatency != 300 || stats.P50Latency != 200 || stats.AvgLatency != 200 {
+		t.Errorf("latency min/max/p50/avg = %v/%v/%v/%v, want 100/300/200/200",
+			stats.MinLatency, stats.MaxLatency, stats.P50Latency, stats.AvgLatency)
+	}
+	if !stats.HasTTFT || stats.P50TTFT != 20 {
+		t.Errorf("HasTTFT = %v, P50TTFT = %v, want true, 20", stats.HasTTFT, stats.P50TTFT)
+	}
+	// (duration - TTFT) / (output tokens - 1) is 9ms for every request
+	if !stats.HasTPOT || math.Abs(stats.P50TPOT-9) > 1e-9 {
+		t.Errorf("HasTPOT = %v, P50TPOT = %v, want true, 9", stats.HasTPOT, stats.P50TPOT)
+	}
+	if stats.ErrorsByType["ThrottlingError"] != 1 || stats.ErrorsByStatus[429] != 1 {
+		t.Errorf("errors = %v %v, want one ThrottlingError with status 429", stats.ErrorsByType, stats.ErrorsByStatus)
+	}
+	if stats.ErrorRequestIDs["ThrottlingError"] != "req-1" {
+		t.Errorf("ErrorRequestIDs = %v, want req-1 for ThrottlingError", stats.ErrorRequestIDs)
+	}
+}
+
+func TestMetricsStreamFailurePhases(t *testing.T) {
+	m := NewMetrics()
+	m.AddResult(&bedrock.InvokeResult{ErrorType: "ModelStreamError", FailurePhase: bedrock.FailurePhaseMidStream,
+		FailedAfter: 40 * time.Millisecond, PartialOutputTokens: 12})
+	m.AddResult(&bedrock.InvokeResult{ErrorType: "ModelStreamError", FailurePhase: bedrock.FailurePhaseBeforeFirstToken})
+	m.AddResult(&bedrock.InvokeResult{FailurePhase: bedrock.FailurePhaseRequest})
+
+	stats := m.ComputeStats()
+	if stats.FailedMidStream != 1 || stats.FailedBeforeFirstToken != 1 || stats.FailedAtRequest != 1 {
+		t.Errorf("phases = %d/%d/%d, want 1/1/1", stats.FailedMidStream, stats.FailedBeforeFirstToken, stats.FailedAtRequest)
+	}
+	if stats.AvgMidStreamFailureAfter != 40 || stats.PartialOutputTokens != 12 {
+		t.Errorf("mid-stream after/partial = %v/%d, want 40/12", stats.AvgMidStreamFailureAfter, stats.PartialOutputTokens)
+	}
+	if stats.ErrorsByType["UnknownError"] != 1 {
+		t.Errorf("ErrorsByType = %v, want an UnknownError for the unclassified failure", stats.ErrorsByType)
+	}
+}
+
+func TestMetricsRetries(t *testing.T) {
+	m := NewMetrics()
+	recovered := successResult(100*time.Millisecond, 0, 10, 10)
+	recovered.Attempts = 3
+	m.AddResult(recovered)
+	first := successResult(100*time.Millisecond, 0, 10, 10)
+	first.Attempts = 1
+	m.AddResult(first)
+	m.AddResult(&bedrock.InvokeResult{ErrorType: "ThrottlingError", Attempts: 2})
+
+	stats := m.ComputeStats()
+	if !stats.HasRetries() || stats.RetriedRequests != 2 || stats.RetriedSuccesses != 1 {
+		t.Errorf("retried/recovered = %d/%d, want 2/1", stats.RetriedRequests, stats.RetriedSuccesses)
+	}
+	if stats.AvgAttempts != 2 {
+		t.Errorf("AvgAttempts = %v, want 2", stats.AvgAttempts)
+	}
+}
+
+func TestMetricsMergeSumsDurations(t *testing.T) {
+	a := NewMetrics()
+	a.AddResult(successResult(100*time.Millisecond, 0, 10, 10))
+	a.Finalize()
+	b := NewMetrics()
+	b.AddResult(successResult(300*time.Millisecond, 0, 10, 10))
+	b.Finalize()
+
+	pooled := NewMetrics()
+	pooled.Merge(a)
+	pooled.Merge(b)
+
+	stats := pooled.ComputeStats()
+	if stats.TotalRequests != 2 || stats.P50Latency != 200 {
+		t.Errorf("merged requests/p50 = %d/%v, want 2/200", stats.TotalRequests, stats.P50Latency)
+	}
+	if want := a.ComputeStats().Duration + b.ComputeStats().Duration; stats.Duration != want {
+		t.Errorf("merged Duration = %v, want %v", stats.Duration, want)
+	}
+	if stats.HasTTFT {
+		t.Errorf("HasTTFT = true for non-streaming results")
+	}
+}
+
+func TestMetricsReset(t *testing.T) {
+	m := NewMetrics()
+	m.AddResult(successResult(100*time.Millisecond, 10*time.Millisecond, 10, 10))
+	m.AddResult(&bedrock.InvokeResult{ErrorType: "ThrottlingError"})
+	m.Reset()
+
+	stats := m.ComputeStats()
+	if stats.TotalRequests != 0 || stats.HasTTFT || len(stats.ErrorsByType) != 0 {
+		t.Errorf("after Reset: requests=%d HasTTFT=%v errors=%v, want empty", stats.TotalRequests, stats.HasTTFT, stats.ErrorsByType)
+	}
+}
@@ -3,12 +3,19 @@ package benchmark
 import (
 	"context"
 	"fmt"
+	"math"
+	"math/rand"
+	"net/http"
 	"time"
+	"unicode/utf8"
 
 	"bedrock-performance/internal/bedrock"
 	"bedrock-performance/internal/config"
+	"bedrock-performance/internal/faults"
+	"bedrock-performance/internal/recording"
 	"bedrock-performance/internal/report"
 	"bedrock-performance/internal/types"
+	"bedrock-performance/internal/validation"
 )
 
 // Runner orchestrates the benchmark test
@@ -16,18 +23,44 @@ type Runner struct {
 	config       *config.Config
 	clientConfig *bedrock.ClientConfig
 	console      *report.ConsoleReporter
+	rng          *rand.Rand
+	attachments  *attachments
+	newInvoker   InvokerFactory
+	faults       *faults.Injector
+	validator    *validation.Validator
 }
 
-// NewRunner creates a new benchmark runner
+// NewRunner creates a new benchmark runner that sends requests to Bedrock
 func NewRunner(cfg *config.Config) *Runner {
+	return NewRunnerWithInvoker(cfg, NewBedrockInvoker)
+}
+
+// NewRunnerWithInvoker creates a benchmark runner whose workers send requests
+// through invokers created by newInvoker
+func NewRunnerWithInvoker(cfg *config.Config, newInvoker InvokerFactory) *Runner {
 	clientConfig := &bedrock.ClientConfig{
-		Region:      cfg.AWS.Region,
-		AccessKey:   cfg.AWS.AccessKeyID,
-		SecretKey:   cfg.AWS.SecretAccessKey,
-		ModelID:     cfg.Model.ID,
-		MaxTokens:   cfg.Test.MaxTokens,
-		Temperature: cfg.Test.Temperature,
-		ServiceTier: bedrock.ServiceTier(cfg.Test.ServiceTier),
+		Region:             cfg.AWS.Region,
+		AccessKey:          cfg.AWS.AccessKeyID,
+		SecretKey:          cfg.AWS.SecretAccessKey,
+		EndpointURL:        cfg.AWS.EndpointURL,
+		UseFIPS:            cfg.AWS.UseFIPS,
+		UseDualStack:       cfg.AWS.UseDualStack,
+		Anonymous:          cfg.AWS.Anonymous,
+		MaxAttempts:        cfg.AWS.MaxAttempts,
+		RetryMode:          cfg.AWS.RetryMode,
+		MaxBackoff:         time.Duration(cfg.AWS.MaxBackoffMs) * time.Millisecond,
+		RequestTimeout:     time.Duration(cfg.AWS.RequestTimeoutMs) * time.Millisecond,
+		ModelID:            cfg.Model.ID,
+		MaxTokens:          cfg.Test.MaxTokens,
+		Temperature:        cfg.Test.Temperature,
+		SystemPrompt:       cfg.Test.SystemPrompt,
+		TopP:               cfg.Test.TopP,
+		TopK:               cfg.Test.TopK,
+		StopSequences:      cfg.Test.StopSequences,
+		Prefill:            cfg.Test.Prefill,
+		ServiceTier:        bedrock.ServiceTier(cfg.Test.ServiceTier),
+		PerformanceLatency: bedrock.PerformanceLatency(cfg.Test.PerformanceLatency),
+		Format:             cfg.Model.Format,
 	}
 
 	console := report.NewConsoleReporter()
@@ -36,73 +69,685 @@ func NewRunner(cfg *config.Config) *Runner {
 		config:       cfg,
 		clientConfig: clientConfig,
 		console:      console,
+		rng:          rand.New(rand.NewSource(time.Now().UnixNano())),
+		newInvoker:   newInvoker,
 	}
 }
 
+// runVariant pairs a report variant with the client settings that produce it
+type runVariant struct {
+	variant      types.Variant
+	clientConfig *bedrock.ClientConfig
+	prompt       string
+	// calibration is set when the prompt was sized by input tokens
+	calibration *types.PromptCalibration
+	// countedTokens is the CountTokens preflight count of the request
+	countedTokens int
+	countErr      error
+}
+
 // Run executes the benchmark test
 func (r *Runner) Run(ctx context.Context) ([]*types.ConcurrencyLevelStats, error) {
 	r.console.PrintHeader(r.config)
 
 	var allStats []*types.ConcurrencyLevelStats
-	prompt := GeneratePrompt(r.config.Test.PromptTemplate, r.config.Test.PromptSize)
+	if err := r.setup(); err != nil {
+		return nil, err
+	}
 
-	// Test streaming if enabled
-	if r.config.Test.Streaming {
-		r.console.PrintSection("Streaming Mode Test")
-		stats, err := r.runConcurrencyTests(ctx, prompt, true)
+	recorder, err := r.setupRecording()
+	if err != nil {
+		return nil, err
+	}
+	if recorder != nil {
+		defer recorder.Close()
+	}
+	r.setupFaults()
+
+	variants, err := r.prepareVariants(ctx, r.config.Test.CountTokens.Enabled)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, rv := range variants {
+		r.console.PrintSection(sectionTitle(rv.variant))
+		stats, err := r.runConcurrencyTests(ctx, rv)
 		if err != nil {
-			return nil, fmt.Errorf("streaming test failed: %w", err)
+			return nil, fmt.Errorf("%s test failed: %w", rv.variant.Label(), err)
 		}
 		allStats = append(allStats, stats...)
 	}
 
-	// Test non-streaming if enabled
-	if r.config.Test.NonStreaming {
-		r.console.PrintSection("Non-Streaming Mode Test")
-		stats, err := r.runConcurrencyTests(ctx, prompt, false)
-		if err != nil {
-			return nil, fmt.Errorf("non-streaming test failed: %w", err)
+	if recorder != nil {
+		if err := recorder.Close(); err != nil {
+			return nil, err
 		}
-		allStats = append(allStats, stats...)
+		r.console.PrintRecordingSaved(recorder.Count(), r.config.Recording.File)
 	}
 
 	return allStats, nil
 }
 
-// runConcurrencyTests runs tests with increasing concurrency levels
-func (r *Runner) runConcurrencyTests(ctx context.Context, prompt string, streaming bool) ([]*types.ConcurrencyLevelStats, error) {
-	var results []*types.ConcurrencyLevelStats
+// DryRun estimates the input tokens, token rate and cost of every variant
+// and concurrency level without sending generation requests. Input tokens
+// are counted with CountTokens (which also sizes prompt_size_tokens
+// prompts); latency is the assumed duration of one request, which sets how
+// many requests each worker sends.
+func (r *Runner) DryRun(ctx context.Context, latency time.Duration) ([]types.LoadEstimate, error) {
+	r.console.PrintHeader(r.config)
 
-	for concurrency := r.config.Concurrency.Start; concurrency <= r.config.Concurrency.End; concurrency += r.config.Concurrency.Step {
-		r.console.PrintConcurrencyLevel(concurrency)
+	if err := r.setup(); err != nil {
+		return nil, err
+	}
+	variants, err := r.prepareVariants(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+
+	estimates := r.estimateLoad(variants, latency)
+	r.console.PrintDryRun(estimates, latency, r.config.Model.Pricing.Enabled())
+	return estimates, nil
+}
 
-		stats, err := r.runSingleConcurrencyLevel(ctx, prompt, streaming, concurrency)
+// setup loads the request content shared by every variant: the prompt
+// caching prefix, tool definitions, attachments and response validator
+func (r *Runner) setup() error {
+	sharedPrefix, err := r.config.Test.SharedPrefix()
+	if err != nil {
+		return err
+	}
+	if r.config.Test.PromptCaching.Enabled && sharedPrefix == "" {
+		sharedPrefix = GenerateSharedPrefix(r.config.Test.PromptCaching.PrefixSize)
+	}
+	r.clientConfig.SharedPrefix = sharedPrefix
+
+	tools, err := r.config.Test.Tools()
+	if err != nil {
+		return err
+	}
+	for _, tool := range tools {
+		r.clientConfig.Tools = append(r.clientConfig.Tools, bedrock.ToolSpec{
+			Name:        tool.Name,
+			Description: tool.Description,
+			InputSchema: tool.InputSchema,
+		})
+	}
+	if len(tools) > 0 {
+		r.clientConfig.ToolChoice = r.config.Test.ToolUse.ToolChoice
+		if r.config.Test.ToolUse.SecondTurn {
+			r.clientConfig.ToolResult = r.config.Test.ToolUse.ToolResult
+		}
+	}
+
+	r.attachments, err = loadAttachments(r.config.Test.Multimodal)
+	if err != nil {
+		return err
+	}
+	r.clientConfig.Documents = r.attachments.documents
+
+	if r.config.Validation.Enabled() {
+		r.validator, err = validation.New(r.config.Validation, r.config.Test.Prefill)
 		if err != nil {
-			return nil, fmt.Errorf("concurrency level %d failed: %w", concurrency, err)
+			return err
 		}
+	}
+	return nil
+}
 
-		results = append(results, &types.ConcurrencyLevelStats{
-			ConcurrencyLevel: concurrency,
-			Stats:            stats,
+// prepareVariants expands the variants to run and sizes their prompts. With
+// countTokens, prompts sized by tokens are calibrated with CountTokens and
+// the request of every variant is counted.
+func (r *Runner) prepareVariants(ctx context.Context, countTokens bool) ([]runVariant, error) {
+	variants, err := r.variants()
+	if err != nil {
+		return nil, err
+	}
+	if err := r.sizePrompts(ctx, variants, countTokens); err != nil {
+		return nil, err
+	}
+	if countTokens {
+		r.countTokens(ctx, variants)
+	}
+	return variants, nil
+}
+
+// sizePrompts sets the prompt of every variant: prompt_size characters, or
+// the size calibrated per model and API to send prompt_size_tokens input tokens.
+// Calibration probes go through the same transport as the benchmark, so
+// they are recorded, replayed and subject to injected faults; with
+// countTokens they are CountTokens calls instead of invocations.
+func (r *Runner) sizePrompts(ctx context.Context, variants []runVariant, countTokens bool) error {
+	template := r.config.Test.PromptTemplate
+	if r.config.Test.PromptSizeTokens == 0 {
+		prompt := GeneratePrompt(template, r.config.Test.PromptSize)
+		for i := range variants {
+			variants[i].prompt = prompt
+		}
+		return nil
+	}
+
+	calibrator, err := newCalibrator(r.newInvoker, template, r.config.Test.PromptSizeTokens,
+		r.config.Test.PromptTokenTolerance, r.config.Test.CalibrationFile)
+	if err != nil {
+		return err
+	}
+	calibrator.countTokens = countTokens
+	printed := make(map[string]bool)
+	for i := range variants {
+		cfg := variants[i].clientConfig
+		calibration, err := calibrator.calibrate(ctx, cfg)
+		if err != nil {
+			return err
+		}
+		if key := calibrationKey(cfg); !printed[key] {
+			name := bedrock.ParseModelID(cfg.ModelID).DisplayName()
+			if len(r.config.Test.APIs()) > 1 {
+				name += fmt.Sprintf(" [%s]", cfg.API)
+			}
+			r.console.PrintCalibration(name, calibration)
+			printed[key] = true
+		}
+		variants[i].prompt = GeneratePrompt(template, calibration.PromptSize)
+		variants[i].calibration = calibration
+	}
+	return calibrator.save()
+}
+
+// countTokens counts the input tokens of each distinct request with the
+// CountTokens API. A failed count (e.g. a model without token counting) is
+// reported and leaves the variant uncounted rather than failing the run.
+func (r *Runner) countTokens(ctx context.Context, variants []runVariant) {
+	type count struct {
+		tokens int
+		err    error
+	}
+	counts := make(map[string]count)
+	for i := range variants {
+		rv := &variants[i]
+		cfg := rv.clientConfig
+		prompt := requestPrompt(rv.prompt, cfg.SharedPrefix != "", 0, 1)
+		key := fmt.Sprintf("%s|%s|%s|%d|%s|%t|%s", cfg.ModelID, cfg.API, rv.variant.Images, cfg.ThinkingBudget, cfg.ReasoningEffort, cfg.PromptCaching, prompt)
+
+		c, ok := counts[key]
+		if !ok {
+			c.tokens, c.err = r.newInvoker(cfg).CountTokens(ctx, prompt)
+			counts[key] = c
+			r.console.PrintTokenCount(rv.variant.Label(), c.tokens, c.err)
+		}
+		rv.countedTokens, rv.countErr = c.tokens, c.err
+	}
+}
+
+// estimateLoad estimates the load of every variant and concurrency level
+// for a dry run, assuming each request takes latency
+func (r *Runner) estimateLoad(variants []runVariant, latency time.Duration) []types.LoadEstimate {
+	pricing := &r.config.Model.Pricing
+	levelSeconds := float64(r.config.Concurrency.DurationSeconds * r.config.Concurrency.Repetitions)
+
+	var estimates []types.LoadEstimate
+	for _, rv := range variants {
+		input, counted := rv.countedTokens, rv.countErr == nil && rv.countedTokens > 0
+		if !counted {
+			input = estimateInputTokens(rv)
+		}
+		maxTokens := rv.clientConfig.MaxTokens
+
+		for _, concurrency := range r.concurrencyLevels() {
+			perMinute := float64(concurrency) * time.Minute.Seconds() / latency.Seconds()
+			requests := int(math.Ceil(perMinute * levelSeconds / time.Minute.Seconds()))
+			estimate := types.LoadEstimate{
+				Variant:           rv.variant,
+				Concurrency:       concurrency,
+				InputTokens:       input,
+				Counted:           counted,
+				MaxOutputTokens:   maxTokens,
+				RequestsPerMinute: perMinute,
+				TokensPerMinute:   perMinute * float64(input+maxTokens),
+				Requests:          requests,
+			}
+			if pricing.Enabled() {
+				estimate.Cost = float64(requests) * pricing.EstimateCost(input, maxTokens, string(rv.clientConfig.ServiceTier)) *
+					pricing.LatencyMultiplier(string(rv.clientConfig.PerformanceLatency))
+			}
+			estimates = append(estimates, estimate)
+		}
+	}
+	return estimates
+}
+
+// estimateInputTokens guesses the input tokens of a variant's request at
+// four characters per token, for models CountTokens cannot count
+func estimateInputTokens(rv runVariant) int {
+	if rv.calibration != nil {
+		return rv.calibration.ProbeTokens
+	}
+	cfg := rv.clientConfig
+	chars := utf8.RuneCountInString(rv.prompt) + utf8.RuneCountInString(cfg.SystemPrompt) +
+		utf8.RuneCountInString(cfg.SharedPrefix) + utf8.RuneCountInString(cfg.Prefill)
+	return max(1, chars/4)
+}
+
+// setupRecording installs the record or replay transport of recording.mode.
+// The recorder, if any, must be closed once the run is over. Replayed
+// requests never reach AWS, so they are sent unsigned.
+func (r *Runner) setupRecording() (*recording.Recorder, error) {
+	switch r.config.Recording.Mode {
+	case "record":
+		recorder, err := recording.NewRecorder(r.config.Recording.File)
+		if err != nil {
+			return nil, err
+		}
+		r.wrapTransport(recorder.Wrap)
+		return recorder, nil
+	case "replay":
+		replayer, err := recording.LoadReplayer(r.config.Recording.File)
+		if err != nil {
+			return nil, err
+		}
+		r.wrapTransport(replayer.Wrap)
+		r.clientConfig.Anonymous = true
+	}
+	return nil, nil
+}
+
+// setupFaults installs the fault injector of the faults rules. Faults are
+// injected outside the record or replay transport, so recordings hold only
+// real traffic and replays can be run under injected faults.
+func (r *Runner) setupFaults() {
+	if !r.config.Faults.Enabled() {
+		return
+	}
+	rules := make([]faults.Rule, 0, len(r.config.Faults.Rules))
+	for _, rule := range r.config.Faults.Rules {
+		rules = append(rules, faults.Rule{
+			Type:    faults.Type(rule.Type),
+			Rate:    rule.Rate,
+			Start:   time.Duration(rule.StartSeconds * float64(time.Second)),
+			End:     time.Duration(rule.EndSeconds * float64(time.Second)),
+			Latency: time.Duration(rule.LatencyMs) * time.Millisecond,
+			Status:  rule.Status,
+			After:   time.Duration(rule.AfterMs) * time.Millisecond,
 		})
+	}
+	seed := r.config.Faults.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	r.faults = faults.NewInjector(rules, seed)
+	r.wrapTransport(r.faults.Wrap)
+}
+
+// wrapTransport adds wrap to the client transport, outside any wrapper
+// already installed
+func (r *Runner) wrapTransport(wrap func(http.RoundTripper) http.RoundTripper) {
+	inner := r.clientConfig.WrapTransport
+	if inner == nil {
+		r.clientConfig.WrapTransport = wrap
+		return
+	}
+	r.clientConfig.WrapTransport = func(base http.RoundTripper) http.RoundTripper {
+		return wrap(inner(base))
+	}
+}
+
+// requestSettings is one combination of the per-request settings that are
+// swept for every model target
+type requestSettings struct {
+	tier            string
+	latency         string
+	caching         bool
+	thinkingBudget  int
+	effort          string
+	imageCount      int
+	imageResolution int
+}
 
-		r.console.PrintStats(stats, concurrency)
+// requestSettings returns every combination of service tier, performance
+// latency, prompt caching mode, thinking budget, reasoning effort and image
+// count and resolution, in run order
+func (r *Runner) requestSettings() []requestSettings {
+	var settings []requestSettings
+	for _, tier := range r.config.Test.Tiers() {
+		for _, latency := range r.config.Test.Latencies() {
+			for _, caching := range r.config.Test.CachingModes() {
+				for _, budget := range r.config.Test.Budgets() {
+					for _, effort := range r.config.Test.Efforts() {
+						for _, count := range r.config.Test.Multimodal.ImagesPerRequest() {
+							for _, resolution := range r.config.Test.Multimodal.ImageResolutions() {
+								settings = append(settings, requestSettings{
+									tier:            tier,
+									latency:         latency,
+									caching:         caching,
+									thinkingBudget:  budget,
+									effort:          effort,
+									imageCount:      count,
+									imageResolution: resolution,
+								})
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+	return settings
+}
+
+// imageLabel describes the images attached per request, e.g. "2 images @1024px"
+func imageLabel(count, resolution int) string {
+	label := "1 image"
+	if count != 1 {
+		label = fmt.Sprintf("%d images", count)
+	}
+	if resolution > 0 {
+		label += fmt.Sprintf(" @%dpx", resolution)
+	}
+	return label
+}
+
+// variants expands the configuration into the list of variants to run:
+// streaming before non-streaming, then each configured API, then each model
+// target, then each service tier, performance latency, prompt caching mode
+// and thinking budget.
+// InvokeModel variants need a model adapter; streaming variants the
+// adapter cannot serve are skipped with a warning rather than producing a
+// sweep of failures.
+func (r *Runner) variants() ([]runVariant, error) {
+	var modes []bool
+	if r.config.Test.Streaming {
+		modes = append(modes, true)
+	}
+	if r.config.Test.NonStreaming {
+		modes = append(modes, false)
+	}
+
+	apis := r.config.Test.APIs()
+	targets := r.config.Model.Targets()
+	tiers := r.config.Test.Tiers()
+	latencies := r.config.Test.Latencies()
+	budgets := r.config.Test.Budgets()
+	settings := r.requestSettings()
+
+	thinking := false
+	for _, budget := range budgets {
+		thinking = thinking || budget > 0
+	}
+	effort := false
+	for _, level := range r.config.Test.Efforts() {
+		effort = effort || level != ""
+	}
+	if thinking {
+		// Requests with thinking cannot carry these, so results labelled with them would be wrong
+		switch {
+		case r.config.Test.Prefill != "":
+			return nil, fmt.Errorf("a prefill is not supported with extended thinking")
+		case r.config.Test.TopK > 0:
+			return nil, fmt.Errorf("top_k is not supported with extended thinking")
+		case r.config.Test.Temperature != 0 && r.config.Test.Temperature != 1:
+			return nil, fmt.Errorf("extended thinking runs at the default temperature; set test.temperature to 0 or 1")
+		}
+	}
+
+	adapters := make(map[string]bedrock.ModelAdapter, len(targets))
+	for _, target := range targets {
+		ref := bedrock.ParseModelID(target)
+		if ref.Region != "" && ref.Region != r.clientConfig.Region {
+			return nil, fmt.Errorf("%s is in region %s but aws.region is %s", target, ref.Region, r.clientConfig.Region)
+		}
+
+		for _, api := range apis {
+			if bedrock.API(api) != bedrock.APIInvoke {
+				if err := r.checkConverseParams(target, thinking, effort); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			adapter, err := bedrock.ResolveAdapter(target, r.clientConfig.Format)
+			if err != nil {
+				return nil, err
+			}
+			if r.config.Test.TopK > 0 && !adapter.Capabilities().TopK {
+				return nil, fmt.Errorf("top_k is not supported for %s models over InvokeModel", adapter.Name())
+			}
+			if len(r.config.Test.StopSequences) > 0 && !adapter.Capabilities().StopSequences {
+				return nil, fmt.Errorf("stop sequences are not supported for %s models over InvokeModel", adapter.Name())
+			}
+			if r.config.Test.PromptCaching.Enabled && !adapter.Capabilities().PromptCaching {
+				return nil, fmt.Errorf("prompt caching is not supported for %s models over InvokeModel; use test.api \"converse\"", adapter.Name())
+			}
+			if r.config.Test.ToolUse.Enabled && !adapter.Capabilities().ToolUse {
+				return nil, fmt.Errorf("tool use is not supported for %s models over InvokeModel; use test.api \"converse\"", adapter.Name())
+			}
+			if len(r.config.Test.Multimodal.Images) > 0 && !adapter.Capabilities().Images {
+				return nil, fmt.Errorf("image input is not supported for %s models over InvokeModel; use test.api \"converse\"", adapter.Name())
+			}
+			if len(r.config.Test.Multimodal.Documents) > 0 && !adapter.Capabilities().Documents {
+				return nil, fmt.Errorf("document input is not supported for %s models over InvokeModel; use test.api \"converse\"", adapter.Name())
+			}
+			if thinking && !adapter.Capabilities().Thinking {
+				return nil, fmt.Errorf("a thinking budget is not supported for %s models; reasoning models that always think need no budget (gpt-oss takes test.reasoning_effort)", adapter.Name())
+			}
+			if effort && !adapter.Capabilities().ReasoningEffort {
+				return nil, fmt.Errorf("a reasoning effort is not supported for %s models", adapter.Name())
+			}
+			adapters[target] = adapter
+		}
+	}
+
+	var variants []runVariant
+	for _, streaming := range modes {
+		for _, api := range apis {
+			for _, target := range targets {
+				adapter := adapters[target]
+				if streaming && bedrock.API(api) == bedrock.APIInvoke && !adapter.Capabilities().Streaming {
+					r.console.PrintError(fmt.Errorf("skipping streaming test of %s: %s models do not support InvokeModelWithResponseStream", target, adapter.Name()))
+					continue
+				}
+
+				for _, setting := range settings {
+					clientConfig := *r.clientConfig
+					clientConfig.API = bedrock.API(api)
+					clientConfig.ModelID = target
+					clientConfig.ServiceTier = bedrock.ServiceTier(setting.tier)
+					clientConfig.PerformanceLatency = bedrock.PerformanceLatency(setting.latency)
+					clientConfig.PromptCaching = setting.caching
+					clientConfig.ThinkingBudget = setting.thinkingBudget
+					clientConfig.ReasoningEffort = setting.effort
+					images, err := r.attachments.imageSet(setting.imageCount, setting.imageResolution)
+					if err != nil {
+						return nil, err
+					}
+					clientConfig.Images = images
+
+					v := types.Variant{Streaming: streaming}
+					// Only label settings when they are not the implicit default
+					if len(apis) > 1 || api != string(bedrock.APIInvoke) {
+						v.API = api
+					}
+					if len(targets) > 1 {
+						v.Model = bedrock.ParseModelID(target).DisplayName()
+					}
+					if len(tiers) > 1 || setting.tier != string(bedrock.ServiceTierDefault) {
+						v.ServiceTier = setting.tier
+					}
+					if len(latencies) > 1 || setting.latency != string(bedrock.PerformanceLatencyStandard) {
+						v.Latency = setting.latency
+					}
+					if r.config.Test.PromptCaching.Enabled {
+						v.PromptCaching = "off"
+						if setting.caching {
+							v.PromptCaching = "on"
+						}
+					}
+					if len(budgets) > 1 || setting.thinkingBudget > 0 {
+						v.Thinking = "off"
+						if setting.thinkingBudget > 0 {
+							v.Thinking = fmt.Sprintf("%d", setting.thinkingBudget)
+						}
+					}
+					if effort {
+						v.Thinking = "effort " + setting.effort
+						if setting.effort == "" {
+							v.Thinking = "effort default"
+						}
+					}
+					if len(images) > 0 {
+						v.Images = imageLabel(setting.imageCount, setting.imageResolution)
+					}
+
+					variants = append(variants, runVariant{variant: v, clientConfig: &clientConfig})
+				}
+			}
+		}
+	}
+	return variants, nil
+}
+
+// checkConverseParams rejects Converse settings that have no common Converse
+// parameter and that the target's model family does not accept: top_k, a
+// thinking budget and a reasoning effort
+func (r *Runner) checkConverseParams(target string, thinking, effort bool) error {
+	if r.config.Test.TopK == 0 && !thinking && !effort {
+		return nil
+	}
+	adapter, err := bedrock.ResolveAdapter(target, r.clientConfig.Format)
+	if err != nil {
+		return fmt.Errorf("top_k and reasoning settings over Converse depend on the model family: %w", err)
+	}
+	if effort && !adapter.Capabilities().ReasoningEffort {
+		return fmt.Errorf("a reasoning effort is not supported for %s models", adapter.Name())
+	}
+	if r.config.Test.TopK > 0 && !bedrock.ConverseSupportsTopK(adapter.Name()) {
+		return fmt.Errorf("top_k is not supported for %s models over Converse", adapter.Name())
+	}
+	if thinking && !adapter.Capabilities().Thinking {
+		return fmt.Errorf("a thinking budget is not supported for %s models; reasoning models that always think need no budget (gpt-oss takes test.reasoning_effort)", adapter.Name())
+	}
+	return nil
+}
+
+// sectionTitle returns the console section title for a variant
+func sectionTitle(v types.Variant) string {
+	title := "Non-Streaming Mode Test"
+	if v.Streaming {
+		title = "Streaming Mode Test"
+	}
+	if v.API != "" {
+		title += fmt.Sprintf(" [%s]", v.API)
+	}
+	if v.ServiceTier != "" {
+		title += fmt.Sprintf(" [%s tier]", v.ServiceTier)
+	}
+	if v.Latency != "" {
+		title += fmt.Sprintf(" [%s latency]", v.Latency)
+	}
+	if v.PromptCaching != "" {
+		title += fmt.Sprintf(" [cache %s]", v.PromptCaching)
+	}
+	if v.Thinking != "" {
+		title += fmt.Sprintf(" [thinking %s]", v.Thinking)
+	}
+	if v.Images != "" {
+		title += fmt.Sprintf(" [%s]", v.Images)
+	}
+	if v.Model != "" {
+		title += fmt.Sprintf(" - %s", v.Model)
+	}
+	return title
+}
+
+// runConcurrencyTests runs tests with increasing concurrency levels.
+// Each level is run Concurrency.Repetitions times; with RandomizeOrder the
+// order of levels is shuffled within each repetition so slow drifts in
+// service behaviour do not line up with the concurrency sweep.
+func (r *Runner) runConcurrencyTests(ctx context.Context, rv runVariant) ([]*types.ConcurrencyLevelStats, error) {
+	levels := r.concurrencyLevels()
+	repetitions := r.config.Concurrency.Repetitions
+
+	pooled := make(map[int]*Metrics, len(levels))
+	trials := make(map[int][]*types.Stats, len(levels))
+	for _, concurrency := range levels {
+		pooled[concurrency] = NewMetrics()
+	}
+
+	for rep := 1; rep <= repetitions; rep++ {
+		order := make([]int, len(levels))
+		copy(order, levels)
+		if r.config.Concurrency.RandomizeOrder {
+			r.rng.Shuffle(len(order), func(i, j int) { order[i], order[j] = order[j], order[i] })
+		}
+
+		if repetitions > 1 {
+			r.console.PrintRepetition(rep, repetitions)
+		}
+
+		for _, concurrency := range order {
+			r.console.PrintConcurrencyLevel(concurrency)
+
+			metrics, err := r.runSingleConcurrencyLevel(ctx, rv, concurrency)
+			if err != nil {
+				return nil, fmt.Errorf("concurrency level %d failed: %w", concurrency, err)
+			}
+
+			stats := metrics.ComputeStats()
+			trials[concurrency] = append(trials[concurrency], stats)
+			pooled[concurrency].Merge(metrics)
+
+			r.console.PrintStats(stats, concurrency)
+		}
+	}
+
+	var results []*types.ConcurrencyLevelStats
+	for _, concurrency := range levels {
+		levelStats := &types.ConcurrencyLevelStats{
+			Variant:          rv.variant,
+			ConcurrencyLevel: concurrency,
+			Stats:            pooled[concurrency].ComputeStats(),
+			Trials:           trials[concurrency],
+			Calibration:      rv.calibration,
+		}
+		if r.config.Test.CountTokens.Enabled {
+			levelStats.CountedInputTokens = rv.countedTokens
+			if rv.countErr != nil {
+				levelStats.CountError = rv.countErr.Error()
+			}
+		}
+		if repetitions > 1 {
+			levelStats.Summary = summarizeTrials(trials[concurrency])
+			r.console.PrintTrialSummary(levelStats.Summary, concurrency)
+		}
+		results = append(results, levelStats)
 	}
 
 	return results, nil
 }
 
+// concurrencyLevels returns the configured concurrency levels in ascending order
+func (r *Runner) concurrencyLevels() []int {
+	var levels []int
+	for concurrency := r.config.Concurrency.Start; concurrency <= r.config.Concurrency.End; concurrency += r.config.Concurrency.Step {
+		levels = append(levels, concurrency)
+	}
+	return levels
+}
+
 // runSingleConcurrencyLevel runs a test at a specific concurrency level
-func (r *Runner) runSingleConcurrencyLevel(ctx context.Context, prompt string, streaming bool, concurrency int) (*types.Stats, error) {
+func (r *Runner) runSingleConcurrencyLevel(ctx context.Context, rv runVariant, concurrency int) (*Metrics, error) {
 	metrics := NewMetrics()
 
-	// Create worker pool - each worker will create its own client
-	pool := NewWorkerPool(r.clientConfig, metrics, streaming, prompt, concurrency)
+	// Create worker pool - each worker will create its own invoker
+	pool := NewWorkerPool(r.newInvoker, rv.clientConfig, metrics, rv.variant.Streaming, rv.prompt, concurrency)
+	pool.validator = r.validator
 
 	// Create a context with timeout
 	testCtx, cancel := context.WithTimeout(ctx, time.Duration(r.config.Concurrency.DurationSeconds)*time.Second)
 	defer cancel()
 
+	// Fault windows are measured from the start of each level
+	if r.faults != nil {
+		r.faults.Restart()
+	}
+
 	// Start workers
 	pool.Start(testCtx)
 
@@ -135,7 +780,11 @@ func (r *Runner) runSingleConcurrencyLevel(ctx context.Context, prompt string, s
 	// Finalize metrics
 	metrics.Finalize()
 
-	return metrics.ComputeStats(), nil
+	if r.faults != nil {
+		r.console.PrintInjectedFaults(faults.FormatCounts(r.faults.Counts()))
+	}
+
+	return metrics, nil
 }
 
 // GenerateReport generates the final benchmark report
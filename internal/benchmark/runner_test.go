@@ -0,0 +1,390 @@
+package benchmark
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"bedrock-performance/internal/bedrock"
+	"bedrock-performance/internal/config"
+)
+
+// testConfig returns a one-second-per-level configuration for runner tests
+func testConfig(t *testing.T) *config.Config {
+	return &config.Config{
+		AWS:   config.AWSConfig{Region: "us-east-1"},
+		Model: config.ModelConfig{ID: "anthropic.claude-3-haiku-20240307-v1:0", Quota: 100},
+		Test: config.TestConfig{
+			PromptSize:         200,
+			Streaming:          true,
+			NonStreaming:       true,
+			MaxTokens:          256,
+			API:                "invoke",
+			ServiceTier:        "default",
+			PerformanceLatency: "standard",
+		},
+		Concurrency: config.ConcurrencyConfig{Start: 1, End: 2, Step: 1, DurationSeconds: 1, Repetitions: 1},
+		Output:      config.OutputConfig{ReportFile: filepath.Join(t.TempDir(), "report.md")},
+	}
+}
+
+func TestRunnerLifecycle(t *testing.T) {
+	cfg := testConfig(t)
+	fake := NewFakeInvoker(FakeResponse{Latency: 10 * time.Millisecond, TTFT: 2 * time.Millisecond, InputTokens: 50, OutputTokens: 20})
+	runner := NewRunnerWithInvoker(cfg, fake.Factory())
+
+	allStats, err := runner.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run() = %v", err)
+	}
+
+	// Streaming then non-streaming, each at concurrency 1 and 2
+	if len(allStats) != 4 {
+		t.Fatalf("got %d result sets, want 4", len(allStats))
+	}
+	for i, want := range []struct {
+		streaming   bool
+		concurrency int
+	}{{true, 1}, {true, 2}, {false, 1}, {false, 2}} {
+		got := allStats[i]
+		if got.Variant.Streaming != want.streaming || got.ConcurrencyLevel != want.concurrency {
+			t.Errorf("result %d = streaming %v at %d, want streaming %v at %d",
+				i, got.Variant.Streaming, got.ConcurrencyLevel, want.streaming, want.concurrency)
+		}
+		if got.Stats.SuccessCount == 0 || got.Stats.FailureCount != 0 {
+			t.Errorf("result %d: success/failure = %d/%d", i, got.Stats.SuccessCount, got.Stats.FailureCount)
+		}
+		if got.Stats.HasTTFT != want.streaming {
+			t.Errorf("result %d: HasTTFT = %v, want %v", i, got.Stats.HasTTFT, want.streaming)
+		}
+	}
+	if fake.StreamingCalls() == 0 || fake.StreamingCalls() == fake.Calls() {
+		t.Errorf("streaming calls = %d of %d, want both modes", fake.StreamingCalls(), fake.Calls())
+	}
+
+	if err := runner.GenerateReport(allStats); err != nil {
+		t.Fatalf("GenerateReport() = %v", err)
+	}
+	report, err := os.ReadFile(cfg.Output.ReportFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(report), "anthropic.claude-3-haiku-20240307-v1:0") {
+		t.Errorf("report does not mention the model")
+	}
+}
+
+func TestRunnerRejectsUnsupportedCapability(t *testing.T) {
+	tests := []struct {
+		name    string
+		modelID string
+		api     string
+		modify  func(c *config.Config)
+		wantErr string
+	}{
+		{"tool use", "mistral.mistral-7b-instruct-v0:2", "invoke", func(c *config.Config) {
+			c.Test.ToolUse = config.ToolUseConfig{Enabled: true, ToolChoice: "auto"}
+		}, "tool use is not supported"},
+		{"llama top_k", "meta.llama3-8b-instruct-v1:0", "invoke", func(c *config.Config) { c.Test.TopK = 40 }, "top_k is not supported for llama models over InvokeModel"},
+		{"llama stop sequences", "meta.llama3-8b-instruct-v1:0", "invoke", func(c *config.Config) { c.Test.StopSequences = []string{"END"} }, "stop sequences are not supported"},
+		{"converse top_k", "mistral.mistral-7b-instruct-v0:2", "converse", func(c *config.Config) { c.Test.TopK = 40 }, "top_k is not supported for mistral models over Converse"},
+		{"reasoning effort", "anthropic.claude-3-haiku-20240307-v1:0", "invoke", func(c *config.Config) { c.Test.ReasoningEffort = "high" }, "a reasoning effort is not supported for claude models"},
+		{"converse thinking", "amazon.nova-lite-v1:0", "converse", func(c *config.Config) {
+			c.Test.MaxTokens = 4096
+			c.Test.ThinkingBudget = 1024
+		}, "a thinking budget is not supported for nova models"},
+		{"prefill with thinking", "anthropic.claude-3-haiku-20240307-v1:0", "converse", func(c *config.Config) {
+			c.Test.MaxTokens = 4096
+			c.Test.ThinkingBudget = 1024
+			c.Test.Prefill = "{"
+		}, "a prefill is not supported with extended thinking"},
+		{"top_k with thinking", "anthropic.claude-3-haiku-20240307-v1:0", "invoke", func(c *config.Config) {
+			c.Test.MaxTokens = 4096
+			c.Test.ThinkingBudgets = []int{0, 1024}
+			c.Test.TopK = 40
+		}, "top_k is not supported with extended thinking"},
+		{"temperature with thinking", "anthropic.claude-3-haiku-20240307-v1:0", "invoke", func(c *config.Config) {
+			c.Test.MaxTokens = 4096
+			c.Test.ThinkingBudget = 1024
+			c.Test.Temperature = 0.7
+		}, "default temperature"},
+		{"model in another region", "arn:aws:bedrock:eu-west-1:123456789012:inference-profile/eu.anthropic.claude-3-haiku-20240307-v1:0", "invoke", func(c *config.Config) {},
+			"is in region eu-west-1 but aws.region is us-east-1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig(t)
+			cfg.Model.ID = tt.modelID
+			cfg.Test.API = tt.api
+			tt.modify(cfg)
+			fake := NewFakeInvoker()
+
+			_, err := NewRunnerWithInvoker(cfg, fake.Factory()).Run(context.Background())
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Run() = %v, want %q", err, tt.wantErr)
+			}
+			if fake.Calls() != 0 {
+				t.Errorf("fake was called %d times before validation failed", fake.Calls())
+			}
+		})
+	}
+}
+
+func TestRunnerConverseTopKFamilies(t *testing.T) {
+	// Converse has no common top_k parameter; only the families with a known
+	// additionalModelRequestFields mapping accept it
+	tests := []struct {
+		modelID string
+		ok      bool
+	}{
+		{"anthropic.claude-3-haiku-20240307-v1:0", true},
+		{"amazon.nova-lite-v1:0", true},
+		{"meta.llama3-8b-instruct-v1:0", false},
+		{"meta.llama2-13b-chat-v1", false},
+		{"mistral.mistral-7b-instruct-v0:2", false},
+		{"amazon.titan-text-express-v1", false},
+		{"cohere.command-r-v1:0", false},
+		{"deepseek.r1-v1:0", false},
+		{"qwen.qwen3-32b-v1:0", false},
+		{"ai21.jamba-1-5-mini-v1:0", false},
+		{"openai.gpt-oss-20b-1:0", false},
+	}
+	for _, tt := range tests {
+		cfg := testConfig(t)
+		cfg.Model.ID = tt.modelID
+		cfg.Test.API = "converse"
+		cfg.Test.TopK = 40
+
+		_, err := NewRunnerWithInvoker(cfg, NewFakeInvoker().Factory()).variants()
+		switch {
+		case tt.ok && err != nil:
+			t.Errorf("%s: variants() = %v, want top_k accepted", tt.modelID, err)
+		case !tt.ok && (err == nil || !strings.Contains(err.Error(), "top_k is not supported")):
+			t.Errorf("%s: variants() = %v, want top_k rejected", tt.modelID, err)
+		}
+	}
+}
+
+func TestRunnerCalibratesEachAPI(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.Test.API = "both"
+	cfg.Test.PromptSize = 0
+	cfg.Test.PromptSizeTokens = 2000
+	cfg.Test.PromptTokenTolerance = 2
+	cfg.Test.CalibrationFile = filepath.Join(t.TempDir(), "calibration.json")
+
+	// Converse wraps the prompt with more overhead than InvokeModel
+	counters := map[bedrock.API]*tokenCounter{
+		bedrock.APIInvoke:   {overhead: 100},
+		bedrock.APIConverse: {overhead: 400},
+	}
+	factory := func(c *bedrock.ClientConfig) Invoker { return counters[c.API] }
+
+	variants, err := NewRunnerWithInvoker(cfg, factory).prepareVariants(context.Background(), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sizes := make(map[string]int)
+	for _, v := range variants {
+		if v.calibration == nil || !v.calibration.Converged {
+			t.Fatalf("%s variant calibration = %+v, want converged", v.clientConfig.API, v.calibration)
+		}
+		sizes[string(v.clientConfig.API)] = v.calibration.PromptSize
+	}
+	for api, counter := range counters {
+		if counter.probes == 0 {
+			t.Errorf("%s was never probed", api)
+		}
+	}
+	if len(sizes) != 2 || sizes["invoke"] <= sizes["converse"] {
+		t.Errorf("prompt sizes by API = %v, want a smaller prompt for converse", sizes)
+	}
+
+	// The saved sizes are reused per API on the next run
+	for _, counter := range counters {
+		counter.probes = 0
+	}
+	if _, err := NewRunnerWithInvoker(cfg, factory).prepareVariants(context.Background(), false); err != nil {
+		t.Fatal(err)
+	}
+	for api, counter := range counters {
+		if counter.probes != 0 {
+			t.Errorf("%s was probed again %d times despite the calibration file", api, counter.probes)
+		}
+	}
+}
+
+func TestRunnerStopsOnCancel(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.Concurrency.DurationSeconds = 30
+	fake := NewFakeInvoker(FakeResponse{Latency: 5 * time.Millisecond, OutputTokens: 1})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if _, err := NewRunnerWithInvoker(cfg, fake.Factory()).Run(ctx); err != nil {
+		t.Fatalf("Run() = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Run took %v after cancellation", elapsed)
+	}
+}
+
+func TestRunnerCountTokensPreflight(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.Concurrency.End = 1
+	cfg.Test.CountTokens = config.CountTokensConfig{Enabled: true, TolerancePercent: 2}
+	fake := NewFakeInvoker(FakeResponse{Latency: 10 * time.Millisecond, InputTokens: 50, CountedTokens: 40, OutputTokens: 5})
+	runner := NewRunnerWithInvoker(cfg, fake.Factory())
+
+	allStats, err := runner.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run() = %v", err)
+	}
+	// Streaming and non-streaming send the same request, so it is counted once
+	if fake.CountCalls() != 1 {
+		t.Errorf("CountTokens called %d times, want 1", fake.CountCalls())
+	}
+	for _, stat := range allStats {
+		if stat.CountedInputTokens != 40 {
+			t.Errorf("%s: CountedInputTokens = %d, want 40", stat.Variant.Label(), stat.CountedInputTokens)
+		}
+	}
+
+	if err := runner.GenerateReport(allStats); err != nil {
+		t.Fatal(err)
+	}
+	report, err := os.ReadFile(cfg.Output.ReportFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(report), "## Token Accounting") || !strings.Contains(string(report), "+25.0% | **mismatch**") {
+		t.Errorf("report does not flag the 50 vs 40 token mismatch")
+	}
+}
+
+func TestRunnerDryRun(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.Model.Pricing = config.PricingConfig{InputPerMillion: 1, OutputPerMillion: 5}
+	fake := NewFakeInvoker(FakeResponse{CountedTokens: 1000})
+
+	estimates, err := NewRunnerWithInvoker(cfg, fake.Factory()).DryRun(context.Background(), 2*time.Second)
+	if err != nil {
+		t.Fatalf("DryRun() = %v", err)
+	}
+	if fake.Calls() != 0 {
+		t.Errorf("dry run sent %d generation requests", fake.Calls())
+	}
+	if len(estimates) != 4 {
+		t.Fatalf("got %d estimates, want 4", len(estimates))
+	}
+
+	// Two workers at 2s per request send 60 requests per minute, reserving
+	// 1000 input + 256 max output tokens each
+	e := estimates[1]
+	if e.Concurrency != 2 || !e.Counted || e.RequestsPerMinute != 60 || e.TokensPerMinute != 60*1256 {
+		t.Errorf("estimate = %+v", e)
+	}
+	if e.Requests != 1 {
+		t.Errorf("Requests = %d, want 1 in a one-second level", e.Requests)
+	}
+	if want := (1000*1.0 + 256*5.0) / 1e6; e.Cost != want {
+		t.Errorf("Cost = %v, want %v", e.Cost, want)
+	}
+}
+
+func TestRunnerDryRunWithoutCountTokens(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.Test.NonStreaming = false
+	fake := NewFakeInvoker(FakeResponse{CountErr: errors.New("model does not support token counting")})
+
+	estimates, err := NewRunnerWithInvoker(cfg, fake.Factory()).DryRun(context.Background(), time.Second)
+	if err != nil {
+		t.Fatalf("DryRun() = %v", err)
+	}
+	// The 200 character prompt at four characters per token
+	if estimates[0].Counted || estimates[0].InputTokens < 45 || estimates[0].InputTokens > 50 {
+		t.Errorf("estimate = %+v, want an uncounted estimate of about 50 tokens", estimates[0])
+	}
+}
+
+func TestRunnerComparesLatencyModes(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.Test.NonStreaming = false
+	cfg.Concurrency.End = 1
+	cfg.Test.PerformanceLatencies = []string{"standard", "optimized"}
+	cfg.Model.Pricing = config.PricingConfig{InputPerMillion: 1, OutputPerMillion: 5}
+	fake := NewFakeInvoker(FakeResponse{Latency: 10 * time.Millisecond, InputTokens: 50, OutputTokens: 20, PerformanceLatency: "optimized"})
+
+	var mu sync.Mutex
+	requested := make(map[bedrock.PerformanceLatency]bool)
+	factory := func(c *bedrock.ClientConfig) Invoker {
+		mu.Lock()
+		defer mu.Unlock()
+		requested[c.PerformanceLatency] = true
+		return fake
+	}
+
+	runner := NewRunnerWithInvoker(cfg, factory)
+	allStats, err := runner.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run() = %v", err)
+	}
+	if len(allStats) != 2 || allStats[0].Variant.Latency != "standard" || allStats[1].Variant.Latency != "optimized" {
+		t.Fatalf("got %d levels, want one standard and one optimized", len(allStats))
+	}
+	if !requested[bedrock.PerformanceLatencyStandard] || !requested[bedrock.PerformanceLatencyOptimized] {
+		t.Errorf("requested latency modes = %v, want standard and optimized", requested)
+	}
+	if s := allStats[1].Stats; s.OptimizedServed != s.SuccessCount || s.LatencyModeReported != s.SuccessCount {
+		t.Errorf("served optimized %d of %d reported, want all %d", s.OptimizedServed, s.LatencyModeReported, s.SuccessCount)
+	}
+
+	if err := runner.GenerateReport(allStats); err != nil {
+		t.Fatal(err)
+	}
+	report, err := os.ReadFile(cfg.Output.ReportFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Identical tokens cost 1.25x with optimized latency
+	if !strings.Contains(string(report), "## Latency-Optimized Inference") || !strings.Contains(string(report), "| +25.0% |\n") {
+		t.Errorf("report does not compare the cost of latency modes")
+	}
+}
+
+func TestRunnerSweepsReasoningEffort(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.Model.ID = "openai.gpt-oss-20b-1:0"
+	cfg.Test.NonStreaming = false
+	cfg.Concurrency.End = 1
+	cfg.Test.ReasoningEfforts = []string{"low", "high"}
+	fake := NewFakeInvoker(FakeResponse{Latency: 5 * time.Millisecond, InputTokens: 10, OutputTokens: 5})
+
+	var mu sync.Mutex
+	var efforts []string
+	factory := func(c *bedrock.ClientConfig) Invoker {
+		mu.Lock()
+		defer mu.Unlock()
+		efforts = append(efforts, c.ReasoningEffort)
+		return fake
+	}
+
+	allStats, err := NewRunnerWithInvoker(cfg, factory).Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run() = %v", err)
+	}
+	if len(allStats) != 2 || allStats[0].Variant.Thinking != "effort low" || allStats[1].Variant.Thinking != "effort high" {
+		t.Fatalf("variants = %v, want effort low and effort high", allStats)
+	}
+	if !slices.Contains(efforts, "low") || !slices.Contains(efforts, "high") {
+		t.Errorf("requested efforts = %v, want low and high", efforts)
+	}
+}
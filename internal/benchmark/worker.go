@@ -5,25 +5,31 @@ import (
 	"fmt"
 	"strings"
 	"sync"
+	"unicode"
+	"unicode/utf8"
 
 	"bedrock-performance/internal/bedrock"
+	"bedrock-performance/internal/validation"
 )
 
 // WorkerPool manages a pool of workers for concurrent testing
 type WorkerPool struct {
+	newInvoker   InvokerFactory
 	clientConfig *bedrock.ClientConfig
 	metrics      *Metrics
 	streaming    bool
 	prompt       string
 	workerCount  int
+	validator    *validation.Validator
 	stopChan     chan struct{}
 	wg           sync.WaitGroup
 }
 
 // NewWorkerPool creates a new worker pool
-// Each worker will create its own client to avoid connection pool contention
-func NewWorkerPool(clientConfig *bedrock.ClientConfig, metrics *Metrics, streaming bool, prompt string, workerCount int) *WorkerPool {
+// Each worker will create its own invoker to avoid connection pool contention
+func NewWorkerPool(newInvoker InvokerFactory, clientConfig *bedrock.ClientConfig, metrics *Metrics, streaming bool, prompt string, workerCount int) *WorkerPool {
 	return &WorkerPool{
+		newInvoker:   newInvoker,
 		clientConfig: clientConfig,
 		metrics:      metrics,
 		streaming:    streaming,
@@ -48,12 +54,15 @@ func (wp *WorkerPool) Stop() {
 }
 
 // worker is the main worker loop
-// Each worker creates its own client to avoid connection pool bottleneck
+// Each worker creates its own invoker to avoid connection pool bottleneck
 func (wp *WorkerPool) worker(ctx context.Context, workerID int) {
 	defer wp.wg.Done()
 
-	// Create a dedicated client for this worker to avoid connection pool contention
-	client := bedrock.NewClientFromConfig(wp.clientConfig)
+	// Create a dedicated invoker for this worker to avoid connection pool contention
+	client := wp.newInvoker(wp.clientConfig)
+
+	varySuffix := wp.clientConfig.SharedPrefix != ""
+	requestNum := 0
 
 	for {
 		// Check if we should stop before starting a new request
@@ -68,11 +77,26 @@ func (wp *WorkerPool) worker(ctx context.Context, workerID int) {
 		// Execute one request with independent context
 		// Use context.Background() so the request won't be canceled by test timeout
 		// This allows in-flight requests to complete naturally even after test window expires
+		requestNum++
+		prompt := requestPrompt(wp.prompt, varySuffix, workerID, requestNum)
+
 		var result *bedrock.InvokeResult
 		if wp.streaming {
-			result = client.InvokeStreaming(context.Background(), wp.prompt)
+			result = client.InvokeStreaming(context.Background(), prompt)
 		} else {
-			result = client.InvokeNonStreaming(context.Background(), wp.prompt)
+			result = client.InvokeNonStreaming(context.Background(), prompt)
+		}
+
+		// Answer tool calls with the canned result and time the final turn
+		if wp.clientConfig.ToolResult != "" && result.Success && len(result.ToolCalls) > 0 {
+			result.FollowUp = client.InvokeToolFollowUp(context.Background(), prompt, result, wp.streaming)
+		}
+
+		// Check the content of successful responses; a failed check is a
+		// quality failure, not an API error
+		if wp.validator != nil && result.Success {
+			result.Validated = true
+			result.QualityFailure, result.QualityDetail = wp.validator.Check(result)
 		}
 
 		// Record the result
@@ -80,7 +104,20 @@ func (wp *WorkerPool) worker(ctx context.Context, workerID int) {
 	}
 }
 
-// GeneratePrompt generates a prompt of approximately the specified size
+// requestPrompt returns the prompt of a worker's nth request. With a shared
+// prefix every request gets a distinct suffix so only the prefix can be
+// served from the prompt cache.
+func requestPrompt(prompt string, varySuffix bool, workerID, n int) string {
+	if !varySuffix {
+		return prompt
+	}
+	return fmt.Sprintf("[Request %d-%d] %s", workerID, n, prompt)
+}
+
+// GeneratePrompt generates a prompt of about size characters. Short
+// templates are padded with whole sentences and long ones are cut at a word
+// boundary; sizes count runes, so non-ASCII templates are never split
+// inside a character.
 func GeneratePrompt(template string, size int) string {
 	if template == "" {
 		template = "Please write a detailed explanation about artificial intelligence, " +
@@ -91,19 +128,42 @@ func GeneratePrompt(template string, size int) string {
 	// Replace {size} placeholder if present
 	prompt := strings.ReplaceAll(template, "{size}", fmt.Sprintf("%d", size))
 
-	// If the prompt is already large enough, return it
-	if len(prompt) >= size {
-		return prompt[:size]
+	// Pad the prompt to reach the desired size
+	if length := utf8.RuneCountInString(prompt); length < size {
+		sentence := "Please provide more detailed information. "
+		prompt = prompt + " " + strings.Repeat(sentence, (size-length)/len(sentence)+1)
 	}
 
-	// Pad the prompt to reach the desired size
-	padding := strings.Repeat("Please provide more detailed information. ", (size-len(prompt))/45+1)
-	prompt = prompt + " " + padding
+	return truncateAtWord(prompt, size)
+}
 
-	// Trim to exact size
-	if len(prompt) > size {
-		prompt = prompt[:size]
+// truncateAtWord cuts s to at most size runes. The cut is moved back to the
+// last space when one is in the second half, so words are not split; text
+// without spaces (e.g. Chinese) is cut at the rune.
+func truncateAtWord(s string, size int) string {
+	runes := []rune(s)
+	if len(runes) <= size {
+		return strings.TrimRightFunc(s, unicode.IsSpace)
 	}
+	cut := size
+	if !unicode.IsSpace(runes[size]) {
+		for i := size - 1; i > size/2; i-- {
+			if unicode.IsSpace(runes[i]) {
+				cut = i
+				break
+			}
+		}
+	}
+	return strings.TrimRightFunc(string(runes[:cut]), unicode.IsSpace)
+}
+
+// GenerateSharedPrefix generates a reference document of approximately size
+// characters to use as the cached prefix when none is configured
+func GenerateSharedPrefix(size int) string {
+	paragraph := "Reference document. The following background material describes the history, " +
+		"architecture and operation of large language model services, including request routing, " +
+		"batching, capacity planning, token accounting and latency characteristics. "
 
-	return prompt
+	prefix := strings.Repeat(paragraph, size/len(paragraph)+1)
+	return prefix[:size]
 }
@@ -0,0 +1,167 @@
+package benchmark
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"bedrock-performance/internal/bedrock"
+	"bedrock-performance/internal/config"
+	"bedrock-performance/internal/validation"
+)
+
+func TestGeneratePrompt(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		size     int
+	}{
+		{"default template padded", "", 1000},
+		{"default template truncated", "", 20},
+		{"custom template", "Write {size} characters about Go.", 300},
+		{"non-ASCII template", "请详细介绍人工智能的历史、应用和未来前景。", 10},
+		{"non-ASCII words", "Écrivez une explication détaillée sur l'intelligence artificielle.", 30},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prompt := GeneratePrompt(tt.template, tt.size)
+			length := utf8.RuneCountInString(prompt)
+			if length > tt.size || length < tt.size*2/3 {
+				t.Errorf("GeneratePrompt has %d characters, want at most and close to %d", length, tt.size)
+			}
+			if !utf8.ValidString(prompt) {
+				t.Errorf("GeneratePrompt split a character: %q", prompt)
+			}
+			if strings.TrimSpace(prompt) != prompt {
+				t.Errorf("GeneratePrompt has surrounding whitespace: %q", prompt)
+			}
+		})
+	}
+
+	prompt := GeneratePrompt("Write {size} characters about Go.", 300)
+	if !strings.HasPrefix(prompt, "Write 300 characters about Go.") {
+		t.Errorf("GeneratePrompt did not substitute {size}: %q", prompt[:40])
+	}
+	if prompt := GeneratePrompt("", 20); prompt != "Please write a" {
+		t.Errorf("GeneratePrompt(20) = %q, want it cut at a word boundary", prompt)
+	}
+}
+
+func TestGenerateSharedPrefix(t *testing.T) {
+	for _, size := range []int{10, 5000} {
+		if got := len(GenerateSharedPrefix(size)); got != size {
+			t.Errorf("len(GenerateSharedPrefix(%d)) = %d", size, got)
+		}
+	}
+}
+
+// runPool runs a worker pool against fake for the given time
+func runPool(fake *FakeInvoker, clientConfig *bedrock.ClientConfig, streaming bool, workers int, d time.Duration) *Metrics {
+	metrics := NewMetrics()
+	pool := NewWorkerPool(fake.Factory(), clientConfig, metrics, streaming, "prompt", workers)
+
+	ctx, cancel := context.WithTimeout(context.Background(), d)
+	defer cancel()
+	pool.Start(ctx)
+	<-ctx.Done()
+	pool.Stop()
+	metrics.Finalize()
+	return metrics
+}
+
+func TestWorkerPoolRecordsScriptedResults(t *testing.T) {
+	fake := NewFakeInvoker(
+		FakeResponse{Latency: 5 * time.Millisecond, TTFT: time.Millisecond, InputTokens: 20, OutputTokens: 10},
+		FakeResponse{Latency: 5 * time.Millisecond, Err: errors.New("throttled"), ErrorType: "ThrottlingError", StatusCode: 429},
+	)
+
+	stats := runPool(fake, &bedrock.ClientConfig{}, true, 2, 100*time.Millisecond).ComputeStats()
+
+	if stats.TotalRequests == 0 || stats.TotalRequests != fake.Calls() {
+		t.Fatalf("TotalRequests = %d, fake calls = %d", stats.TotalRequests, fake.Calls())
+	}
+	if fake.StreamingCalls() != fake.Calls() {
+		t.Errorf("StreamingCalls = %d, want all %d calls", fake.StreamingCalls(), fake.Calls())
+	}
+	if stats.SuccessCount == 0 || stats.ErrorsByType["ThrottlingError"] == 0 {
+		t.Errorf("success/throttled = %d/%d, want both", stats.SuccessCount, stats.ErrorsByType["ThrottlingError"])
+	}
+	if stats.FailedAtRequest != stats.FailureCount {
+		t.Errorf("FailedAtRequest = %d, want %d", stats.FailedAtRequest, stats.FailureCount)
+	}
+	if !stats.HasTTFT || stats.P50TTFT != 1 {
+		t.Errorf("P50TTFT = %v, want 1ms", stats.P50TTFT)
+	}
+}
+
+func TestWorkerPoolVariesPromptWithSharedPrefix(t *testing.T) {
+	fake := NewFakeInvoker(FakeResponse{Latency: time.Millisecond, OutputTokens: 1})
+	runPool(fake, &bedrock.ClientConfig{SharedPrefix: "prefix"}, false, 1, 20*time.Millisecond)
+
+	prompts := fake.Prompts()
+	if len(prompts) < 2 {
+		t.Fatalf("got %d prompts, want at least 2", len(prompts))
+	}
+	if prompts[0] == prompts[1] || !strings.HasSuffix(prompts[0], "prompt") {
+		t.Errorf("prompts %q and %q should differ only in their request prefix", prompts[0], prompts[1])
+	}
+}
+
+func TestWorkerPoolRunsToolFollowUp(t *testing.T) {
+	fake := NewFakeInvoker(
+		FakeResponse{Latency: time.Millisecond, OutputTokens: 5, ToolCalls: []bedrock.ToolCall{{ID: "t1", Name: "get_weather", Input: `{"city":"Paris"}`}}},
+		FakeResponse{Latency: 2 * time.Millisecond, OutputTokens: 8},
+	)
+
+	stats := runPool(fake, &bedrock.ClientConfig{ToolResult: `{"temperature": 21}`}, false, 1, 50*time.Millisecond).ComputeStats()
+
+	if !stats.HasToolUse || stats.ToolCallRequests == 0 {
+		t.Fatalf("HasToolUse = %v, ToolCallRequests = %d", stats.HasToolUse, stats.ToolCallRequests)
+	}
+	if stats.FollowUps != stats.ToolCallRequests {
+		t.Errorf("FollowUps = %d, want one per tool call request (%d)", stats.FollowUps, stats.ToolCallRequests)
+	}
+	// Follow-up turns are not counted as benchmark requests
+	if stats.TotalRequests != stats.ToolCallRequests {
+		t.Errorf("TotalRequests = %d, want %d", stats.TotalRequests, stats.ToolCallRequests)
+	}
+}
+
+func TestWorkerPoolValidatesContent(t *testing.T) {
+	fake := NewFakeInvoker(
+		FakeResponse{Latency: time.Millisecond, InputTokens: 10, OutputTokens: 5, Content: `{"ok": true}`},
+		FakeResponse{Latency: time.Millisecond, InputTokens: 10, OutputTokens: 5, Content: "not json"},
+		FakeResponse{Latency: time.Millisecond, InputTokens: 10, OutputTokens: 100, Content: `{"ok":`, StopReason: "max_tokens"},
+	)
+	validator, err := validation.New(config.ValidationConfig{JSON: true, RejectMaxTokens: true}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	metrics := NewMetrics()
+	pool := NewWorkerPool(fake.Factory(), &bedrock.ClientConfig{}, metrics, false, "prompt", 1)
+	pool.validator = validator
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	pool.Start(ctx)
+	<-ctx.Done()
+	pool.Stop()
+	metrics.Finalize()
+	stats := metrics.ComputeStats()
+
+	if !stats.HasValidation || stats.QualityFailures+stats.ValidCount != stats.SuccessCount {
+		t.Fatalf("valid %d + quality failures %d != successes %d", stats.ValidCount, stats.QualityFailures, stats.SuccessCount)
+	}
+	if stats.QualityFailuresByCheck[validation.CheckJSON] == 0 || stats.QualityFailuresByCheck[validation.CheckMaxTokens] == 0 {
+		t.Errorf("QualityFailuresByCheck = %v, want json and max_tokens failures", stats.QualityFailuresByCheck)
+	}
+	if stats.QualityFailureSamples[validation.CheckJSON] == "" {
+		t.Errorf("no sample recorded for json failures")
+	}
+	if stats.GoodputRequestsPerSecond <= 0 || stats.GoodputRequestsPerSecond >= stats.RequestsPerSecond {
+		t.Errorf("goodput %.1f req/s, want between 0 and %.1f", stats.GoodputRequestsPerSecond, stats.RequestsPerSecond)
+	}
+}
@@ -3,7 +3,9 @@ package config
 import (
 	"encoding/json"
 	"fmt"
+	"net/url"
 	"os"
+	"regexp"
 )
 
 // Config represents the complete configuration for the benchmark tool
@@ -13,6 +15,9 @@ type Config struct {
 	Test        TestConfig        `json:"test"`
 	Concurrency ConcurrencyConfig `json:"concurrency"`
 	Output      OutputConfig      `json:"output"`
+	Recording   RecordingConfig   `json:"recording"`
+	Faults      FaultsConfig      `json:"faults"`
+	Validation  ValidationConfig  `json:"validation"`
 }
 
 // AWSConfig contains AWS credentials and region
@@ -20,31 +25,194 @@ type AWSConfig struct {
 	Region          string `json:"region"`
 	AccessKeyID     string `json:"access_key_id"`
 	SecretAccessKey string `json:"secret_access_key"`
+	// EndpointURL overrides the regional endpoint, e.g. a VPC interface
+	// endpoint or a local stand-in server such as http://localhost:8080
+	EndpointURL string `json:"endpoint_url"`
+	// UseFIPS and UseDualStack select the FIPS and dual-stack (IPv6) regional endpoints
+	UseFIPS      bool `json:"use_fips"`
+	UseDualStack bool `json:"use_dualstack"`
+	// Anonymous sends unsigned requests; loopback endpoints without keys use
+	// dummy credentials automatically
+	Anonymous bool `json:"anonymous"`
+	// MaxAttempts is the number of SDK attempts per request including the
+	// first (1 disables retries, 0 keeps the SDK default of 3)
+	MaxAttempts int `json:"max_attempts"`
+	// RetryMode is "standard" or "adaptive" (client-side rate limiting on
+	// throttles); empty keeps the SDK default
+	RetryMode string `json:"retry_mode"`
+	// MaxBackoffMs caps the delay between retries (0 keeps the SDK default of 20s)
+	MaxBackoffMs int `json:"max_backoff_ms"`
+	// RequestTimeoutMs bounds each request, retries and stream reading
+	// included (0 for no timeout)
+	RequestTimeoutMs int `json:"request_timeout_ms"`
 }
 
 // ModelConfig contains Bedrock model configuration
 type ModelConfig struct {
 	ID    string `json:"id"`
 	Quota int    `json:"quota"`
+	// Format overrides the request/response format detected from the model ID
+	// (e.g. "claude", "llama"); required for ARNs and custom imported models
+	Format string `json:"format"`
+	// CompareIDs lists additional model IDs, inference profile IDs or ARNs
+	// (e.g. the cross-region profile or provisioned throughput of the same
+	// model) to benchmark under identical load in the same report
+	CompareIDs []string `json:"compare_ids"`
+	// Pricing is used to estimate the cost of each run (optional)
+	Pricing PricingConfig `json:"pricing"`
+}
+
+// PricingConfig contains on-demand token prices used for cost estimates
+type PricingConfig struct {
+	InputPerMillion  float64 `json:"input_per_million"`
+	OutputPerMillion float64 `json:"output_per_million"`
+	// TierMultipliers scales the on-demand price per service tier; priority
+	// and flex default to 1.75 and 0.5 when omitted
+	TierMultipliers map[string]float64 `json:"tier_multipliers"`
+	// LatencyOptimizedMultiplier scales the price of latency-optimized
+	// inference; defaults to 1.25 when omitted
+	LatencyOptimizedMultiplier float64 `json:"latency_optimized_multiplier"`
+	// Prompt cache prices; default to 0.1x and 1.25x the input price when omitted
+	CacheReadPerMillion  float64 `json:"cache_read_per_million"`
+	CacheWritePerMillion float64 `json:"cache_write_per_million"`
 }
 
 // TestConfig contains test parameters
 type TestConfig struct {
-	PromptSize     int     `json:"prompt_size"`
-	PromptTemplate string  `json:"prompt_template"`
-	Streaming      bool    `json:"streaming"`
-	NonStreaming   bool    `json:"non_streaming"`
-	MaxTokens      int     `json:"max_tokens"`
-	Temperature    float64 `json:"temperature"`
-	ServiceTier    string  `json:"service_tier"`
+	PromptSize     int    `json:"prompt_size"`
+	PromptTemplate string `json:"prompt_template"`
+	// PromptSizeTokens sizes the prompt by input tokens instead of
+	// characters: probe requests scale the generated text until the reported
+	// input tokens are within PromptTokenTolerance percent of it. It replaces
+	// PromptSize when set.
+	PromptSizeTokens     int     `json:"prompt_size_tokens"`
+	PromptTokenTolerance float64 `json:"prompt_token_tolerance"`
+	// CalibrationFile keeps calibrated prompt sizes per model between runs
+	CalibrationFile string  `json:"calibration_file"`
+	Streaming       bool    `json:"streaming"`
+	NonStreaming    bool    `json:"non_streaming"`
+	MaxTokens       int     `json:"max_tokens"`
+	Temperature     float64 `json:"temperature"`
+	// SystemPrompt is sent with every request; SystemPromptFile reads it from a file instead
+	SystemPrompt     string `json:"system_prompt"`
+	SystemPromptFile string `json:"system_prompt_file"`
+	// Optional sampling settings; zero values leave the model default
+	TopP          float64  `json:"top_p"`
+	TopK          int      `json:"top_k"`
+	StopSequences []string `json:"stop_sequences"`
+	// Prefill starts the assistant turn with this text
+	Prefill string `json:"prefill"`
+	// ThinkingBudget enables extended thinking with this many tokens (0 disables it)
+	ThinkingBudget int `json:"thinking_budget"`
+	// ThinkingBudgets runs every listed budget (0 for thinking off) under
+	// identical load for comparison; when empty, ThinkingBudget alone is used
+	ThinkingBudgets []int `json:"thinking_budgets"`
+	// ReasoningEffort is "low", "medium" or "high" for reasoning models that
+	// take an effort level instead of a budget (gpt-oss); ReasoningEfforts
+	// runs every listed level under identical load for comparison
+	ReasoningEffort  string   `json:"reasoning_effort"`
+	ReasoningEfforts []string `json:"reasoning_efforts"`
+	ServiceTier      string   `json:"service_tier"`
+	// ServiceTiers runs every listed tier under identical load for comparison;
+	// when empty, ServiceTier alone is used
+	ServiceTiers []string `json:"service_tiers"`
+	// PerformanceLatency is "standard" or "optimized" for latency-optimized
+	// inference on models that support it
+	PerformanceLatency string `json:"performance_latency"`
+	// PerformanceLatencies runs every listed latency mode under identical
+	// load for comparison; when empty, PerformanceLatency alone is used
+	PerformanceLatencies []string `json:"performance_latencies"`
+	// API selects the invocation path: "invoke" (default), "converse", or
+	// "both" to run Invoke and Converse under identical load for comparison
+	API string `json:"api"`
+	// PromptCaching sends a shared prefix with a cache breakpoint ahead of a
+	// per-request suffix
+	PromptCaching PromptCachingConfig `json:"prompt_caching"`
+	// ToolUse attaches tool definitions to every request to measure function calling latency
+	ToolUse ToolUseConfig `json:"tool_use"`
+	// Multimodal attaches images and documents to every request
+	Multimodal MultimodalConfig `json:"multimodal"`
+	// CountTokens counts input tokens with the CountTokens API before the run
+	CountTokens CountTokensConfig `json:"count_tokens"`
+}
+
+// CountTokensConfig configures the CountTokens preflight
+type CountTokensConfig struct {
+	// Enabled counts the input tokens of each distinct request before the
+	// run, compares them with the input tokens reported by responses, and
+	// calibrates prompt_size_tokens without generation probes
+	Enabled bool `json:"enabled"`
+	// TolerancePercent is the difference between counted and reported input
+	// tokens above which a variant is flagged (default 2)
+	TolerancePercent float64 `json:"tolerance_percent"`
+}
+
+// MultimodalConfig configures image and document inputs
+type MultimodalConfig struct {
+	// Images are local jpeg, png, gif or webp files, attached in order and
+	// reused from the start when a request needs more images than files
+	Images []string `json:"images"`
+	// ImageCounts runs every listed number of images per request for
+	// comparison; defaults to one image per request
+	ImageCounts []int `json:"image_counts"`
+	// Resolutions runs every listed longest image side in pixels for
+	// comparison; 0 sends the files unchanged and is the default
+	Resolutions []int `json:"resolutions"`
+	// Documents are local files (e.g. PDF) attached to every request
+	Documents []string `json:"documents"`
+}
+
+// ToolUseConfig configures the tool use (function calling) benchmark
+type ToolUseConfig struct {
+	Enabled bool `json:"enabled"`
+	// ToolsFile is a JSON array of tool definitions in the Anthropic format
+	// ([{"name", "description", "input_schema"}]); a built-in get_weather
+	// tool is used when it is empty
+	ToolsFile string `json:"tools_file"`
+	// ToolChoice is "auto" (default), "any", or the name of a tool the model must call
+	ToolChoice string `json:"tool_choice"`
+	// SecondTurn sends ToolResult back for every tool call and times the final answer
+	SecondTurn bool   `json:"second_turn"`
+	ToolResult string `json:"tool_result"`
+}
+
+// ToolDefinition is one tool offered to the model
+type ToolDefinition struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	InputSchema json.RawMessage `json:"input_schema"`
+}
+
+// defaultTool is offered when tool use is enabled without a tools file
+var defaultTool = ToolDefinition{
+	Name:        "get_weather",
+	Description: "Get the current weather for a city.",
+	InputSchema: json.RawMessage(`{"type":"object","properties":{"city":{"type":"string","description":"City name"},"unit":{"type":"string","enum":["celsius","fahrenheit"]}},"required":["city"]}`),
+}
+
+// PromptCachingConfig configures the prompt caching benchmark
+type PromptCachingConfig struct {
+	Enabled bool `json:"enabled"`
+	// Prefix is the shared system prompt or document; PrefixFile reads it from
+	// a file instead. When both are empty a prefix of PrefixSize characters is generated.
+	Prefix     string `json:"prefix"`
+	PrefixFile string `json:"prefix_file"`
+	PrefixSize int    `json:"prefix_size"`
+	// CompareDisabled also runs every variant with the same prefix but no
+	// cache breakpoint, as the caching-disabled baseline
+	CompareDisabled bool `json:"compare_disabled"`
 }
 
 // ConcurrencyConfig defines the concurrency test parameters
 type ConcurrencyConfig struct {
-	Start            int `json:"start"`
-	End              int `json:"end"`
-	Step             int `json:"step"`
-	DurationSeconds  int `json:"duration_seconds"`
+	Start           int `json:"start"`
+	End             int `json:"end"`
+	Step            int `json:"step"`
+	DurationSeconds int `json:"duration_seconds"`
+	// Repetitions is the number of times each concurrency level is run (default 1)
+	Repetitions int `json:"repetitions"`
+	// RandomizeOrder shuffles the order of levels within each repetition
+	RandomizeOrder bool `json:"randomize_order"`
 }
 
 // OutputConfig defines output settings
@@ -52,6 +220,253 @@ type OutputConfig struct {
 	ReportFile string `json:"report_file"`
 }
 
+// RecordingConfig saves the traffic of a run or replays a saved run
+type RecordingConfig struct {
+	// Mode is "record" to save every request and response (with stream chunk
+	// timing) to File, "replay" to serve responses from File with their
+	// original timing instead of calling Bedrock, or empty for neither
+	Mode string `json:"mode"`
+	File string `json:"file"`
+}
+
+// ValidationConfig checks the content of successful responses. Responses
+// that fail a check are counted as quality failures and excluded from goodput.
+type ValidationConfig struct {
+	// NonEmpty requires some non-whitespace text
+	NonEmpty bool `json:"non_empty"`
+	// MinLength and MaxLength bound the response length in characters (0 for no bound)
+	MinLength int `json:"min_length"`
+	MaxLength int `json:"max_length"`
+	// Contains lists substrings that must all appear in the response
+	Contains []string `json:"contains"`
+	// Regex must match somewhere in the response
+	Regex string `json:"regex"`
+	// JSON requires the response to be a JSON document (a surrounding
+	// markdown code fence is allowed); JSONSchemaFile additionally validates
+	// it against a JSON Schema
+	JSON           bool   `json:"json"`
+	JSONSchemaFile string `json:"json_schema_file"`
+	// RejectMaxTokens fails responses that stopped at max_tokens (truncated)
+	RejectMaxTokens bool `json:"reject_max_tokens"`
+}
+
+// Enabled reports whether any content check is configured
+func (v *ValidationConfig) Enabled() bool {
+	return v.NonEmpty || v.MinLength > 0 || v.MaxLength > 0 || len(v.Contains) > 0 || v.Regex != "" ||
+		v.JSON || v.JSONSchemaFile != "" || v.RejectMaxTokens
+}
+
+// FaultsConfig injects failures between the SDK and the network to test how
+// retry and timeout settings hold up
+type FaultsConfig struct {
+	Rules []FaultRule `json:"rules"`
+	// Seed makes the injected faults repeatable (0 picks a random seed)
+	Seed int64 `json:"seed"`
+}
+
+// FaultRule injects one kind of fault into a share of HTTP attempts
+type FaultRule struct {
+	// Type is "latency" (delay the response), "throttle" (429
+	// ThrottlingException), "server_error" (500 or 503) or "drop" (reset the
+	// connection while the response body is being read)
+	Type string `json:"type"`
+	// Rate is the probability that the rule fires on an attempt
+	Rate float64 `json:"rate"`
+	// StartSeconds and EndSeconds limit the rule to a window measured from
+	// the start of each concurrency level (EndSeconds 0 means no end)
+	StartSeconds float64 `json:"start_seconds"`
+	EndSeconds   float64 `json:"end_seconds"`
+	// LatencyMs is the delay added by latency rules
+	LatencyMs int `json:"latency_ms"`
+	// Status is the status code of server_error rules (500 or 503, default 500)
+	Status int `json:"status"`
+	// AfterMs is how long after the response headers a drop rule resets the
+	// connection; the first body read is always delivered
+	AfterMs int `json:"after_ms"`
+}
+
+// Enabled reports whether any fault rules are configured
+func (f *FaultsConfig) Enabled() bool {
+	return len(f.Rules) > 0
+}
+
+// Targets returns the model identifiers to benchmark, primary model first
+func (m *ModelConfig) Targets() []string {
+	return append([]string{m.ID}, m.CompareIDs...)
+}
+
+// Tiers returns the service tiers to benchmark, in run order
+func (t *TestConfig) Tiers() []string {
+	if len(t.ServiceTiers) > 0 {
+		return t.ServiceTiers
+	}
+	return []string{t.ServiceTier}
+}
+
+// Latencies returns the performance latency modes to benchmark, in run order
+func (t *TestConfig) Latencies() []string {
+	if len(t.PerformanceLatencies) > 0 {
+		return t.PerformanceLatencies
+	}
+	return []string{t.PerformanceLatency}
+}
+
+// Enabled reports whether token prices were configured
+func (p *PricingConfig) Enabled() bool {
+	return p.InputPerMillion > 0 || p.OutputPerMillion > 0
+}
+
+// EstimateCost returns the estimated USD cost of the given token counts on a service tier
+func (p *PricingConfig) EstimateCost(inputTokens, outputTokens int, tier string) float64 {
+	cost := (float64(inputTokens)*p.InputPerMillion + float64(outputTokens)*p.OutputPerMillion) / 1e6
+	return cost * p.tierMultiplier(tier)
+}
+
+// tierMultiplier returns the price multiplier for a service tier
+func (p *PricingConfig) tierMultiplier(tier string) float64 {
+	if m, ok := p.TierMultipliers[tier]; ok {
+		return m
+	}
+	switch tier {
+	case "priority":
+		return 1.75
+	case "flex":
+		return 0.5
+	default:
+		return 1.0
+	}
+}
+
+// LatencyMultiplier returns the price multiplier for a performance latency mode
+func (p *PricingConfig) LatencyMultiplier(latency string) float64 {
+	if latency != "optimized" {
+		return 1.0
+	}
+	if p.LatencyOptimizedMultiplier > 0 {
+		return p.LatencyOptimizedMultiplier
+	}
+	return 1.25
+}
+
+// CacheCost returns the estimated USD cost of prompt cache reads and writes on a service tier
+func (p *PricingConfig) CacheCost(readTokens, writeTokens int, tier string) float64 {
+	readPrice := p.CacheReadPerMillion
+	if readPrice == 0 {
+		readPrice = p.InputPerMillion * 0.1
+	}
+	writePrice := p.CacheWritePerMillion
+	if writePrice == 0 {
+		writePrice = p.InputPerMillion * 1.25
+	}
+	cost := (float64(readTokens)*readPrice + float64(writeTokens)*writePrice) / 1e6
+	return cost * p.tierMultiplier(tier)
+}
+
+// Budgets returns the thinking budgets to benchmark, in run order
+func (t *TestConfig) Budgets() []int {
+	if len(t.ThinkingBudgets) > 0 {
+		return t.ThinkingBudgets
+	}
+	return []int{t.ThinkingBudget}
+}
+
+// Efforts returns the reasoning efforts to benchmark, in run order ("" is the model default)
+func (t *TestConfig) Efforts() []string {
+	if len(t.ReasoningEfforts) > 0 {
+		return t.ReasoningEfforts
+	}
+	return []string{t.ReasoningEffort}
+}
+
+// CachingModes returns the prompt caching settings to benchmark, in run order
+func (t *TestConfig) CachingModes() []bool {
+	if !t.PromptCaching.Enabled {
+		return []bool{false}
+	}
+	if t.PromptCaching.CompareDisabled {
+		return []bool{false, true}
+	}
+	return []bool{true}
+}
+
+// SharedPrefix returns the configured prompt caching prefix, reading
+// PrefixFile if set; it is empty when prompt caching is disabled
+func (t *TestConfig) SharedPrefix() (string, error) {
+	c := t.PromptCaching
+	if !c.Enabled {
+		return "", nil
+	}
+	if c.PrefixFile != "" {
+		data, err := os.ReadFile(c.PrefixFile)
+		if err != nil {
+			return "", fmt.Errorf("failed to read test.prompt_caching.prefix_file: %w", err)
+		}
+		return string(data), nil
+	}
+	return c.Prefix, nil
+}
+
+// Tools returns the tool definitions to offer, reading ToolsFile if set; it
+// is empty when tool use is disabled
+func (t *TestConfig) Tools() ([]ToolDefinition, error) {
+	c := t.ToolUse
+	if !c.Enabled {
+		return nil, nil
+	}
+	if c.ToolsFile == "" {
+		return []ToolDefinition{defaultTool}, nil
+	}
+
+	data, err := os.ReadFile(c.ToolsFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read test.tool_use.tools_file: %w", err)
+	}
+	var tools []ToolDefinition
+	if err := json.Unmarshal(data, &tools); err != nil {
+		return nil, fmt.Errorf("failed to parse test.tool_use.tools_file: %w", err)
+	}
+	if len(tools) == 0 {
+		return nil, fmt.Errorf("test.tool_use.tools_file defines no tools")
+	}
+	for i, tool := range tools {
+		if tool.Name == "" {
+			return nil, fmt.Errorf("test.tool_use.tools_file: tool %d has no name", i)
+		}
+		if len(tool.InputSchema) == 0 {
+			return nil, fmt.Errorf("test.tool_use.tools_file: tool %s has no input_schema", tool.Name)
+		}
+	}
+	return tools, nil
+}
+
+// ImagesPerRequest returns the images per request to benchmark, in run order;
+// it is [0] when no images are configured
+func (m *MultimodalConfig) ImagesPerRequest() []int {
+	if len(m.Images) == 0 {
+		return []int{0}
+	}
+	if len(m.ImageCounts) > 0 {
+		return m.ImageCounts
+	}
+	return []int{1}
+}
+
+// ImageResolutions returns the image resolutions to benchmark, in run order
+func (m *MultimodalConfig) ImageResolutions() []int {
+	if len(m.Images) == 0 || len(m.Resolutions) == 0 {
+		return []int{0}
+	}
+	return m.Resolutions
+}
+
+// APIs returns the invocation APIs to benchmark, in run order
+func (t *TestConfig) APIs() []string {
+	if t.API == "both" {
+		return []string{"invoke", "converse"}
+	}
+	return []string{t.API}
+}
+
 // LoadConfig reads and parses the configuration file
 func LoadConfig(path string) (*Config, error) {
 	data, err := os.ReadFile(path)
@@ -64,6 +479,16 @@ func LoadConfig(path string) (*Config, error) {
 		return nil, fmt.Errorf("failed to parse config file: %w", err)
 	}
 
+	cfg.applyDefaults()
+
+	if cfg.Test.SystemPromptFile != "" {
+		data, err := os.ReadFile(cfg.Test.SystemPromptFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read test.system_prompt_file: %w", err)
+		}
+		cfg.Test.SystemPrompt = string(data)
+	}
+
 	// Validate configuration
 	if err := cfg.Validate(); err != nil {
 		return nil, fmt.Errorf("invalid configuration: %w", err)
@@ -72,6 +497,38 @@ func LoadConfig(path string) (*Config, error) {
 	return &cfg, nil
 }
 
+// applyDefaults fills in optional settings that were omitted from the file
+func (c *Config) applyDefaults() {
+	if c.Concurrency.Repetitions == 0 {
+		c.Concurrency.Repetitions = 1
+	}
+	if c.Test.API == "" {
+		c.Test.API = "invoke"
+	}
+	if c.Test.ServiceTier == "" {
+		c.Test.ServiceTier = "default"
+	}
+	if c.Test.PerformanceLatency == "" {
+		c.Test.PerformanceLatency = "standard"
+	}
+	if c.Test.PromptTokenTolerance == 0 {
+		c.Test.PromptTokenTolerance = 5
+	}
+	if c.Test.CountTokens.TolerancePercent == 0 {
+		c.Test.CountTokens.TolerancePercent = 2
+	}
+	if c.Test.PromptCaching.PrefixSize == 0 {
+		// Comfortably above the 1,024-2,048 token minimum cacheable prefix
+		c.Test.PromptCaching.PrefixSize = 12000
+	}
+	if c.Test.ToolUse.ToolChoice == "" {
+		c.Test.ToolUse.ToolChoice = "auto"
+	}
+	if c.Test.ToolUse.ToolResult == "" {
+		c.Test.ToolUse.ToolResult = `{"temperature": 21, "unit": "celsius", "conditions": "partly cloudy"}`
+	}
+}
+
 // Validate checks if the configuration is valid
 func (c *Config) Validate() error {
 	if c.AWS.Region == "" {
@@ -79,21 +536,129 @@ func (c *Config) Validate() error {
 	}
 	// access_key_id and secret_access_key are optional
 	// if empty, the SDK will use default credential chain
+	if c.AWS.EndpointURL != "" {
+		u, err := url.Parse(c.AWS.EndpointURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("aws.endpoint_url must be an absolute http or https URL")
+		}
+		if c.AWS.UseFIPS || c.AWS.UseDualStack {
+			return fmt.Errorf("aws.use_fips and aws.use_dualstack cannot be combined with aws.endpoint_url")
+		}
+	}
 	if c.Model.ID == "" {
 		return fmt.Errorf("model.id is required")
 	}
+	for i, id := range c.Model.CompareIDs {
+		if id == "" {
+			return fmt.Errorf("model.compare_ids[%d] must not be empty", i)
+		}
+	}
 	if c.Model.Quota <= 0 {
 		return fmt.Errorf("model.quota must be positive")
 	}
-	if c.Test.PromptSize <= 0 {
+	if c.Test.PromptSizeTokens < 0 {
+		return fmt.Errorf("test.prompt_size_tokens must not be negative")
+	}
+	if c.Test.PromptSize <= 0 && c.Test.PromptSizeTokens == 0 {
 		return fmt.Errorf("test.prompt_size must be positive")
 	}
+	if c.Test.PromptTokenTolerance <= 0 || c.Test.PromptTokenTolerance >= 100 {
+		return fmt.Errorf("test.prompt_token_tolerance must be between 0 and 100")
+	}
+	if c.Test.CountTokens.TolerancePercent < 0 {
+		return fmt.Errorf("test.count_tokens.tolerance_percent must not be negative")
+	}
 	if !c.Test.Streaming && !c.Test.NonStreaming {
 		return fmt.Errorf("at least one of streaming or non_streaming must be enabled")
 	}
+	switch c.Test.API {
+	case "invoke", "converse", "both":
+	default:
+		return fmt.Errorf("test.api must be one of invoke, converse, both")
+	}
+	for _, tier := range c.Test.Tiers() {
+		switch tier {
+		case "default", "priority", "flex":
+		default:
+			return fmt.Errorf("service tier %q must be one of default, priority, flex", tier)
+		}
+	}
+	for _, latency := range c.Test.Latencies() {
+		switch latency {
+		case "standard", "optimized":
+		default:
+			return fmt.Errorf("performance latency %q must be one of standard, optimized", latency)
+		}
+	}
+	if c.Model.Pricing.LatencyOptimizedMultiplier < 0 {
+		return fmt.Errorf("model.pricing.latency_optimized_multiplier must not be negative")
+	}
+	if c.Test.PromptCaching.Enabled && c.Test.PromptCaching.PrefixSize <= 0 {
+		return fmt.Errorf("test.prompt_caching.prefix_size must be positive")
+	}
+	if c.Test.TopP < 0 || c.Test.TopP > 1 {
+		return fmt.Errorf("test.top_p must be between 0 and 1")
+	}
+	if c.Test.TopK < 0 {
+		return fmt.Errorf("test.top_k must not be negative")
+	}
 	if c.Test.MaxTokens <= 0 {
 		return fmt.Errorf("test.max_tokens must be positive")
 	}
+	for _, budget := range c.Test.Budgets() {
+		if budget == 0 {
+			continue
+		}
+		if budget < 1024 {
+			return fmt.Errorf("thinking budget %d must be 0 (off) or at least 1024 tokens", budget)
+		}
+		if budget >= c.Test.MaxTokens {
+			return fmt.Errorf("thinking budget %d must be less than test.max_tokens", budget)
+		}
+		// Extended thinking runs at the default temperature without top_k or a prefill
+		if c.Test.Prefill != "" {
+			return fmt.Errorf("test.prefill is not supported with extended thinking")
+		}
+		if c.Test.TopK > 0 {
+			return fmt.Errorf("test.top_k is not supported with extended thinking")
+		}
+		if c.Test.Temperature != 0 && c.Test.Temperature != 1 {
+			return fmt.Errorf("test.temperature must be 0 (model default) or 1 with extended thinking")
+		}
+	}
+	for _, effort := range c.Test.Efforts() {
+		switch effort {
+		case "", "low", "medium", "high":
+		default:
+			return fmt.Errorf("reasoning effort %q must be one of low, medium, high", effort)
+		}
+		if effort != "" && (len(c.Test.Budgets()) > 1 || c.Test.Budgets()[0] > 0) {
+			return fmt.Errorf("test.reasoning_effort cannot be combined with a thinking budget")
+		}
+	}
+	for _, count := range c.Test.Multimodal.ImagesPerRequest() {
+		if count < 0 || (count == 0 && len(c.Test.Multimodal.Images) > 0) {
+			return fmt.Errorf("test.multimodal.image_counts must be positive")
+		}
+	}
+	for _, resolution := range c.Test.Multimodal.ImageResolutions() {
+		if resolution < 0 {
+			return fmt.Errorf("test.multimodal.resolutions must not be negative")
+		}
+	}
+	if c.Test.ToolUse.Enabled {
+		for _, budget := range c.Test.Budgets() {
+			if budget == 0 {
+				continue
+			}
+			if c.Test.ToolUse.ToolChoice != "auto" {
+				return fmt.Errorf("test.tool_use.tool_choice must be \"auto\" with extended thinking")
+			}
+			if c.Test.ToolUse.SecondTurn {
+				return fmt.Errorf("test.tool_use.second_turn is not supported with extended thinking")
+			}
+		}
+	}
 	if c.Concurrency.Start <= 0 {
 		return fmt.Errorf("concurrency.start must be positive")
 	}
@@ -106,9 +671,75 @@ func (c *Config) Validate() error {
 	if c.Concurrency.DurationSeconds <= 0 {
 		return fmt.Errorf("concurrency.duration_seconds must be positive")
 	}
+	if c.Concurrency.Repetitions <= 0 {
+		return fmt.Errorf("concurrency.repetitions must be positive")
+	}
 	if c.Output.ReportFile == "" {
 		return fmt.Errorf("output.report_file is required")
 	}
+	switch c.Recording.Mode {
+	case "":
+	case "record", "replay":
+		if c.Recording.File == "" {
+			return fmt.Errorf("recording.file is required when recording.mode is set")
+		}
+	default:
+		return fmt.Errorf("recording.mode must be record or replay")
+	}
+	if c.AWS.MaxAttempts < 0 {
+		return fmt.Errorf("aws.max_attempts must not be negative")
+	}
+	switch c.AWS.RetryMode {
+	case "", "standard", "adaptive":
+	default:
+		return fmt.Errorf("aws.retry_mode must be standard or adaptive")
+	}
+	if c.AWS.MaxBackoffMs < 0 || c.AWS.RequestTimeoutMs < 0 {
+		return fmt.Errorf("aws.max_backoff_ms and aws.request_timeout_ms must not be negative")
+	}
+	if c.Validation.MinLength < 0 || c.Validation.MaxLength < 0 {
+		return fmt.Errorf("validation.min_length and validation.max_length must not be negative")
+	}
+	if c.Validation.MaxLength > 0 && c.Validation.MaxLength < c.Validation.MinLength {
+		return fmt.Errorf("validation.max_length must be >= validation.min_length")
+	}
+	if c.Validation.Regex != "" {
+		if _, err := regexp.Compile(c.Validation.Regex); err != nil {
+			return fmt.Errorf("validation.regex is invalid: %w", err)
+		}
+	}
+	for i, rule := range c.Faults.Rules {
+		if err := rule.validate(); err != nil {
+			return fmt.Errorf("faults.rules[%d]: %w", i, err)
+		}
+	}
+
+	return nil
+}
 
+// validate checks a single fault rule
+func (r *FaultRule) validate() error {
+	switch r.Type {
+	case "latency":
+		if r.LatencyMs <= 0 {
+			return fmt.Errorf("latency_ms must be positive")
+		}
+	case "throttle", "drop":
+	case "server_error":
+		if r.Status != 0 && r.Status != 500 && r.Status != 503 {
+			return fmt.Errorf("status must be 500 or 503")
+		}
+	default:
+		return fmt.Errorf("type must be one of latency, throttle, server_error, drop")
+	}
+	if r.Rate <= 0 || r.Rate > 1 {
+		return fmt.Errorf("rate must be greater than 0 and at most 1")
+	}
+	if r.StartSeconds < 0 || r.EndSeconds < 0 || (r.EndSeconds > 0 && r.EndSeconds <= r.StartSeconds) {
+		return fmt.Errorf("end_seconds must be after start_seconds")
+	}
+	if r.AfterMs < 0 {
+		return fmt.Errorf("after_ms must not be negative")
+	}
 	return nil
 }
@@ -0,0 +1,190 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// validConfig returns a minimal configuration that passes validation
+func validConfig() *Config {
+	cfg := &Config{
+		AWS:   AWSConfig{Region: "us-east-1"},
+		Model: ModelConfig{ID: "anthropic.claude-3-haiku-20240307-v1:0", Quota: 100},
+		Test: TestConfig{
+			PromptSize: 100,
+			Streaming:  true,
+			MaxTokens:  2048,
+		},
+		Concurrency: ConcurrencyConfig{Start: 1, End: 4, Step: 1, DurationSeconds: 10},
+		Output:      OutputConfig{ReportFile: "report.md"},
+	}
+	cfg.applyDefaults()
+	return cfg
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(c *Config)
+		wantErr string
+	}{
+		{"valid", func(c *Config) {}, ""},
+		{"missing region", func(c *Config) { c.AWS.Region = "" }, "aws.region is required"},
+		{"missing model", func(c *Config) { c.Model.ID = "" }, "model.id is required"},
+		{"empty compare id", func(c *Config) { c.Model.CompareIDs = []string{""} }, "model.compare_ids[0]"},
+		{"token sized prompt", func(c *Config) {
+			c.Test.PromptSize = 0
+			c.Test.PromptSizeTokens = 1000
+		}, ""},
+		{"no prompt size", func(c *Config) { c.Test.PromptSize = 0 }, "test.prompt_size"},
+		{"token tolerance above 100", func(c *Config) { c.Test.PromptTokenTolerance = 150 }, "prompt_token_tolerance"},
+		{"negative count tolerance", func(c *Config) { c.Test.CountTokens.TolerancePercent = -1 }, "count_tokens.tolerance_percent"},
+		{"no mode", func(c *Config) { c.Test.Streaming = false }, "streaming or non_streaming"},
+		{"unknown api", func(c *Config) { c.Test.API = "grpc" }, "test.api"},
+		{"unknown tier", func(c *Config) { c.Test.ServiceTiers = []string{"default", "gold"} }, `service tier "gold"`},
+		{"unknown latency", func(c *Config) { c.Test.PerformanceLatencies = []string{"standard", "fast"} }, `performance latency "fast"`},
+		{"negative latency multiplier", func(c *Config) { c.Model.Pricing.LatencyOptimizedMultiplier = -1 }, "latency_optimized_multiplier"},
+		{"zero prefix size", func(c *Config) { c.Test.PromptCaching = PromptCachingConfig{Enabled: true} }, "prefix_size must be positive"},
+		{"top_p above 1", func(c *Config) { c.Test.TopP = 1.5 }, "test.top_p"},
+		{"small thinking budget", func(c *Config) { c.Test.ThinkingBudget = 512 }, "at least 1024"},
+		{"thinking budget above max_tokens", func(c *Config) { c.Test.ThinkingBudget = 4096 }, "less than test.max_tokens"},
+		{"unknown reasoning effort", func(c *Config) { c.Test.ReasoningEffort = "max" }, `reasoning effort "max"`},
+		{"effort with budget", func(c *Config) {
+			c.Test.ReasoningEfforts = []string{"low", "high"}
+			c.Test.ThinkingBudget = 1024
+		}, "cannot be combined"},
+		{"thinking budget off", func(c *Config) { c.Test.ThinkingBudgets = []int{0, 1024} }, ""},
+		{"prefill with thinking", func(c *Config) {
+			c.Test.ThinkingBudget = 1024
+			c.Test.Prefill = "{"
+		}, "test.prefill"},
+		{"top_k with thinking", func(c *Config) {
+			c.Test.ThinkingBudgets = []int{0, 1024}
+			c.Test.TopK = 40
+		}, "test.top_k"},
+		{"temperature with thinking", func(c *Config) {
+			c.Test.ThinkingBudget = 1024
+			c.Test.Temperature = 0.7
+		}, "test.temperature"},
+		{"default temperature with thinking", func(c *Config) {
+			c.Test.ThinkingBudget = 1024
+			c.Test.Temperature = 1
+		}, ""},
+		{"tool choice with thinking", func(c *Config) {
+			c.Test.ThinkingBudget = 1024
+			c.Test.ToolUse = ToolUseConfig{Enabled: true, ToolChoice: "any"}
+		}, "tool_choice"},
+		{"relative endpoint", func(c *Config) { c.AWS.EndpointURL = "localhost:8080" }, "aws.endpoint_url"},
+		{"endpoint with fips", func(c *Config) {
+			c.AWS.EndpointURL = "http://localhost:8080"
+			c.AWS.UseFIPS = true
+		}, "cannot be combined"},
+		{"negative image count", func(c *Config) {
+			c.Test.Multimodal = MultimodalConfig{Images: []string{"cat.png"}, ImageCounts: []int{-1}}
+		}, "image_counts"},
+		{"end before start", func(c *Config) { c.Concurrency.End = 0 }, "concurrency.end"},
+		{"zero step", func(c *Config) { c.Concurrency.Step = 0 }, "concurrency.step"},
+		{"zero duration", func(c *Config) { c.Concurrency.DurationSeconds = 0 }, "duration_seconds"},
+		{"missing report file", func(c *Config) { c.Output.ReportFile = "" }, "output.report_file"},
+		{"replay without file", func(c *Config) { c.Recording.Mode = "replay" }, "recording.file"},
+		{"unknown retry mode", func(c *Config) { c.AWS.RetryMode = "legacy" }, "aws.retry_mode"},
+		{"negative timeout", func(c *Config) { c.AWS.RequestTimeoutMs = -1 }, "request_timeout_ms"},
+		{"fault rules", func(c *Config) {
+			c.Faults.Rules = []FaultRule{
+				{Type: "throttle", Rate: 0.1, StartSeconds: 5, EndSeconds: 10},
+				{Type: "latency", Rate: 1, LatencyMs: 200},
+			}
+		}, ""},
+		{"unknown fault", func(c *Config) { c.Faults.Rules = []FaultRule{{Type: "corrupt", Rate: 0.1}} }, "faults.rules[0]: type"},
+		{"fault rate above 1", func(c *Config) { c.Faults.Rules = []FaultRule{{Type: "drop", Rate: 2}} }, "rate"},
+		{"fault window reversed", func(c *Config) {
+			c.Faults.Rules = []FaultRule{{Type: "throttle", Rate: 0.5, StartSeconds: 10, EndSeconds: 5}}
+		}, "end_seconds"},
+		{"server error status", func(c *Config) { c.Faults.Rules = []FaultRule{{Type: "server_error", Rate: 0.1, Status: 502}} }, "status"},
+		{"validation length bounds", func(c *Config) { c.Validation = ValidationConfig{MinLength: 100, MaxLength: 10} }, "validation.max_length"},
+		{"invalid validation regex", func(c *Config) { c.Validation.Regex = "(unclosed" }, "validation.regex"},
+		{"unknown recording mode", func(c *Config) { c.Recording = RecordingConfig{Mode: "capture", File: "x.jsonl"} }, "recording.mode"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validConfig()
+			tt.modify(cfg)
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Validate() = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadConfigAppliesDefaults(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	data := `{
+		"aws": {"region": "us-west-2"},
+		"model": {"id": "amazon.nova-lite-v1:0", "quota": 10},
+		"test": {"prompt_size": 50, "non_streaming": true, "max_tokens": 256},
+		"concurrency": {"start": 1, "end": 1, "step": 1, "duration_seconds": 5},
+		"output": {"report_file": "out.md"}
+	}`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig() = %v", err)
+	}
+	if cfg.Test.API != "invoke" {
+		t.Errorf("Test.API = %q, want invoke", cfg.Test.API)
+	}
+	if cfg.Test.ServiceTier != "default" {
+		t.Errorf("Test.ServiceTier = %q, want default", cfg.Test.ServiceTier)
+	}
+	if cfg.Test.PerformanceLatency != "standard" {
+		t.Errorf("Test.PerformanceLatency = %q, want standard", cfg.Test.PerformanceLatency)
+	}
+	if cfg.Concurrency.Repetitions != 1 {
+		t.Errorf("Concurrency.Repetitions = %d, want 1", cfg.Concurrency.Repetitions)
+	}
+}
+
+func TestLoadConfigRejectsInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"aws": {"region": "us-east-1"}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadConfig(path); err == nil || !strings.Contains(err.Error(), "invalid configuration") {
+		t.Fatalf("LoadConfig() = %v, want invalid configuration error", err)
+	}
+}
+
+func TestSweepDefaults(t *testing.T) {
+	cfg := validConfig()
+	if got := cfg.Test.Tiers(); len(got) != 1 || got[0] != "default" {
+		t.Errorf("Tiers() = %v, want [default]", got)
+	}
+	if got := cfg.Test.Latencies(); len(got) != 1 || got[0] != "standard" {
+		t.Errorf("Latencies() = %v, want [standard]", got)
+	}
+	if got := cfg.Test.Budgets(); len(got) != 1 || got[0] != 0 {
+		t.Errorf("Budgets() = %v, want [0]", got)
+	}
+	if got := cfg.Test.Multimodal.ImagesPerRequest(); len(got) != 1 || got[0] != 0 {
+		t.Errorf("ImagesPerRequest() without images = %v, want [0]", got)
+	}
+
+	cfg.Test.API = "both"
+	if got := cfg.Test.APIs(); len(got) != 2 || got[0] != "invoke" || got[1] != "converse" {
+		t.Errorf("APIs() = %v, want [invoke converse]", got)
+	}
+}
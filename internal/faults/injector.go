@@ -0,0 +1,283 @@
+// Package faults injects failures between the Bedrock SDK and the network
+// so that retry and timeout settings can be tested under controlled
+// conditions.
+//
+// Faults are injected per HTTP attempt at the http.RoundTripper, so the SDK
+// retries an injected throttle, server error or dropped connection exactly
+// as it would a real one.
+package faults
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Type is a kind of injected fault
+type Type string
+
+const (
+	// Latency delays the request before it is sent
+	Latency Type = "latency"
+	// Throttle answers with a 429 ThrottlingException instead of sending the request
+	Throttle Type = "throttle"
+	// ServerError answers with a 500 or 503 instead of sending the request
+	ServerError Type = "server_error"
+	// Drop resets the connection while the response body is being read
+	Drop Type = "drop"
+)
+
+// Rule injects one kind of fault into a share of attempts
+type Rule struct {
+	Type Type
+	// Rate is the probability that the rule fires on an attempt
+	Rate float64
+	// Start and End limit the rule to a window measured from the last
+	// Restart (End 0 means no end)
+	Start time.Duration
+	End   time.Duration
+	// Latency is the delay added by Latency rules
+	Latency time.Duration
+	// Status is the status code of ServerError rules (default 500)
+	Status int
+	// After is how long after the response headers a Drop rule resets the
+	// connection, at the next body read; the first read always gets through
+	After time.Duration
+}
+
+// active reports whether the rule applies at offset since from the window start
+func (r *Rule) active(since time.Duration) bool {
+	return since >= r.Start && (r.End == 0 || since < r.End)
+}
+
+// Injector decides which faults to inject into each attempt and counts the
+// faults it injected since the last Restart
+type Injector struct {
+	rules []Rule
+
+	mu     sync.Mutex
+	rng    *rand.Rand
+	epoch  time.Time
+	counts map[Type]int
+}
+
+// NewInjector creates an injector for rules; the same seed injects the
+// same sequence of faults into the same sequence of attempts
+func NewInjector(rules []Rule, seed int64) *Injector {
+	return &Injector{
+		rules:  rules,
+		rng:    rand.New(rand.NewSource(seed)),
+		epoch:  time.Now(),
+		counts: make(map[Type]int),
+	}
+}
+
+// Wrap returns a transport that injects faults into requests sent through base
+func (i *Injector) Wrap(base http.RoundTripper) http.RoundTripper {
+	return &transport{base: base, injector: i}
+}
+
+// Restart starts the rule windows over and clears the fault counts
+func (i *Injector) Restart() {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.epoch = time.Now()
+	i.counts = make(map[Type]int)
+}
+
+// Counts returns the number of faults injected since the last Restart, by type
+func (i *Injector) Counts() map[Type]int {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	counts := make(map[Type]int, len(i.counts))
+	for t, n := range i.counts {
+		counts[t] = n
+	}
+	return counts
+}
+
+// FormatCounts describes fault counts as "drop=2, throttle=5", or "none"
+func FormatCounts(counts map[Type]int) string {
+	var parts []string
+	for t, n := range counts {
+		parts = append(parts, fmt.Sprintf("%s=%d", t, n))
+	}
+	if len(parts) == 0 {
+		return "none"
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, ", ")
+}
+
+// plan is the set of faults chosen for one attempt
+type plan struct {
+	delay  time.Duration
+	reject *Rule
+	drop   *Rule
+}
+
+// decide rolls every active rule for one attempt. Latency rules add up; the
+// first throttle or server error rule that fires answers the attempt, and a
+// drop only applies to attempts that reach the server.
+func (i *Injector) decide() plan {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	var p plan
+	since := time.Since(i.epoch)
+	for k := range i.rules {
+		rule := &i.rules[k]
+		if !rule.active(since) || i.rng.Float64() >= rule.Rate {
+			continue
+		}
+		switch rule.Type {
+		case Latency:
+			p.delay += rule.Latency
+			i.counts[Latency]++
+		case Throttle, ServerError:
+			if p.reject == nil {
+				p.reject = rule
+				i.counts[rule.Type]++
+			}
+		case Drop:
+			if p.drop == nil {
+				p.drop = rule
+			}
+		}
+	}
+	if p.reject != nil {
+		p.drop = nil
+	}
+	return p
+}
+
+// count records an injected fault
+func (i *Injector) count(t Type) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.counts[t]++
+}
+
+// transport injects the faults the injector chooses into each attempt
+type transport struct {
+	base     http.RoundTripper
+	injector *Injector
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	p := t.injector.decide()
+
+	if p.delay > 0 {
+		if err := sleep(req.Context(), p.delay); err != nil {
+			closeBody(req)
+			return nil, err
+		}
+	}
+	if p.reject != nil {
+		closeBody(req)
+		return errorResponse(req, p.reject), nil
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil || p.drop == nil {
+		return resp, err
+	}
+	resp.Body = &dropBody{body: resp.Body, at: time.Now().Add(p.drop.After), injector: t.injector}
+	return resp, nil
+}
+
+// errorResponse builds the response Bedrock sends for a throttle or server error
+func errorResponse(req *http.Request, rule *Rule) *http.Response {
+	status, errorType, message := http.StatusTooManyRequests, "ThrottlingException", "Too many requests, please wait before trying again."
+	if rule.Type == ServerError {
+		status, errorType, message = http.StatusInternalServerError, "InternalServerException", "The server encountered an internal error."
+		if rule.Status == http.StatusServiceUnavailable {
+			status, errorType, message = http.StatusServiceUnavailable, "ServiceUnavailableException", "The service is temporarily unavailable."
+		}
+	}
+	body := fmt.Sprintf(`{"message":%q}`, message+" (injected fault)")
+
+	header := make(http.Header)
+	header.Set("Content-Type", "application/json")
+	header.Set("X-Amzn-ErrorType", errorType)
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		ContentLength: int64(len(body)),
+		Body:          io.NopCloser(strings.NewReader(body)),
+		Request:       req,
+	}
+}
+
+// errConnectionReset is the error a dropped connection returns, matching
+// what a real reset looks like to the SDK's retry classification
+var errConnectionReset = &net.OpError{Op: "read", Net: "tcp", Err: os.NewSyscallError("read", syscall.ECONNRESET)}
+
+// dropBody passes reads through until at, then fails the next read with a
+// connection reset; a body that reaches its end after at is reset instead of
+// ending. Bodies that end before at are not dropped.
+type dropBody struct {
+	body     io.ReadCloser
+	at       time.Time
+	injector *Injector
+	reads    int
+	dropped  bool
+}
+
+func (b *dropBody) Read(p []byte) (int, error) {
+	if b.dropped {
+		return 0, errConnectionReset
+	}
+	if b.reads > 0 && !time.Now().Before(b.at) {
+		return 0, b.drop()
+	}
+	b.reads++
+	n, err := b.body.Read(p)
+	if err == io.EOF && !time.Now().Before(b.at) {
+		return n, b.drop()
+	}
+	return n, err
+}
+
+// drop marks the body as reset and counts the fault
+func (b *dropBody) drop() error {
+	b.dropped = true
+	b.injector.count(Drop)
+	return errConnectionReset
+}
+
+func (b *dropBody) Close() error {
+	return b.body.Close()
+}
+
+// closeBody closes the body of a request that will not be sent
+func closeBody(req *http.Request) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+}
+
+// sleep waits for d, returning early with the context's error if ctx is done
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,137 @@
+package faults
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"bedrock-performance/internal/bedrock"
+	"bedrock-performance/internal/mock"
+)
+
+const testModel = "anthropic.claude-3-haiku-20240307-v1:0"
+
+// newClient returns a client for a fast mock Bedrock runtime that sends
+// every attempt through injector
+func newClient(t *testing.T, injector *Injector, maxAttempts int, timeout time.Duration) *bedrock.Client {
+	cfg := mock.DefaultConfig()
+	cfg.TTFT = mock.LatencyConfig{P50: 5, P99: 5}
+	cfg.TokensPerSecond = 2000
+	cfg.OutputTokens = mock.RangeConfig{Min: 40, Max: 40}
+	cfg.ChunkTokens = 4
+	cfg.Seed = 1
+	srv := httptest.NewServer(mock.NewServer(cfg))
+	t.Cleanup(srv.Close)
+
+	return bedrock.NewClientFromConfig(&bedrock.ClientConfig{
+		Region: "us-east-1", ModelID: testModel, MaxTokens: 100,
+		EndpointURL: srv.URL, WrapTransport: injector.Wrap,
+		MaxAttempts: maxAttempts, MaxBackoff: 5 * time.Millisecond, RequestTimeout: timeout,
+	})
+}
+
+func TestThrottleIsRetried(t *testing.T) {
+	injector := NewInjector([]Rule{{Type: Throttle, Rate: 1}}, 1)
+	result := newClient(t, injector, 3, 0).InvokeNonStreaming(context.Background(), "hello")
+
+	if result.Success || result.ErrorCode != "ThrottlingException" || result.HTTPStatusCode != 429 {
+		t.Fatalf("result = %v (%s, %d), want a throttle", result.Error, result.ErrorCode, result.HTTPStatusCode)
+	}
+	if result.Attempts != 3 {
+		t.Errorf("attempts = %d, want 3", result.Attempts)
+	}
+	if got := injector.Counts()[Throttle]; got != 3 {
+		t.Errorf("injected %d throttles, want 3", got)
+	}
+}
+
+func TestServerErrorStatus(t *testing.T) {
+	injector := NewInjector([]Rule{{Type: ServerError, Rate: 1, Status: 503}}, 1)
+	result := newClient(t, injector, 1, 0).InvokeStreaming(context.Background(), "hello")
+
+	if result.Success || result.HTTPStatusCode != 503 || result.ErrorCode != "ServiceUnavailableException" {
+		t.Fatalf("result = %v (%s, %d), want a 503", result.Error, result.ErrorCode, result.HTTPStatusCode)
+	}
+	if result.FailurePhase != bedrock.FailurePhaseRequest {
+		t.Errorf("failure phase = %q, want request", result.FailurePhase)
+	}
+}
+
+func TestWindow(t *testing.T) {
+	injector := NewInjector([]Rule{{Type: Throttle, Rate: 1, End: 100 * time.Millisecond}}, 1)
+	client := newClient(t, injector, 1, 0)
+
+	if result := client.InvokeNonStreaming(context.Background(), "hello"); result.Success {
+		t.Fatalf("request inside the window succeeded")
+	}
+	time.Sleep(100 * time.Millisecond)
+	if result := client.InvokeNonStreaming(context.Background(), "hello"); !result.Success {
+		t.Fatalf("request after the window failed: %v", result.Error)
+	}
+
+	injector.Restart()
+	if len(injector.Counts()) != 0 {
+		t.Errorf("Restart kept counts %v", injector.Counts())
+	}
+	if result := client.InvokeNonStreaming(context.Background(), "hello"); result.Success {
+		t.Errorf("request after Restart was outside the window")
+	}
+}
+
+func TestDropMidStream(t *testing.T) {
+	injector := NewInjector([]Rule{{Type: Drop, Rate: 1, After: 20 * time.Millisecond}}, 1)
+	result := newClient(t, injector, 3, 0).InvokeStreaming(context.Background(), "hello")
+
+	if result.Success || result.FailurePhase != bedrock.FailurePhaseMidStream {
+		t.Fatalf("result = %v (%q), want a mid-stream failure", result.Error, result.FailurePhase)
+	}
+	if result.Attempts != 1 {
+		t.Errorf("attempts = %d, want 1: streams are not retried once open", result.Attempts)
+	}
+	if got := injector.Counts()[Drop]; got != 1 {
+		t.Errorf("injected %d drops, want 1", got)
+	}
+}
+
+func TestDropIsRetriedBeforeResponse(t *testing.T) {
+	injector := NewInjector([]Rule{{Type: Drop, Rate: 1}}, 1)
+	result := newClient(t, injector, 2, 0).InvokeNonStreaming(context.Background(), "hello")
+
+	if result.Success || result.Attempts != 2 {
+		t.Fatalf("result = %v after %d attempts, want failure after 2", result.Error, result.Attempts)
+	}
+}
+
+func TestLatencyAndTimeout(t *testing.T) {
+	injector := NewInjector([]Rule{{Type: Latency, Rate: 1, Latency: 50 * time.Millisecond}}, 1)
+	result := newClient(t, injector, 1, 0).InvokeNonStreaming(context.Background(), "hello")
+	if !result.Success || result.Duration() < 50*time.Millisecond {
+		t.Fatalf("result = %v after %v, want success after at least 50ms", result.Error, result.Duration())
+	}
+
+	result = newClient(t, injector, 3, 20*time.Millisecond).InvokeNonStreaming(context.Background(), "hello")
+	if result.Success || result.ErrorType != "TimeoutError" {
+		t.Fatalf("result = %v (%s), want a timeout", result.Error, result.ErrorType)
+	}
+	if result.Duration() > 45*time.Millisecond {
+		t.Errorf("timed out request took %v", result.Duration())
+	}
+}
+
+func TestSeedRepeats(t *testing.T) {
+	rules := []Rule{{Type: Throttle, Rate: 0.3}, {Type: Latency, Rate: 0.5, Latency: time.Millisecond}}
+	first, second := NewInjector(rules, 42), NewInjector(rules, 42)
+	for i := 0; i < 100; i++ {
+		a, b := first.decide(), second.decide()
+		if a.delay != b.delay || (a.reject == nil) != (b.reject == nil) {
+			t.Fatalf("attempt %d differs between injectors with the same seed", i)
+		}
+	}
+	if FormatCounts(first.Counts()) != FormatCounts(second.Counts()) {
+		t.Errorf("counts %s != %s", FormatCounts(first.Counts()), FormatCounts(second.Counts()))
+	}
+	if FormatCounts(nil) != "none" {
+		t.Errorf("FormatCounts(nil) = %q", FormatCounts(nil))
+	}
+}
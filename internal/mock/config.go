@@ -0,0 +1,135 @@
+package mock
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Config controls the behaviour of the mock Bedrock runtime server
+type Config struct {
+	// Addr is the listen address (default ":8080")
+	Addr string `json:"addr"`
+	// Format is the model family served when the model ID does not identify
+	// one (e.g. application inference profile ARNs); defaults to "claude"
+	Format string `json:"format"`
+
+	// TTFT is the time to first token, drawn from a log-normal distribution
+	// with the given median and 99th percentile in milliseconds
+	TTFT LatencyConfig `json:"ttft_ms"`
+	// TokensPerSecond is the decode speed after the first token
+	TokensPerSecond float64 `json:"tokens_per_second"`
+	// OutputTokens is drawn uniformly from [Min, Max] and capped by the
+	// request's max tokens
+	OutputTokens RangeConfig `json:"output_tokens"`
+	// ChunkTokens is the number of tokens carried by each stream chunk
+	ChunkTokens int `json:"chunk_tokens"`
+
+	// ThrottleRate is the fraction of requests rejected with ThrottlingException
+	ThrottleRate float64 `json:"throttle_rate"`
+	// ErrorRate is the fraction of requests failing with InternalServerException
+	// or ServiceUnavailableException
+	ErrorRate float64 `json:"error_rate"`
+	// MidStreamErrorRate is the fraction of streams that end with a
+	// modelStreamErrorException after part of the output
+	MidStreamErrorRate float64 `json:"mid_stream_error_rate"`
+	// MaxRequestsPerSecond throttles requests above this rate (0 for no cap)
+	MaxRequestsPerSecond int `json:"max_requests_per_second"`
+
+	// Seed makes the random behaviour repeatable (0 seeds from the clock)
+	Seed int64 `json:"seed"`
+}
+
+// LatencyConfig describes a latency distribution in milliseconds
+type LatencyConfig struct {
+	P50 float64 `json:"p50"`
+	P99 float64 `json:"p99"`
+}
+
+// RangeConfig is an inclusive integer range
+type RangeConfig struct {
+	Min int `json:"min"`
+	Max int `json:"max"`
+}
+
+// DefaultConfig returns a configuration resembling a mid-sized model with no injected failures
+func DefaultConfig() *Config {
+	cfg := &Config{}
+	cfg.applyDefaults()
+	return cfg
+}
+
+// LoadConfig reads and parses a mock server configuration file
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	}
+
+	cfg.applyDefaults()
+
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	return &cfg, nil
+}
+
+// applyDefaults fills in optional settings that were omitted from the file
+func (c *Config) applyDefaults() {
+	if c.Addr == "" {
+		c.Addr = ":8080"
+	}
+	if c.Format == "" {
+		c.Format = "claude"
+	}
+	if c.TTFT.P50 == 0 {
+		c.TTFT.P50 = 400
+	}
+	if c.TTFT.P99 == 0 {
+		c.TTFT.P99 = c.TTFT.P50 * 3
+	}
+	if c.TokensPerSecond == 0 {
+		c.TokensPerSecond = 80
+	}
+	if c.OutputTokens.Max == 0 {
+		c.OutputTokens.Min, c.OutputTokens.Max = 200, 400
+	}
+	if c.ChunkTokens == 0 {
+		c.ChunkTokens = 3
+	}
+}
+
+// Validate checks if the configuration is valid
+func (c *Config) Validate() error {
+	if c.TTFT.P50 < 0 || c.TTFT.P99 < c.TTFT.P50 {
+		return fmt.Errorf("ttft_ms.p99 must be >= ttft_ms.p50 >= 0")
+	}
+	if c.TokensPerSecond <= 0 {
+		return fmt.Errorf("tokens_per_second must be positive")
+	}
+	if c.OutputTokens.Min < 1 || c.OutputTokens.Max < c.OutputTokens.Min {
+		return fmt.Errorf("output_tokens must satisfy 1 <= min <= max")
+	}
+	if c.ChunkTokens < 1 {
+		return fmt.Errorf("chunk_tokens must be positive")
+	}
+	for name, rate := range map[string]float64{
+		"throttle_rate":         c.ThrottleRate,
+		"error_rate":            c.ErrorRate,
+		"mid_stream_error_rate": c.MidStreamErrorRate,
+	} {
+		if rate < 0 || rate > 1 {
+			return fmt.Errorf("%s must be between 0 and 1", name)
+		}
+	}
+	if c.MaxRequestsPerSecond < 0 {
+		return fmt.Errorf("max_requests_per_second must not be negative")
+	}
+	return nil
+}
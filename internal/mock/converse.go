@@ -0,0 +1,123 @@
+package mock
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"bedrock-performance/internal/bedrock"
+)
+
+// converseInput is the part of a Converse request that is billed as input.
+// CountTokens takes the same fields, so both count the same tokens.
+type converseInput struct {
+	Messages   json.RawMessage `json:"messages,omitempty"`
+	System     json.RawMessage `json:"system,omitempty"`
+	ToolConfig json.RawMessage `json:"toolConfig,omitempty"`
+}
+
+// converseInputTokens estimates the input tokens of a Converse request or
+// CountTokens converse input from its messages, system prompt and tools
+func converseInputTokens(body []byte) int {
+	var input converseInput
+	if json.Unmarshal(body, &input) != nil {
+		return max(1, len(body)/4)
+	}
+	normalized, _ := json.Marshal(input)
+	return max(1, len(normalized)/4)
+}
+
+// converseLatency returns the latency mode a Converse request asked for; the
+// mock serves both at the same speed
+func converseLatency(body []byte) string {
+	var req struct {
+		PerformanceConfig struct {
+			Latency string `json:"latency"`
+		} `json:"performanceConfig"`
+	}
+	if json.Unmarshal(body, &req) != nil || req.PerformanceConfig.Latency == "" {
+		return string(bedrock.PerformanceLatencyStandard)
+	}
+	return req.PerformanceConfig.Latency
+}
+
+// converseUsage is the usage object of Converse responses and metadata events
+func converseUsage(gen *generation) obj {
+	return obj{"inputTokens": gen.inputTokens, "outputTokens": gen.outputTokens, "totalTokens": gen.inputTokens + gen.outputTokens}
+}
+
+// converse answers Converse after the full generation time. Converse has one
+// response shape for every model family.
+func (s *Server) converse(w http.ResponseWriter, r *http.Request, body []byte, gen *generation, ttft time.Duration, start time.Time) {
+	if !sleep(r, ttft+s.decodeTime(gen.outputTokens-1)) {
+		return
+	}
+
+	payload, err := json.Marshal(obj{
+		"output": obj{"message": obj{
+			"role":    "assistant",
+			"content": []obj{{"text": outputText(0, gen.outputTokens)}},
+		}},
+		"stopReason":        stopReason(gen, "end_turn", "max_tokens"),
+		"usage":             converseUsage(gen),
+		"metrics":           obj{"latencyMs": time.Since(start).Milliseconds()},
+		"performanceConfig": obj{"latency": converseLatency(body)},
+	})
+	if err != nil {
+		s.writeError(w, http.StatusInternalServerError, "InternalServerException", err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(payload)
+}
+
+// converseStream answers ConverseStream with messageStart, one
+// contentBlockDelta per text piece, contentBlockStop, messageStop and
+// metadata events, paced like stream. A mid-stream error stops after about
+// half of the output with a modelStreamErrorException.
+func (s *Server) converseStream(w http.ResponseWriter, r *http.Request, body []byte, gen *generation, ttft time.Duration, midStreamError bool, start time.Time) {
+	w.Header().Set("Content-Type", "application/vnd.amazon.eventstream")
+	w.WriteHeader(http.StatusOK)
+	events := newEventWriter(w)
+
+	send := func(eventType string, event obj) bool {
+		payload, err := json.Marshal(event)
+		return err == nil && events.event(eventType, payload) == nil
+	}
+
+	if !sleep(r, ttft) || !send("messageStart", obj{"role": "assistant"}) {
+		return
+	}
+
+	failAt := gen.outputTokens + 1
+	if midStreamError {
+		failAt = max(1, gen.outputTokens/2)
+	}
+
+	sent := 0
+	for index := 0; sent < gen.outputTokens; index++ {
+		if sent >= failAt {
+			events.exception("modelStreamErrorException", "The model stopped generating output unexpectedly.")
+			return
+		}
+		n := min(s.cfg.ChunkTokens, gen.outputTokens-sent)
+		if index > 0 && !sleep(r, s.decodeTime(n)) {
+			return
+		}
+		delta := obj{"contentBlockIndex": 0, "delta": obj{"text": outputText(sent, n)}}
+		sent += n
+		if !send("contentBlockDelta", delta) {
+			return
+		}
+	}
+
+	if !send("contentBlockStop", obj{"contentBlockIndex": 0}) ||
+		!send("messageStop", obj{"stopReason": stopReason(gen, "end_turn", "max_tokens")}) {
+		return
+	}
+	send("metadata", obj{
+		"usage":             converseUsage(gen),
+		"metrics":           obj{"latencyMs": time.Since(start).Milliseconds()},
+		"performanceConfig": obj{"latency": converseLatency(body)},
+	})
+}
@@ -0,0 +1,71 @@
+package mock
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/aws/aws-sdk-go-v2/aws/protocol/eventstream"
+	"github.com/aws/aws-sdk-go-v2/aws/protocol/eventstream/eventstreamapi"
+)
+
+// eventWriter writes InvokeModelWithResponseStream and ConverseStream events
+// with AWS eventstream framing
+type eventWriter struct {
+	w       io.Writer
+	encoder *eventstream.Encoder
+}
+
+func newEventWriter(w io.Writer) *eventWriter {
+	return &eventWriter{w: w, encoder: eventstream.NewEncoder()}
+}
+
+// chunk writes a PayloadPart event; Bedrock base64-encodes the model's JSON
+// chunk into the bytes field, which encoding/json does for []byte
+func (e *eventWriter) chunk(payload []byte) error {
+	body, err := json.Marshal(struct {
+		Bytes []byte `json:"bytes"`
+	}{payload})
+	if err != nil {
+		return err
+	}
+	return e.event("chunk", body)
+}
+
+// event writes an event of the given type with a JSON payload
+func (e *eventWriter) event(eventType string, payload []byte) error {
+	return e.write(eventstream.Message{
+		Headers: eventstream.Headers{
+			{Name: eventstreamapi.MessageTypeHeader, Value: eventstream.StringValue(eventstreamapi.EventMessageType)},
+			{Name: eventstreamapi.EventTypeHeader, Value: eventstream.StringValue(eventType)},
+			{Name: eventstreamapi.ContentTypeHeader, Value: eventstream.StringValue("application/json")},
+		},
+		Payload: payload,
+	})
+}
+
+// exception writes a modeled stream exception such as modelStreamErrorException
+func (e *eventWriter) exception(exceptionType, message string) error {
+	body, err := json.Marshal(obj{"message": message})
+	if err != nil {
+		return err
+	}
+	return e.write(eventstream.Message{
+		Headers: eventstream.Headers{
+			{Name: eventstreamapi.MessageTypeHeader, Value: eventstream.StringValue(eventstreamapi.ExceptionMessageType)},
+			{Name: eventstreamapi.ExceptionTypeHeader, Value: eventstream.StringValue(exceptionType)},
+			{Name: eventstreamapi.ContentTypeHeader, Value: eventstream.StringValue("application/json")},
+		},
+		Payload: body,
+	})
+}
+
+// write encodes msg and flushes it so the client sees each event as it is produced
+func (e *eventWriter) write(msg eventstream.Message) error {
+	if err := e.encoder.Encode(e.w, msg); err != nil {
+		return err
+	}
+	if f, ok := e.w.(interface{ Flush() }); ok {
+		f.Flush()
+	}
+	return nil
+}
@@ -0,0 +1,261 @@
+package mock
+
+import "fmt"
+
+// obj is a JSON object in a response body or stream chunk
+type obj = map[string]interface{}
+
+// generation describes the output of one mocked invocation
+type generation struct {
+	requestID    string
+	inputTokens  int
+	outputTokens int
+	// truncated means the output stopped at the request's max tokens
+	truncated bool
+}
+
+// family builds the response body and stream chunks of one model family's
+// InvokeModel format. Stream chunks are sent in the order start, one delta
+// per text piece, end; the server adds amazon-bedrock-invocationMetrics to
+// the last chunk.
+type family interface {
+	response(text string, gen *generation) obj
+	streamStart(gen *generation) []obj
+	// streamDelta returns the chunk for the index-th piece of text; sent is
+	// the number of output tokens generated so far, including this piece
+	streamDelta(text string, index, sent int, gen *generation) obj
+	streamEnd(gen *generation) []obj
+}
+
+// families maps adapter format names to their mocked response shapes
+var families = map[string]family{
+	"claude":      claudeFamily{},
+	"nova":        novaFamily{},
+	"llama":       llamaFamily{},
+	"llama2":      llamaFamily{},
+	"mistral":     mistralFamily{},
+	"titan":       titanFamily{},
+	"cohere":      cohereFamily{},
+	"deepseek":    chatFamily{},
+	"qwen":        chatFamily{},
+	"openai-chat": chatFamily{},
+	"jamba":       chatFamily{},
+	"gpt-oss":     chatFamily{},
+}
+
+// stopReason returns the family's stop reason for a finished or truncated generation
+func stopReason(gen *generation, finished, truncated string) string {
+	if gen.truncated {
+		return truncated
+	}
+	return finished
+}
+
+// claudeFamily mocks the Anthropic Messages API
+type claudeFamily struct{}
+
+func (claudeFamily) response(text string, gen *generation) obj {
+	return obj{
+		"id":          "msg_" + gen.requestID,
+		"type":        "message",
+		"role":        "assistant",
+		"content":     []obj{{"type": "text", "text": text}},
+		"stop_reason": stopReason(gen, "end_turn", "max_tokens"),
+		"usage":       obj{"input_tokens": gen.inputTokens, "output_tokens": gen.outputTokens},
+	}
+}
+
+func (claudeFamily) streamStart(gen *generation) []obj {
+	return []obj{
+		{"type": "message_start", "message": obj{
+			"id": "msg_" + gen.requestID, "type": "message", "role": "assistant", "content": []obj{},
+			"usage": obj{"input_tokens": gen.inputTokens, "output_tokens": 1},
+		}},
+		{"type": "content_block_start", "index": 0, "content_block": obj{"type": "text", "text": ""}},
+	}
+}
+
+func (claudeFamily) streamDelta(text string, index, sent int, gen *generation) obj {
+	return obj{"type": "content_block_delta", "index": 0, "delta": obj{"type": "text_delta", "text": text}}
+}
+
+func (claudeFamily) streamEnd(gen *generation) []obj {
+	return []obj{
+		{"type": "content_block_stop", "index": 0},
+		{"type": "message_delta", "delta": obj{"stop_reason": stopReason(gen, "end_turn", "max_tokens")},
+			"usage": obj{"output_tokens": gen.outputTokens}},
+		{"type": "message_stop"},
+	}
+}
+
+// novaFamily mocks the Amazon Nova messages-v1 format
+type novaFamily struct{}
+
+func (novaFamily) response(text string, gen *generation) obj {
+	return obj{
+		"output":     obj{"message": obj{"role": "assistant", "content": []obj{{"text": text}}}},
+		"stopReason": stopReason(gen, "end_turn", "max_tokens"),
+		"usage":      obj{"inputTokens": gen.inputTokens, "outputTokens": gen.outputTokens, "totalTokens": gen.inputTokens + gen.outputTokens},
+	}
+}
+
+func (novaFamily) streamStart(gen *generation) []obj {
+	return []obj{{"messageStart": obj{"role": "assistant"}}}
+}
+
+func (novaFamily) streamDelta(text string, index, sent int, gen *generation) obj {
+	return obj{"contentBlockDelta": obj{"delta": obj{"text": text}, "contentBlockIndex": 0}}
+}
+
+func (novaFamily) streamEnd(gen *generation) []obj {
+	return []obj{
+		{"contentBlockStop": obj{"contentBlockIndex": 0}},
+		{"messageStop": obj{"stopReason": stopReason(gen, "end_turn", "max_tokens")}},
+		{"metadata": obj{"usage": obj{"inputTokens": gen.inputTokens, "outputTokens": gen.outputTokens}}},
+	}
+}
+
+// llamaFamily mocks the Meta Llama text completion format
+type llamaFamily struct{}
+
+func (llamaFamily) response(text string, gen *generation) obj {
+	return obj{
+		"generation":             text,
+		"prompt_token_count":     gen.inputTokens,
+		"generation_token_count": gen.outputTokens,
+		"stop_reason":            stopReason(gen, "stop", "length"),
+	}
+}
+
+func (llamaFamily) streamStart(gen *generation) []obj { return nil }
+
+// streamDelta sets the prompt token count on the first chunk only, as Bedrock does
+func (llamaFamily) streamDelta(text string, index, sent int, gen *generation) obj {
+	chunk := obj{"generation": text, "generation_token_count": sent, "prompt_token_count": nil, "stop_reason": nil}
+	if index == 0 {
+		chunk["prompt_token_count"] = gen.inputTokens
+	}
+	return chunk
+}
+
+func (llamaFamily) streamEnd(gen *generation) []obj {
+	return []obj{{
+		"generation": "", "generation_token_count": gen.outputTokens, "prompt_token_count": nil,
+		"stop_reason": stopReason(gen, "stop", "length"),
+	}}
+}
+
+// mistralFamily mocks the Mistral text completion format
+type mistralFamily struct{}
+
+func (mistralFamily) response(text string, gen *generation) obj {
+	return obj{"outputs": []obj{{"text": text, "stop_reason": stopReason(gen, "stop", "length")}}}
+}
+
+func (mistralFamily) streamStart(gen *generation) []obj { return nil }
+
+func (mistralFamily) streamDelta(text string, index, sent int, gen *generation) obj {
+	return obj{"outputs": []obj{{"text": text, "stop_reason": nil}}}
+}
+
+func (mistralFamily) streamEnd(gen *generation) []obj {
+	return []obj{{"outputs": []obj{{"text": "", "stop_reason": stopReason(gen, "stop", "length")}}}}
+}
+
+// titanFamily mocks the Amazon Titan Text format
+type titanFamily struct{}
+
+func (titanFamily) response(text string, gen *generation) obj {
+	return obj{
+		"inputTextTokenCount": gen.inputTokens,
+		"results": []obj{{
+			"tokenCount":       gen.outputTokens,
+			"outputText":       text,
+			"completionReason": stopReason(gen, "FINISH", "LENGTH"),
+		}},
+	}
+}
+
+func (titanFamily) streamStart(gen *generation) []obj { return nil }
+
+func (titanFamily) streamDelta(text string, index, sent int, gen *generation) obj {
+	return obj{"outputText": text, "index": 0, "totalOutputTextTokenCount": sent, "completionReason": nil, "inputTextTokenCount": gen.inputTokens}
+}
+
+func (titanFamily) streamEnd(gen *generation) []obj {
+	return []obj{{
+		"outputText": "", "index": 0, "totalOutputTextTokenCount": gen.outputTokens,
+		"completionReason": stopReason(gen, "FINISH", "LENGTH"), "inputTextTokenCount": gen.inputTokens,
+	}}
+}
+
+// cohereFamily mocks the Cohere Command R chat format
+type cohereFamily struct{}
+
+func (cohereFamily) response(text string, gen *generation) obj {
+	return obj{
+		"response_id":   gen.requestID,
+		"text":          text,
+		"finish_reason": stopReason(gen, "COMPLETE", "MAX_TOKENS"),
+		"meta":          obj{"billed_units": obj{"input_tokens": gen.inputTokens, "output_tokens": gen.outputTokens}},
+	}
+}
+
+func (cohereFamily) streamStart(gen *generation) []obj {
+	return []obj{{"event_type": "stream-start", "is_finished": false, "generation_id": gen.requestID}}
+}
+
+func (cohereFamily) streamDelta(text string, index, sent int, gen *generation) obj {
+	return obj{"event_type": "text-generation", "is_finished": false, "text": text}
+}
+
+func (c cohereFamily) streamEnd(gen *generation) []obj {
+	finish := stopReason(gen, "COMPLETE", "MAX_TOKENS")
+	return []obj{{
+		"event_type": "stream-end", "is_finished": true, "finish_reason": finish,
+		"response": c.response("", gen),
+	}}
+}
+
+// chatFamily mocks the OpenAI chat completions format (DeepSeek, Qwen, Jamba, gpt-oss)
+type chatFamily struct{}
+
+func (chatFamily) usage(gen *generation) obj {
+	return obj{"prompt_tokens": gen.inputTokens, "completion_tokens": gen.outputTokens, "total_tokens": gen.inputTokens + gen.outputTokens}
+}
+
+func (f chatFamily) response(text string, gen *generation) obj {
+	return obj{
+		"id":     "chatcmpl-" + gen.requestID,
+		"object": "chat.completion",
+		"choices": []obj{{
+			"index":         0,
+			"message":       obj{"role": "assistant", "content": text},
+			"finish_reason": stopReason(gen, "stop", "length"),
+		}},
+		"usage": f.usage(gen),
+	}
+}
+
+func (chatFamily) streamStart(gen *generation) []obj {
+	return []obj{chatChunk(gen, obj{"role": "assistant", "content": ""}, nil)}
+}
+
+func (chatFamily) streamDelta(text string, index, sent int, gen *generation) obj {
+	return chatChunk(gen, obj{"content": text}, nil)
+}
+
+func (f chatFamily) streamEnd(gen *generation) []obj {
+	chunk := chatChunk(gen, obj{}, stopReason(gen, "stop", "length"))
+	chunk["usage"] = f.usage(gen)
+	return []obj{chunk}
+}
+
+// chatChunk returns a chat.completion.chunk with a single choice
+func chatChunk(gen *generation, delta obj, finishReason interface{}) obj {
+	return obj{
+		"id":      fmt.Sprintf("chatcmpl-%s", gen.requestID),
+		"object":  "chat.completion.chunk",
+		"choices": []obj{{"index": 0, "delta": delta, "finish_reason": finishReason}},
+	}
+}
@@ -0,0 +1,391 @@
+package mock
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"bedrock-performance/internal/bedrock"
+)
+
+// fillerWords is the text the mock models generate, one word per token
+var fillerWords = strings.Fields(`the quick brown fox jumps over the lazy dog while
+	a benchmark measures how long each token takes to arrive from the model`)
+
+// Server is an http.Handler that imitates the Bedrock runtime InvokeModel,
+// InvokeModelWithResponseStream, Converse, ConverseStream and CountTokens
+// operations
+type Server struct {
+	cfg *Config
+
+	mu  sync.Mutex
+	rng *rand.Rand
+	// window is the start of the current one-second request window and
+	// windowCount the number of requests admitted in it
+	window      time.Time
+	windowCount int
+	requests    int64
+}
+
+// NewServer creates a mock server with the given behaviour
+func NewServer(cfg *Config) *Server {
+	seed := cfg.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	return &Server{cfg: cfg, rng: rand.New(rand.NewSource(seed))}
+}
+
+// ServeHTTP routes POST /model/{modelId}/invoke,
+// POST /model/{modelId}/invoke-with-response-stream,
+// POST /model/{modelId}/converse, POST /model/{modelId}/converse-stream and
+// POST /model/{modelId}/count-tokens
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	modelID, operation, ok := parsePath(r.URL.EscapedPath())
+	if !ok || r.Method != http.MethodPost {
+		s.writeError(w, http.StatusNotFound, "UnknownOperationException", "the mock server only serves InvokeModel, InvokeModelWithResponseStream, Converse, ConverseStream and CountTokens")
+		return
+	}
+	if operation == "count-tokens" {
+		s.countTokens(w, r)
+		return
+	}
+
+	// Converse has the same request and response shapes for every family
+	converse := operation == "converse" || operation == "converse-stream"
+	var f family
+	if !converse {
+		var err error
+		if f, err = s.family(modelID); err != nil {
+			s.writeError(w, http.StatusBadRequest, "ValidationException", err.Error())
+			return
+		}
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, "ValidationException", "failed to read request body")
+		return
+	}
+
+	start := time.Now()
+	if status, code, message := s.admit(start); status != 0 {
+		s.writeError(w, status, code, message)
+		return
+	}
+
+	gen, ttft, midStreamError := s.plan(body)
+	w.Header().Set("x-amzn-RequestId", gen.requestID)
+	if converse {
+		gen.inputTokens = converseInputTokens(body)
+	}
+	// Echo the requested latency mode; the mock serves both at the same speed
+	latency := r.Header.Get("X-Amzn-Bedrock-PerformanceConfig-Latency")
+	if latency == "" {
+		latency = string(bedrock.PerformanceLatencyStandard)
+	}
+	w.Header().Set("X-Amzn-Bedrock-PerformanceConfig-Latency", latency)
+
+	switch operation {
+	case "invoke":
+		s.invoke(w, r, f, gen, ttft, start)
+	case "invoke-with-response-stream":
+		s.stream(w, r, f, gen, ttft, midStreamError, start)
+	case "converse":
+		s.converse(w, r, body, gen, ttft, start)
+	case "converse-stream":
+		s.converseStream(w, r, body, gen, ttft, midStreamError, start)
+	}
+}
+
+// parsePath extracts the model ID and operation from a request path
+func parsePath(path string) (modelID, operation string, ok bool) {
+	rest, found := strings.CutPrefix(path, "/model/")
+	if !found {
+		return "", "", false
+	}
+	i := strings.LastIndex(rest, "/")
+	if i <= 0 {
+		return "", "", false
+	}
+	operation = rest[i+1:]
+	switch operation {
+	case "invoke", "invoke-with-response-stream", "converse", "converse-stream", "count-tokens":
+	default:
+		return "", "", false
+	}
+	modelID, err := url.PathUnescape(rest[:i])
+	if err != nil {
+		return "", "", false
+	}
+	return modelID, operation, true
+}
+
+// family returns the response shapes for a model ID, falling back to the
+// configured format for IDs that do not name a model family
+func (s *Server) family(modelID string) (family, error) {
+	name := s.cfg.Format
+	if adapter, err := bedrock.ResolveAdapter(modelID, ""); err == nil {
+		name = adapter.Name()
+	}
+	f, ok := families[name]
+	if !ok {
+		return nil, fmt.Errorf("the mock server does not support model format %q", name)
+	}
+	return f, nil
+}
+
+// admit applies the per-second request cap and the injected throttling and
+// server errors, returning a non-zero status if the request is rejected
+func (s *Server) admit(now time.Time) (status int, code, message string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cfg.MaxRequestsPerSecond > 0 {
+		if now.Sub(s.window) >= time.Second {
+			s.window, s.windowCount = now, 0
+		}
+		if s.windowCount >= s.cfg.MaxRequestsPerSecond {
+			return http.StatusTooManyRequests, "ThrottlingException", "Too many requests, please wait before trying again."
+		}
+		s.windowCount++
+	}
+
+	if s.rng.Float64() < s.cfg.ThrottleRate {
+		return http.StatusTooManyRequests, "ThrottlingException", "Too many requests, please wait before trying again."
+	}
+	if s.rng.Float64() < s.cfg.ErrorRate {
+		if s.rng.Intn(2) == 0 {
+			return http.StatusInternalServerError, "InternalServerException", "The server encountered an internal error."
+		}
+		return http.StatusServiceUnavailable, "ServiceUnavailableException", "The service is temporarily unavailable."
+	}
+	return 0, "", ""
+}
+
+// plan draws the output length, time to first token and mid-stream failure of a request
+func (s *Server) plan(body []byte) (gen *generation, ttft time.Duration, midStreamError bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	gen = &generation{
+		requestID:   s.nextRequestIDLocked(),
+		inputTokens: max(1, len(body)/4),
+	}
+
+	span := s.cfg.OutputTokens.Max - s.cfg.OutputTokens.Min
+	gen.outputTokens = s.cfg.OutputTokens.Min + s.rng.Intn(span+1)
+	if limit := maxTokens(body); limit > 0 && gen.outputTokens >= limit {
+		gen.outputTokens, gen.truncated = limit, true
+	}
+
+	// log-normal with median p50; z(0.99) = 2.326
+	sigma := 0.0
+	if s.cfg.TTFT.P50 > 0 {
+		sigma = math.Log(s.cfg.TTFT.P99/s.cfg.TTFT.P50) / 2.326
+	}
+	ms := s.cfg.TTFT.P50 * math.Exp(sigma*s.rng.NormFloat64())
+	ttft = time.Duration(ms * float64(time.Millisecond))
+
+	midStreamError = s.rng.Float64() < s.cfg.MidStreamErrorRate
+	return gen, ttft, midStreamError
+}
+
+// maxTokens finds the output limit in a request body of any supported family
+func maxTokens(body []byte) int {
+	var req struct {
+		MaxTokens           int `json:"max_tokens"`
+		MaxGenLen           int `json:"max_gen_len"`
+		MaxCompletionTokens int `json:"max_completion_tokens"`
+		TextGeneration      struct {
+			MaxTokenCount int `json:"maxTokenCount"`
+		} `json:"textGenerationConfig"`
+		InferenceConfig struct {
+			MaxTokens    int `json:"maxTokens"`
+			MaxNewTokens int `json:"max_new_tokens"`
+		} `json:"inferenceConfig"`
+	}
+	if json.Unmarshal(body, &req) != nil {
+		return 0
+	}
+	for _, v := range []int{req.MaxTokens, req.MaxGenLen, req.MaxCompletionTokens,
+		req.TextGeneration.MaxTokenCount, req.InferenceConfig.MaxTokens, req.InferenceConfig.MaxNewTokens} {
+		if v > 0 {
+			return v
+		}
+	}
+	return 0
+}
+
+// countTokens answers CountTokens for InvokeModel bodies and Converse inputs
+// with the input token count an invocation of the same request reports.
+// Counting is not subject to throttling or injected errors.
+func (s *Server) countTokens(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Input struct {
+			InvokeModel *struct {
+				Body []byte `json:"body"`
+			} `json:"invokeModel"`
+			Converse json.RawMessage `json:"converse"`
+		} `json:"input"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, http.StatusBadRequest, "ValidationException", "failed to read request body")
+		return
+	}
+
+	var tokens int
+	switch {
+	case req.Input.InvokeModel != nil:
+		tokens = max(1, len(req.Input.InvokeModel.Body)/4)
+	case req.Input.Converse != nil:
+		tokens = converseInputTokens(req.Input.Converse)
+	default:
+		s.writeError(w, http.StatusBadRequest, "ValidationException", "input must contain invokeModel or converse")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(obj{"inputTokens": tokens})
+}
+
+// decodeTime is the time the mock model needs to generate tokens after the first one
+func (s *Server) decodeTime(tokens int) time.Duration {
+	return time.Duration(float64(tokens) / s.cfg.TokensPerSecond * float64(time.Second))
+}
+
+// invoke answers InvokeModel after the full generation time
+func (s *Server) invoke(w http.ResponseWriter, r *http.Request, f family, gen *generation, ttft time.Duration, start time.Time) {
+	if !sleep(r, ttft+s.decodeTime(gen.outputTokens-1)) {
+		return
+	}
+
+	payload, err := json.Marshal(f.response(outputText(0, gen.outputTokens), gen))
+	if err != nil {
+		s.writeError(w, http.StatusInternalServerError, "InternalServerException", err.Error())
+		return
+	}
+
+	h := w.Header()
+	h.Set("Content-Type", "application/json")
+	h.Set("X-Amzn-Bedrock-Invocation-Latency", strconv.FormatInt(time.Since(start).Milliseconds(), 10))
+	h.Set("X-Amzn-Bedrock-Input-Token-Count", strconv.Itoa(gen.inputTokens))
+	h.Set("X-Amzn-Bedrock-Output-Token-Count", strconv.Itoa(gen.outputTokens))
+	w.Write(payload)
+}
+
+// stream answers InvokeModelWithResponseStream, pacing chunks at the
+// configured decode speed. A mid-stream error stops after about half of the
+// output with a modelStreamErrorException.
+func (s *Server) stream(w http.ResponseWriter, r *http.Request, f family, gen *generation, ttft time.Duration, midStreamError bool, start time.Time) {
+	w.Header().Set("Content-Type", "application/vnd.amazon.eventstream")
+	w.WriteHeader(http.StatusOK)
+	events := newEventWriter(w)
+
+	send := func(chunk obj) bool {
+		payload, err := json.Marshal(chunk)
+		return err == nil && events.chunk(payload) == nil
+	}
+
+	if !sleep(r, ttft) {
+		return
+	}
+	firstByte := time.Since(start)
+
+	var pending []obj
+	pending = append(pending, f.streamStart(gen)...)
+
+	failAt := gen.outputTokens + 1
+	if midStreamError {
+		failAt = max(1, gen.outputTokens/2)
+	}
+
+	sent := 0
+	for index := 0; sent < gen.outputTokens; index++ {
+		if sent >= failAt {
+			events.exception("modelStreamErrorException", "The model stopped generating output unexpectedly.")
+			return
+		}
+		n := min(s.cfg.ChunkTokens, gen.outputTokens-sent)
+		if index > 0 && !sleep(r, s.decodeTime(n)) {
+			return
+		}
+		text := outputText(sent, n)
+		sent += n
+		pending = append(pending, f.streamDelta(text, index, sent, gen))
+		for _, chunk := range pending {
+			if !send(chunk) {
+				return
+			}
+		}
+		pending = pending[:0]
+	}
+
+	end := f.streamEnd(gen)
+	end[len(end)-1]["amazon-bedrock-invocationMetrics"] = obj{
+		"inputTokenCount":   gen.inputTokens,
+		"outputTokenCount":  gen.outputTokens,
+		"invocationLatency": time.Since(start).Milliseconds(),
+		"firstByteLatency":  firstByte.Milliseconds(),
+	}
+	for _, chunk := range end {
+		if !send(chunk) {
+			return
+		}
+	}
+}
+
+// outputText returns n filler tokens starting at token offset
+func outputText(offset, n int) string {
+	var sb strings.Builder
+	for i := offset; i < offset+n; i++ {
+		if i > 0 {
+			sb.WriteByte(' ')
+		}
+		sb.WriteString(fillerWords[i%len(fillerWords)])
+	}
+	return sb.String()
+}
+
+// sleep waits for d, returning false if the client went away first
+func sleep(r *http.Request, d time.Duration) bool {
+	if d <= 0 {
+		return r.Context().Err() == nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-r.Context().Done():
+		return false
+	}
+}
+
+// nextRequestIDLocked returns a new request ID; the caller must hold s.mu
+func (s *Server) nextRequestIDLocked() string {
+	s.requests++
+	return fmt.Sprintf("mock-%08d", s.requests)
+}
+
+// writeError writes an AWS JSON error response with a request ID, keeping
+// the one already assigned to the request if any
+func (s *Server) writeError(w http.ResponseWriter, status int, code, message string) {
+	h := w.Header()
+	if h.Get("x-amzn-RequestId") == "" {
+		s.mu.Lock()
+		h.Set("x-amzn-RequestId", s.nextRequestIDLocked())
+		s.mu.Unlock()
+	}
+	h.Set("Content-Type", "application/json")
+	h.Set("X-Amzn-ErrorType", code)
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(obj{"message": message})
+}
@@ -0,0 +1,330 @@
+package mock
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws/protocol/eventstream"
+	"github.com/aws/aws-sdk-go-v2/aws/protocol/eventstream/eventstreamapi"
+
+	"bedrock-performance/internal/bedrock"
+)
+
+// fastConfig returns a mock that answers immediately with ten output tokens
+func fastConfig() *Config {
+	cfg := &Config{
+		TTFT:            LatencyConfig{P50: 0.01, P99: 0.01},
+		TokensPerSecond: 1e6,
+		OutputTokens:    RangeConfig{Min: 10, Max: 10},
+		Seed:            1,
+	}
+	cfg.applyDefaults()
+	return cfg
+}
+
+// post sends body to an operation of the mock for a model ID
+func post(t *testing.T, srv *httptest.Server, modelID, operation string, body []byte) *http.Response {
+	t.Helper()
+	resp, err := http.Post(srv.URL+"/model/"+url.PathEscape(modelID)+"/"+operation, "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+// streamMessage is one decoded event of a response stream
+type streamMessage struct {
+	messageType string
+	eventType   string
+	exception   string
+	payload     []byte // the model chunk for events, the error body for exceptions
+}
+
+// decodeStream reads every eventstream message of a streaming response; the
+// SDK decoder checks the prelude and message CRCs
+func decodeStream(t *testing.T, r io.Reader) []streamMessage {
+	t.Helper()
+	decoder := eventstream.NewDecoder()
+	var messages []streamMessage
+	for {
+		msg, err := decoder.Decode(r, nil)
+		if err == io.EOF {
+			return messages
+		}
+		if err != nil {
+			t.Fatalf("decoding stream: %v", err)
+		}
+
+		m := streamMessage{payload: msg.Payload}
+		if v := msg.Headers.Get(eventstreamapi.MessageTypeHeader); v != nil {
+			m.messageType = v.String()
+		}
+		if v := msg.Headers.Get(eventstreamapi.EventTypeHeader); v != nil {
+			m.eventType = v.String()
+		}
+		if v := msg.Headers.Get(eventstreamapi.ExceptionTypeHeader); v != nil {
+			m.exception = v.String()
+		}
+		if m.messageType == eventstreamapi.EventMessageType {
+			// PayloadPart carries the model chunk base64-encoded in "bytes"
+			var part struct {
+				Bytes []byte `json:"bytes"`
+			}
+			if err := json.Unmarshal(msg.Payload, &part); err != nil {
+				t.Fatalf("chunk payload %s: %v", msg.Payload, err)
+			}
+			m.payload = part.Bytes
+		}
+		messages = append(messages, m)
+	}
+}
+
+func TestFamilies(t *testing.T) {
+	modelIDs := []string{
+		"anthropic.claude-3-haiku-20240307-v1:0",
+		"amazon.nova-lite-v1:0",
+		"meta.llama3-8b-instruct-v1:0",
+		"meta.llama2-13b-chat-v1",
+		"mistral.mistral-7b-instruct-v0:2",
+		"amazon.titan-text-express-v1",
+		"cohere.command-r-v1:0",
+		"deepseek.r1-v1:0",
+		"qwen.qwen3-32b-v1:0",
+		"ai21.jamba-1-5-mini-v1:0",
+		"openai.gpt-oss-20b-1:0",
+	}
+	srv := httptest.NewServer(NewServer(fastConfig()))
+	defer srv.Close()
+	want := outputText(0, 10)
+
+	for _, modelID := range modelIDs {
+		t.Run(modelID, func(t *testing.T) {
+			adapter, err := bedrock.ResolveAdapter(modelID, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			body, err := adapter.BuildRequest(&bedrock.InferenceParams{Prompt: "Say something.", MaxTokens: 64})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			resp := post(t, srv, modelID, "invoke", body)
+			if resp.StatusCode != http.StatusOK || resp.Header.Get("x-amzn-RequestId") == "" {
+				t.Fatalf("invoke: status %d, request ID %q", resp.StatusCode, resp.Header.Get("x-amzn-RequestId"))
+			}
+			data, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			result := &bedrock.InvokeResult{}
+			if err := adapter.ParseResponse(data, result); err != nil {
+				t.Fatalf("invoke response %s: %v", data, err)
+			}
+			// Families without usage in the body (Mistral) rely on the headers
+			if result.OutputTokens == 0 {
+				result.OutputTokens, _ = strconv.Atoi(resp.Header.Get("X-Amzn-Bedrock-Output-Token-Count"))
+			}
+			if result.ResponseContent != want || result.OutputTokens != 10 || result.StopReason == "" {
+				t.Errorf("invoke parsed to %q, %d output tokens, stop %q", result.ResponseContent, result.OutputTokens, result.StopReason)
+			}
+
+			resp = post(t, srv, modelID, "invoke-with-response-stream", body)
+			if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "application/vnd.amazon.eventstream" {
+				t.Fatalf("stream: status %d, content type %q", resp.StatusCode, resp.Header.Get("Content-Type"))
+			}
+			messages := decodeStream(t, resp.Body)
+			if len(messages) == 0 {
+				t.Fatal("stream sent no events")
+			}
+			streamed := &bedrock.InvokeResult{}
+			var text strings.Builder
+			for _, m := range messages {
+				if m.messageType != eventstreamapi.EventMessageType || m.eventType != "chunk" {
+					t.Fatalf("stream message type %q, event type %q", m.messageType, m.eventType)
+				}
+				delta, err := adapter.ParseStreamChunk(m.payload, streamed)
+				if err != nil {
+					t.Fatalf("stream chunk %s: %v", m.payload, err)
+				}
+				text.WriteString(delta.Text)
+			}
+			if text.String() != want || streamed.StopReason == "" {
+				t.Errorf("stream parsed to %q, stop %q", text.String(), streamed.StopReason)
+			}
+			if streamed.OutputTokens != 0 && streamed.OutputTokens != 10 {
+				t.Errorf("stream usage reports %d output tokens, want 10", streamed.OutputTokens)
+			}
+			var last struct {
+				Metrics struct {
+					InputTokenCount  int `json:"inputTokenCount"`
+					OutputTokenCount int `json:"outputTokenCount"`
+				} `json:"amazon-bedrock-invocationMetrics"`
+			}
+			if err := json.Unmarshal(messages[len(messages)-1].payload, &last); err != nil || last.Metrics.OutputTokenCount != 10 || last.Metrics.InputTokenCount <= 0 {
+				t.Errorf("last chunk invocation metrics = %+v (%v)", last.Metrics, err)
+			}
+		})
+	}
+}
+
+func TestFaultRates(t *testing.T) {
+	cfg := fastConfig()
+	cfg.ThrottleRate = 0.3
+	cfg.ErrorRate = 0.2
+	srv := httptest.NewServer(NewServer(cfg))
+	defer srv.Close()
+
+	const requests = 1000
+	statuses := make(map[int]int)
+	for i := 0; i < requests; i++ {
+		resp := post(t, srv, "anthropic.claude-3-haiku-20240307-v1:0", "invoke", []byte(`{"max_tokens": 16}`))
+		io.Copy(io.Discard, resp.Body)
+		statuses[resp.StatusCode]++
+
+		if resp.Header.Get("x-amzn-RequestId") == "" {
+			t.Fatalf("status %d response has no request ID", resp.StatusCode)
+		}
+		wantType := map[int]string{
+			http.StatusTooManyRequests:     "ThrottlingException",
+			http.StatusInternalServerError: "InternalServerException",
+			http.StatusServiceUnavailable:  "ServiceUnavailableException",
+		}[resp.StatusCode]
+		if got := resp.Header.Get("X-Amzn-ErrorType"); got != wantType {
+			t.Fatalf("status %d has error type %q, want %q", resp.StatusCode, got, wantType)
+		}
+	}
+
+	// 30% are throttled, and 20% of the rest fail with a server error
+	within := func(name string, got int, rate float64) {
+		want := rate * requests
+		if float64(got) < want*0.8 || float64(got) > want*1.2 {
+			t.Errorf("%s: %d of %d requests, want about %.0f", name, got, requests, want)
+		}
+	}
+	within("throttled", statuses[http.StatusTooManyRequests], 0.3)
+	within("server errors", statuses[http.StatusInternalServerError]+statuses[http.StatusServiceUnavailable], 0.7*0.2)
+	if statuses[http.StatusInternalServerError] == 0 || statuses[http.StatusServiceUnavailable] == 0 {
+		t.Errorf("server errors by status = %v, want both 500 and 503", statuses)
+	}
+}
+
+func TestMaxRequestsPerSecond(t *testing.T) {
+	cfg := fastConfig()
+	cfg.MaxRequestsPerSecond = 5
+	srv := httptest.NewServer(NewServer(cfg))
+	defer srv.Close()
+
+	throttled := 0
+	for i := 0; i < 8; i++ {
+		if post(t, srv, "amazon.nova-lite-v1:0", "invoke", []byte(`{}`)).StatusCode == http.StatusTooManyRequests {
+			throttled++
+		}
+	}
+	if throttled != 3 {
+		t.Errorf("%d of 8 requests throttled, want 3 above the cap of 5 per second", throttled)
+	}
+}
+
+func TestMidStreamError(t *testing.T) {
+	cfg := fastConfig()
+	cfg.MidStreamErrorRate = 1
+	srv := httptest.NewServer(NewServer(cfg))
+	defer srv.Close()
+
+	resp := post(t, srv, "anthropic.claude-3-haiku-20240307-v1:0", "invoke-with-response-stream", []byte(`{"max_tokens": 64}`))
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status %d, want 200 before the stream fails", resp.StatusCode)
+	}
+	messages := decodeStream(t, resp.Body)
+	last := messages[len(messages)-1]
+	if last.messageType != eventstreamapi.ExceptionMessageType || last.exception != "modelStreamErrorException" {
+		t.Fatalf("last message is %q/%q, want a modelStreamErrorException", last.messageType, last.exception)
+	}
+	if len(messages) < 2 {
+		t.Errorf("stream failed before sending any output")
+	}
+}
+
+func TestCountTokensMatchesInvoke(t *testing.T) {
+	srv := httptest.NewServer(NewServer(fastConfig()))
+	defer srv.Close()
+	body := []byte(`{"anthropic_version": "bedrock-2023-05-31", "max_tokens": 16, "messages": [{"role": "user", "content": "Count me."}]}`)
+
+	request, _ := json.Marshal(obj{"input": obj{"invokeModel": obj{"body": body}}})
+	var counted struct {
+		InputTokens int `json:"inputTokens"`
+	}
+	if err := json.NewDecoder(post(t, srv, "anthropic.claude-3-haiku-20240307-v1:0", "count-tokens", request).Body).Decode(&counted); err != nil {
+		t.Fatal(err)
+	}
+	invoked := post(t, srv, "anthropic.claude-3-haiku-20240307-v1:0", "invoke", body).Header.Get("X-Amzn-Bedrock-Input-Token-Count")
+	if counted.InputTokens == 0 || invoked != strconv.Itoa(counted.InputTokens) {
+		t.Errorf("CountTokens = %d, invocation reported %s", counted.InputTokens, invoked)
+	}
+}
+
+func TestConverse(t *testing.T) {
+	cfg := fastConfig()
+	srv := httptest.NewServer(NewServer(cfg))
+	defer srv.Close()
+	want := outputText(0, 10)
+
+	// Converse serves every family, including ones the InvokeModel mock
+	// has no format for
+	for _, modelID := range []string{"anthropic.claude-3-haiku-20240307-v1:0", "meta.llama3-8b-instruct-v1:0", "acme.unknown-v1"} {
+		t.Run(modelID, func(t *testing.T) {
+			client := bedrock.NewClientFromConfig(&bedrock.ClientConfig{
+				Region: "us-east-1", ModelID: modelID, MaxTokens: 64, EndpointURL: srv.URL, Anonymous: true,
+				MaxAttempts: 1, API: bedrock.APIConverse, PerformanceLatency: bedrock.PerformanceLatencyOptimized,
+			})
+			counted, err := client.CountTokens(context.Background(), "Say something.")
+			if err != nil {
+				t.Fatalf("CountTokens: %v", err)
+			}
+
+			for _, result := range []*bedrock.InvokeResult{
+				client.InvokeNonStreaming(context.Background(), "Say something."),
+				client.InvokeStreaming(context.Background(), "Say something."),
+			} {
+				if !result.Success {
+					t.Fatalf("converse failed: %v", result.Error)
+				}
+				if result.ResponseContent != want || result.OutputTokens != 10 || result.StopReason != "end_turn" || result.RequestID == "" {
+					t.Errorf("parsed %q, %d output tokens, stop %q, request ID %q", result.ResponseContent, result.OutputTokens, result.StopReason, result.RequestID)
+				}
+				if result.InputTokens != counted || result.PerformanceLatency != string(bedrock.PerformanceLatencyOptimized) {
+					t.Errorf("%d input tokens (counted %d), latency %q", result.InputTokens, counted, result.PerformanceLatency)
+				}
+			}
+		})
+	}
+
+	cfg.MidStreamErrorRate = 1
+	client := bedrock.NewClientFromConfig(&bedrock.ClientConfig{
+		Region: "us-east-1", ModelID: "amazon.nova-lite-v1:0", MaxTokens: 64, EndpointURL: srv.URL, Anonymous: true,
+		MaxAttempts: 1, API: bedrock.APIConverse,
+	})
+	result := client.InvokeStreaming(context.Background(), "Say something.")
+	if result.ErrorType != "ModelStreamError" || result.FailurePhase != bedrock.FailurePhaseMidStream || result.ResponseContent == "" {
+		t.Errorf("ConverseStream failure = %v (%s, %s) after %q", result.Error, result.ErrorType, result.FailurePhase, result.ResponseContent)
+	}
+}
+
+func TestUnknownOperation(t *testing.T) {
+	srv := httptest.NewServer(NewServer(fastConfig()))
+	defer srv.Close()
+
+	resp := post(t, srv, "anthropic.claude-3-haiku-20240307-v1:0", "apply-guardrail", []byte(`{}`))
+	if resp.StatusCode != http.StatusNotFound || resp.Header.Get("X-Amzn-ErrorType") != "UnknownOperationException" || resp.Header.Get("x-amzn-RequestId") == "" {
+		t.Errorf("status %d, error type %q, request ID %q", resp.StatusCode, resp.Header.Get("X-Amzn-ErrorType"), resp.Header.Get("x-amzn-RequestId"))
+	}
+}
@@ -0,0 +1,48 @@
+// Package recording saves the HTTP exchanges of a benchmark run against
+// Bedrock and replays them later with their original timing.
+//
+// Recording happens below the SDK, at the http.RoundTripper, so a replay runs
+// the same response parsing, stream decoding and error classification as a
+// live run. Recordings are JSON Lines files with one Exchange per line.
+package recording
+
+import (
+	"net/http"
+	"time"
+)
+
+// Exchange is one recorded request and its response
+type Exchange struct {
+	// Time is when the request was sent
+	Time        time.Time `json:"time"`
+	Method      string    `json:"method"`
+	Path        string    `json:"path"`
+	RequestBody string    `json:"request_body"`
+
+	// Status is the HTTP status code, 0 when no response was received
+	Status int         `json:"status"`
+	Header http.Header `json:"header,omitempty"`
+	// HeaderAfter is the time from sending the request to the response
+	// headers (or to the transport error)
+	HeaderAfter time.Duration `json:"header_after_ns"`
+	// Chunks are the response body as it was read, with arrival times
+	Chunks []Chunk `json:"chunks,omitempty"`
+
+	// Error is the transport error that ended the exchange, if any
+	Error string `json:"error,omitempty"`
+	// Truncated means the client closed the body before reaching its end
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// Chunk is one read of a response body
+type Chunk struct {
+	// At is the time from sending the request to the arrival of the data
+	At   time.Duration `json:"at_ns"`
+	Data []byte        `json:"data"`
+}
+
+// key identifies the operation and model of an exchange; replay serves the
+// recorded exchanges of each key in order
+func key(method, path string) string {
+	return method + " " + path
+}
@@ -0,0 +1,144 @@
+package recording
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// Recorder writes the exchanges of every transport it wraps to a file
+type Recorder struct {
+	mu    sync.Mutex
+	file  *os.File
+	enc   *json.Encoder
+	count int
+	err   error
+}
+
+// NewRecorder creates (or truncates) the recording file at path
+func NewRecorder(path string) (*Recorder, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create recording file: %w", err)
+	}
+	return &Recorder{file: file, enc: json.NewEncoder(file)}, nil
+}
+
+// Wrap returns a transport that sends requests through base and records them
+func (r *Recorder) Wrap(base http.RoundTripper) http.RoundTripper {
+	return &recordingTransport{base: base, recorder: r}
+}
+
+// Count returns the number of exchanges written so far
+func (r *Recorder) Count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.count
+}
+
+// Close closes the recording file, returning the first write error if any
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.file.Close(); err != nil && r.err == nil {
+		r.err = err
+	}
+	return r.err
+}
+
+// write appends an exchange to the file; the first error is kept for Close
+func (r *Recorder) write(ex *Exchange) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return
+	}
+	if err := r.enc.Encode(ex); err != nil {
+		r.err = fmt.Errorf("failed to write recording: %w", err)
+		return
+	}
+	r.count++
+}
+
+// recordingTransport records every exchange sent through base
+type recordingTransport struct {
+	base     http.RoundTripper
+	recorder *Recorder
+}
+
+// RoundTrip sends req and records the response as the caller reads it
+func (t *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ex := &Exchange{Time: time.Now(), Method: req.Method, Path: req.URL.EscapedPath()}
+
+	if req.Body != nil && req.Body != http.NoBody {
+		body, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		ex.RequestBody = string(body)
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		req.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(body)), nil
+		}
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	ex.HeaderAfter = time.Since(ex.Time)
+	if err != nil {
+		ex.Error = err.Error()
+		t.recorder.write(ex)
+		return nil, err
+	}
+
+	ex.Status = resp.StatusCode
+	ex.Header = resp.Header.Clone()
+	resp.Body = &recordingBody{body: resp.Body, exchange: ex, recorder: t.recorder}
+	return resp, nil
+}
+
+// recordingBody records each read of a response body and writes the
+// exchange once the body reaches its end, fails or is closed
+type recordingBody struct {
+	body     io.ReadCloser
+	exchange *Exchange
+	recorder *Recorder
+	once     sync.Once
+	done     bool
+}
+
+func (b *recordingBody) Read(p []byte) (int, error) {
+	n, err := b.body.Read(p)
+	if n > 0 {
+		b.exchange.Chunks = append(b.exchange.Chunks, Chunk{
+			At:   time.Since(b.exchange.Time),
+			Data: append([]byte(nil), p[:n]...),
+		})
+	}
+	if err != nil {
+		if err != io.EOF {
+			b.exchange.Error = err.Error()
+		}
+		b.done = true
+		b.finish()
+	}
+	return n, err
+}
+
+func (b *recordingBody) Close() error {
+	if !b.done {
+		b.exchange.Truncated = true
+	}
+	b.finish()
+	return b.body.Close()
+}
+
+// finish writes the exchange the first time it is called
+func (b *recordingBody) finish() {
+	b.once.Do(func() { b.recorder.write(b.exchange) })
+}
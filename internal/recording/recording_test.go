@@ -0,0 +1,134 @@
+package recording
+
+import (
+	"context"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"bedrock-performance/internal/bedrock"
+	"bedrock-performance/internal/mock"
+)
+
+const testModel = "anthropic.claude-3-haiku-20240307-v1:0"
+
+// mockServer starts a mock Bedrock runtime with a fixed, measurable pace
+func mockServer(t *testing.T, midStreamErrorRate float64) *httptest.Server {
+	cfg := mock.DefaultConfig()
+	cfg.TTFT = mock.LatencyConfig{P50: 80, P99: 80}
+	cfg.TokensPerSecond = 1000
+	cfg.OutputTokens = mock.RangeConfig{Min: 60, Max: 60}
+	cfg.ChunkTokens = 6
+	cfg.MidStreamErrorRate = midStreamErrorRate
+	cfg.Seed = 1
+	srv := httptest.NewServer(mock.NewServer(cfg))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// record runs one streaming and one non-streaming request through a recorder
+func record(t *testing.T, srv *httptest.Server, path string) (streamed, invoked *bedrock.InvokeResult) {
+	recorder, err := NewRecorder(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := bedrock.NewClientFromConfig(&bedrock.ClientConfig{
+		Region: "us-east-1", ModelID: testModel, MaxTokens: 100,
+		EndpointURL: srv.URL, WrapTransport: recorder.Wrap,
+	})
+	streamed = client.InvokeStreaming(context.Background(), "hello")
+	invoked = client.InvokeNonStreaming(context.Background(), "hello")
+	if err := recorder.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if recorder.Count() != 2 {
+		t.Fatalf("recorded %d exchanges, want 2", recorder.Count())
+	}
+	return streamed, invoked
+}
+
+// replayClient returns a client that answers from the recording at path
+func replayClient(t *testing.T, path string) *bedrock.Client {
+	replayer, err := LoadReplayer(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return bedrock.NewClientFromConfig(&bedrock.ClientConfig{
+		Region: "us-east-1", ModelID: testModel, MaxTokens: 100,
+		Anonymous: true, WrapTransport: replayer.Wrap,
+	})
+}
+
+// near reports whether a replayed duration is within tolerance of the recorded one
+func near(recorded, replayed time.Duration) bool {
+	diff := replayed - recorded
+	return diff > -15*time.Millisecond && diff < 30*time.Millisecond
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "traffic.jsonl")
+	streamed, invoked := record(t, mockServer(t, 0), path)
+	if !streamed.Success || !invoked.Success {
+		t.Fatalf("recording run failed: %v / %v", streamed.Error, invoked.Error)
+	}
+
+	client := replayClient(t, path)
+	replayedStream := client.InvokeStreaming(context.Background(), "a different prompt")
+	replayedInvoke := client.InvokeNonStreaming(context.Background(), "a different prompt")
+
+	if !replayedStream.Success || replayedStream.ResponseContent != streamed.ResponseContent {
+		t.Errorf("replayed stream = %v %q, want %q", replayedStream.Error, replayedStream.ResponseContent, streamed.ResponseContent)
+	}
+	if replayedStream.OutputTokens != streamed.OutputTokens || replayedStream.InputTokens != streamed.InputTokens {
+		t.Errorf("replayed tokens = %d/%d, want %d/%d", replayedStream.InputTokens, replayedStream.OutputTokens,
+			streamed.InputTokens, streamed.OutputTokens)
+	}
+	if !near(streamed.TTFT, replayedStream.TTFT) || !near(streamed.Duration(), replayedStream.Duration()) {
+		t.Errorf("replayed TTFT/duration = %v/%v, recorded %v/%v", replayedStream.TTFT, replayedStream.Duration(),
+			streamed.TTFT, streamed.Duration())
+	}
+	if len(replayedStream.InterChunkGaps) != len(streamed.InterChunkGaps) {
+		t.Errorf("replayed %d chunk gaps, recorded %d", len(replayedStream.InterChunkGaps), len(streamed.InterChunkGaps))
+	}
+
+	if !replayedInvoke.Success || replayedInvoke.OutputTokens != invoked.OutputTokens {
+		t.Errorf("replayed invoke = %v, %d tokens, want %d", replayedInvoke.Error, replayedInvoke.OutputTokens, invoked.OutputTokens)
+	}
+	if !near(invoked.Duration(), replayedInvoke.Duration()) {
+		t.Errorf("replayed invoke duration = %v, recorded %v", replayedInvoke.Duration(), invoked.Duration())
+	}
+}
+
+func TestReplayMidStreamError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "traffic.jsonl")
+	streamed, _ := record(t, mockServer(t, 1), path)
+	if streamed.FailurePhase != bedrock.FailurePhaseMidStream {
+		t.Fatalf("recorded stream phase = %q (%v), want mid-stream failure", streamed.FailurePhase, streamed.Error)
+	}
+
+	replayed := replayClient(t, path).InvokeStreaming(context.Background(), "hello")
+	if replayed.Success || replayed.ErrorType != streamed.ErrorType || replayed.FailurePhase != streamed.FailurePhase {
+		t.Errorf("replayed error = %q/%q, want %q/%q", replayed.ErrorType, replayed.FailurePhase, streamed.ErrorType, streamed.FailurePhase)
+	}
+	if !near(streamed.FailedAfter, replayed.FailedAfter) {
+		t.Errorf("replayed failure after %v, recorded %v", replayed.FailedAfter, streamed.FailedAfter)
+	}
+}
+
+func TestReplayUnknownOperation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "traffic.jsonl")
+	record(t, mockServer(t, 0), path)
+
+	replayer, err := LoadReplayer(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := bedrock.NewClientFromConfig(&bedrock.ClientConfig{
+		Region: "us-east-1", ModelID: "amazon.nova-lite-v1:0", MaxTokens: 100,
+		Anonymous: true, WrapTransport: replayer.Wrap,
+	})
+	if result := client.InvokeNonStreaming(context.Background(), "hello"); result.Success {
+		t.Errorf("replay served a model that was not recorded")
+	}
+}
@@ -0,0 +1,196 @@
+package recording
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Replayer serves recorded exchanges instead of sending requests. The
+// exchanges recorded for each operation and model are served in recorded
+// order, starting over when they run out; request bodies are not compared.
+type Replayer struct {
+	mu        sync.Mutex
+	exchanges map[string][]*Exchange
+	next      map[string]int
+	total     int
+}
+
+// LoadReplayer reads a recording file
+func LoadReplayer(path string) (*Replayer, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open recording file: %w", err)
+	}
+	defer file.Close()
+
+	r := &Replayer{exchanges: make(map[string][]*Exchange), next: make(map[string]int)}
+	dec := json.NewDecoder(file)
+	for {
+		var ex Exchange
+		if err := dec.Decode(&ex); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to parse recording file: %w", err)
+		}
+		k := key(ex.Method, ex.Path)
+		r.exchanges[k] = append(r.exchanges[k], &ex)
+		r.total++
+	}
+	if r.total == 0 {
+		return nil, fmt.Errorf("recording file %s contains no exchanges", path)
+	}
+	return r, nil
+}
+
+// Len returns the number of recorded exchanges
+func (r *Replayer) Len() int {
+	return r.total
+}
+
+// Wrap returns a transport that answers every request from the recording;
+// base is never used
+func (r *Replayer) Wrap(base http.RoundTripper) http.RoundTripper {
+	return &replayTransport{replayer: r}
+}
+
+// take returns the next recorded exchange for a request
+func (r *Replayer) take(method, path string) (*Exchange, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	k := key(method, path)
+	recorded := r.exchanges[k]
+	if len(recorded) == 0 {
+		return nil, fmt.Errorf("no recorded response for %s (recorded: %s)", k, strings.Join(r.keysLocked(), ", "))
+	}
+	ex := recorded[r.next[k]%len(recorded)]
+	r.next[k]++
+	return ex, nil
+}
+
+// keysLocked returns the recorded operations; the caller must hold r.mu
+func (r *Replayer) keysLocked() []string {
+	keys := make([]string, 0, len(r.exchanges))
+	for k := range r.exchanges {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// replayTransport answers requests with recorded exchanges
+type replayTransport struct {
+	replayer *Replayer
+}
+
+// RoundTrip returns the next recorded response for the request's operation
+// and model, delaying the headers and each body chunk by their recorded offsets
+func (t *replayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	if req.Body != nil {
+		io.Copy(io.Discard, req.Body)
+		req.Body.Close()
+	}
+
+	ex, err := t.replayer.take(req.Method, req.URL.EscapedPath())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := waitUntil(req.Context(), nil, start.Add(ex.HeaderAfter)); err != nil {
+		return nil, err
+	}
+	if ex.Status == 0 {
+		return nil, errors.New(ex.Error)
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", ex.Status, http.StatusText(ex.Status)),
+		StatusCode:    ex.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        ex.Header.Clone(),
+		ContentLength: -1,
+		Body:          &replayBody{ctx: req.Context(), exchange: ex, start: start, closed: make(chan struct{})},
+		Request:       req,
+	}, nil
+}
+
+// replayBody returns the recorded chunks of a response body at their
+// recorded offsets from the start of the request
+type replayBody struct {
+	ctx      context.Context
+	exchange *Exchange
+	start    time.Time
+	next     int
+	pending  []byte
+	closed   chan struct{}
+	once     sync.Once
+}
+
+func (b *replayBody) Read(p []byte) (int, error) {
+	if len(b.pending) == 0 {
+		if b.next >= len(b.exchange.Chunks) {
+			return 0, b.end()
+		}
+		chunk := b.exchange.Chunks[b.next]
+		if err := waitUntil(b.ctx, b.closed, b.start.Add(chunk.At)); err != nil {
+			return 0, err
+		}
+		b.next++
+		b.pending = chunk.Data
+	}
+	n := copy(p, b.pending)
+	b.pending = b.pending[n:]
+	return n, nil
+}
+
+// end returns how the recorded body ended. A body the client closed early
+// blocks until it is closed again, as the client did when it was recorded.
+func (b *replayBody) end() error {
+	switch {
+	case b.exchange.Error != "":
+		return errors.New(b.exchange.Error)
+	case b.exchange.Truncated:
+		select {
+		case <-b.ctx.Done():
+			return b.ctx.Err()
+		case <-b.closed:
+			return io.ErrUnexpectedEOF
+		}
+	}
+	return io.EOF
+}
+
+func (b *replayBody) Close() error {
+	b.once.Do(func() { close(b.closed) })
+	return nil
+}
+
+// waitUntil sleeps until t, returning early if ctx is done or closed is closed
+func waitUntil(ctx context.Context, closed <-chan struct{}, t time.Time) error {
+	d := time.Until(t)
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-closed:
+		return io.ErrClosedPipe
+	}
+}
@@ -3,6 +3,7 @@ package report
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"bedrock-performance/internal/config"
 	"bedrock-performance/internal/types"
@@ -22,13 +23,56 @@ func (c *ConsoleReporter) PrintHeader(cfg *config.Config) {
 	fmt.Println("AWS Bedrock Performance Benchmark Tool")
 	fmt.Println(strings.Repeat("=", 80))
 	fmt.Printf("Model: %s\n", cfg.Model.ID)
+	for _, id := range cfg.Model.CompareIDs {
+		fmt.Printf("Compared Model: %s\n", id)
+	}
 	fmt.Printf("Region: %s\n", cfg.AWS.Region)
-	fmt.Printf("Prompt Size: %d characters\n", cfg.Test.PromptSize)
+	if endpoint := endpointDescription(&cfg.AWS); endpoint != "" {
+		fmt.Printf("Endpoint: %s\n", endpoint)
+	}
+	if traffic := trafficDescription(&cfg.Recording); traffic != "" {
+		fmt.Printf("Traffic: %s\n", traffic)
+	}
+	if retry := retryDescription(&cfg.AWS); retry != "" {
+		fmt.Printf("Retry Policy: %s\n", retry)
+	}
+	if faults := faultsDescription(&cfg.Faults); faults != "" {
+		fmt.Printf("Injected Faults: %s\n", faults)
+	}
+	if checks := validationDescription(&cfg.Validation); checks != "" {
+		fmt.Printf("Response Validation: %s\n", checks)
+	}
+	fmt.Printf("Prompt Size: %s\n", promptSizeDescription(&cfg.Test))
 	fmt.Printf("Max Tokens: %d\n", cfg.Test.MaxTokens)
 	fmt.Printf("Temperature: %.2f\n", cfg.Test.Temperature)
+	if cfg.Test.SystemPrompt != "" {
+		fmt.Printf("System Prompt: %d characters\n", len(cfg.Test.SystemPrompt))
+	}
+	fmt.Printf("API: %s\n", cfg.Test.API)
+	fmt.Printf("Service Tier: %s\n", strings.Join(cfg.Test.Tiers(), ", "))
+	fmt.Printf("Performance Latency: %s\n", strings.Join(cfg.Test.Latencies(), ", "))
+	if budgets := cfg.Test.Budgets(); len(budgets) > 1 || budgets[0] > 0 {
+		fmt.Printf("Thinking Budget: %v tokens\n", budgets)
+	}
+	if efforts := cfg.Test.Efforts(); len(efforts) > 1 || efforts[0] != "" {
+		fmt.Printf("Reasoning Effort: %s\n", strings.Join(efforts, ", "))
+	}
+	if cfg.Test.PromptCaching.Enabled {
+		fmt.Printf("Prompt Caching: enabled (compare disabled: %t)\n", cfg.Test.PromptCaching.CompareDisabled)
+	}
+	if media := cfg.Test.Multimodal; len(media.Images) > 0 || len(media.Documents) > 0 {
+		fmt.Printf("Attachments: %d image files %v per request, %d documents\n",
+			len(media.Images), media.ImagesPerRequest(), len(media.Documents))
+	}
+	if cfg.Test.ToolUse.Enabled {
+		fmt.Printf("Tool Use: enabled (tool choice: %s, second turn: %t)\n", cfg.Test.ToolUse.ToolChoice, cfg.Test.ToolUse.SecondTurn)
+	}
 	fmt.Printf("Concurrency Range: %d -> %d (step: %d)\n",
 		cfg.Concurrency.Start, cfg.Concurrency.End, cfg.Concurrency.Step)
 	fmt.Printf("Duration per Level: %d seconds\n", cfg.Concurrency.DurationSeconds)
+	if cfg.Concurrency.Repetitions > 1 {
+		fmt.Printf("Repetitions: %d (randomized order: %t)\n", cfg.Concurrency.Repetitions, cfg.Concurrency.RandomizeOrder)
+	}
 	fmt.Println(strings.Repeat("=", 80))
 	fmt.Println()
 }
@@ -48,6 +92,11 @@ func (c *ConsoleReporter) PrintConcurrencyLevel(level int) {
 	fmt.Println("Starting test...")
 }
 
+// PrintRepetition prints the start of a new repetition of the concurrency sweep
+func (c *ConsoleReporter) PrintRepetition(rep, total int) {
+	fmt.Printf("\n=== Repetition %d/%d ===\n", rep, total)
+}
+
 // PrintProgress prints progress during the test
 func (c *ConsoleReporter) PrintProgress(stats *types.Stats, concurrency int) {
 	fmt.Printf("  Progress: %d requests | Success: %d | Failures: %d | Req/s: %.2f | Tokens/s: %.2f\n",
@@ -75,11 +124,28 @@ func (c *ConsoleReporter) PrintStats(stats *types.Stats, concurrency int) {
 	fmt.Printf("    Requests/sec:     %.2f\n", stats.RequestsPerSecond)
 	fmt.Printf("    Tokens/sec:       %.2f\n", stats.TokenThroughput)
 
+	// Content validation
+	if stats.HasValidation {
+		fmt.Println("\n  Response Quality:")
+		fmt.Printf("    Valid:            %d (%.2f%%)\n", stats.ValidCount, stats.ValidRate)
+		fmt.Printf("    Quality Failures: %d\n", stats.QualityFailures)
+		for check, count := range stats.QualityFailuresByCheck {
+			fmt.Printf("      %s: %d\n", check, count)
+		}
+		fmt.Printf("    Goodput Req/sec:  %.2f\n", stats.GoodputRequestsPerSecond)
+		fmt.Printf("    Goodput Tokens/s: %.2f\n", stats.GoodputTokenThroughput)
+	}
+
 	// Token stats
 	fmt.Println("\n  Token Usage:")
 	fmt.Printf("    Input Tokens:     %d\n", stats.TotalInputTokens)
 	fmt.Printf("    Output Tokens:    %d\n", stats.TotalOutputTokens)
 	fmt.Printf("    Total Tokens:     %d\n", stats.TotalTokens)
+	if stats.HasCacheActivity() {
+		fmt.Printf("    Cache Read:       %d\n", stats.TotalCacheReadTokens)
+		fmt.Printf("    Cache Write:      %d\n", stats.TotalCacheWriteTokens)
+		fmt.Printf("    Cache Hit Ratio:  %.2f%%\n", stats.CacheHitRatio)
+	}
 
 	// Latency stats
 	if stats.SuccessCount > 0 {
@@ -92,6 +158,19 @@ func (c *ConsoleReporter) PrintStats(stats *types.Stats, concurrency int) {
 		fmt.Printf("    P99:              %.2f\n", stats.P99Latency)
 	}
 
+	// Server vs client split (when Bedrock reports server latency)
+	if stats.HasServerLatency {
+		fmt.Println("\n  Server vs Client (ms):")
+		fmt.Printf("    Server Avg:       %.2f\n", stats.AvgServerLatency)
+		fmt.Printf("    Server P50:       %.2f\n", stats.P50ServerLatency)
+		fmt.Printf("    Server P95:       %.2f\n", stats.P95ServerLatency)
+		fmt.Printf("    Overhead Avg:     %.2f\n", stats.AvgClientOverhead)
+		fmt.Printf("    Overhead P95:     %.2f\n", stats.P95ClientOverhead)
+		if stats.HasServerFirstByte {
+			fmt.Printf("    Server 1st Byte:  %.2f\n", stats.AvgServerFirstByte)
+		}
+	}
+
 	// TTFT stats (if available)
 	if stats.HasTTFT {
 		fmt.Println("\n  Time to First Token (ms):")
@@ -103,6 +182,52 @@ func (c *ConsoleReporter) PrintStats(stats *types.Stats, concurrency int) {
 		fmt.Printf("    P99:              %.2f\n", stats.P99TTFT)
 	}
 
+	// Reasoning split
+	if stats.HasThinking {
+		fmt.Println("\n  Reasoning:")
+		fmt.Printf("    Thinking Tokens:  %.1f avg/request\n", stats.AvgThinkingTokens)
+		fmt.Printf("    Answer Tokens:    %.1f avg/request\n", stats.AvgAnswerTokens)
+		if stats.HasTTFT {
+			fmt.Printf("    P50 1st Thinking: %.2f ms\n", stats.P50TimeToFirstThinking)
+			fmt.Printf("    P50 1st Answer:   %.2f ms\n", stats.P50TimeToFirstAnswer)
+		}
+	}
+
+	// Tool use
+	if stats.HasToolUse {
+		fmt.Println("\n  Tool Use:")
+		fmt.Printf("    Tool Call Rate:   %.1f%% (%d calls, %d invalid inputs)\n", stats.ToolCallRate, stats.TotalToolCalls, stats.InvalidToolInputs)
+		if stats.HasTTFT {
+			fmt.Printf("    P50 1st Tool Use: %.2f ms\n", stats.P50TimeToFirstToolUse)
+			fmt.Printf("    P50 Input Done:   %.2f ms\n", stats.P50TimeToToolInputComplete)
+		}
+		if stats.FollowUps > 0 {
+			fmt.Printf("    Second Turn:      %d runs, %d failed, P50 %.2f ms\n", stats.FollowUps, stats.FollowUpFailures, stats.P50FollowUpLatency)
+		}
+	}
+
+	// Decode speed (streaming only)
+	if stats.HasTPOT {
+		fmt.Println("\n  Time per Output Token (ms):")
+		fmt.Printf("    Average:          %.2f\n", stats.AvgTPOT)
+		fmt.Printf("    P50:              %.2f\n", stats.P50TPOT)
+		fmt.Printf("    P95:              %.2f\n", stats.P95TPOT)
+		fmt.Printf("    P99:              %.2f\n", stats.P99TPOT)
+	}
+	if stats.HasITL {
+		fmt.Println("\n  Inter-Token Latency (ms):")
+		fmt.Printf("    P50:              %.2f\n", stats.P50ITL)
+		fmt.Printf("    P95:              %.2f\n", stats.P95ITL)
+		fmt.Printf("    P99:              %.2f\n", stats.P99ITL)
+		fmt.Printf("    Max Stall:        %.2f\n", stats.MaxITL)
+	}
+	if stats.SuccessCount > 0 {
+		fmt.Println("\n  Output Tokens/sec per Request:")
+		fmt.Printf("    Average:          %.2f\n", stats.AvgOutputTokensPerSec)
+		fmt.Printf("    P50:              %.2f\n", stats.P50OutputTokensPerSec)
+		fmt.Printf("    P10 (slowest):    %.2f\n", stats.P10OutputTokensPerSec)
+	}
+
 	// Error distribution
 	if len(stats.ErrorsByType) > 0 {
 		fmt.Println("\n  Error Distribution:")
@@ -111,9 +236,113 @@ func (c *ConsoleReporter) PrintStats(stats *types.Stats, concurrency int) {
 		}
 	}
 
+	// Retries
+	if stats.HasRetries() {
+		fmt.Println("\n  Retries:")
+		fmt.Printf("    Retried:          %d (%d recovered)\n", stats.RetriedRequests, stats.RetriedSuccesses)
+		fmt.Printf("    Avg Attempts:     %.2f\n", stats.AvgAttempts)
+	}
+
+	// Streaming failure phases
+	if stats.HasStreamFailures() {
+		fmt.Println("\n  Streaming Failures:")
+		fmt.Printf("    At Request:       %d\n", stats.FailedAtRequest)
+		fmt.Printf("    Before 1st Token: %d\n", stats.FailedBeforeFirstToken)
+		fmt.Printf("    Mid-Stream:       %d\n", stats.FailedMidStream)
+		if stats.FailedMidStream > 0 {
+			fmt.Printf("    Avg Failed After: %.2f ms\n", stats.AvgMidStreamFailureAfter)
+			fmt.Printf("    Partial Tokens:   %d\n", stats.PartialOutputTokens)
+		}
+	}
+
 	fmt.Println(strings.Repeat("─", 80))
 }
 
+// PrintTrialSummary prints the mean and 95% confidence interval across repetitions
+func (c *ConsoleReporter) PrintTrialSummary(summary *types.TrialSummary, concurrency int) {
+	fmt.Printf("\n[Concurrency Level: %d] Summary over %d repetitions (mean [95%% CI]):\n", concurrency, summary.Repetitions)
+	fmt.Printf("    Requests/sec:     %s\n", FormatCI(summary.RequestsPerSecond))
+	fmt.Printf("    Tokens/sec:       %s\n", FormatCI(summary.TokenThroughput))
+	fmt.Printf("    P50 Latency (ms): %s\n", FormatCI(summary.P50Latency))
+	fmt.Printf("    P95 Latency (ms): %s\n", FormatCI(summary.P95Latency))
+	fmt.Printf("    P99 Latency (ms): %s\n", FormatCI(summary.P99Latency))
+	if summary.HasTTFT {
+		fmt.Printf("    P50 TTFT (ms):    %s\n", FormatCI(summary.P50TTFT))
+		fmt.Printf("    P95 TTFT (ms):    %s\n", FormatCI(summary.P95TTFT))
+		fmt.Printf("    P99 TTFT (ms):    %s\n", FormatCI(summary.P99TTFT))
+	}
+}
+
+// FormatCI formats a confidence interval as "mean [lower, upper]"
+func FormatCI(ci types.ConfidenceInterval) string {
+	return fmt.Sprintf("%.2f [%.2f, %.2f]", ci.Mean, ci.Lower, ci.Upper)
+}
+
+// PrintInjectedFaults prints the faults injected during a concurrency level
+func (c *ConsoleReporter) PrintInjectedFaults(counts string) {
+	fmt.Printf("  Injected faults: %s\n", counts)
+}
+
+// PrintCalibration prints the prompt size calibrated for a model
+func (c *ConsoleReporter) PrintCalibration(model string, calibration *types.PromptCalibration) {
+	source := fmt.Sprintf("%d probes", calibration.Probes)
+	if calibration.Probes == 0 {
+		source = "cached"
+	}
+	fmt.Printf("Prompt calibration for %s: %d characters -> %d input tokens (target %d, %s)\n",
+		model, calibration.PromptSize, calibration.ProbeTokens, calibration.TargetTokens, source)
+	if !calibration.Converged {
+		fmt.Printf("  Warning: could not reach the target within the tolerance; using the closest size\n")
+	}
+}
+
+// PrintTokenCount prints the CountTokens preflight result for a variant
+func (c *ConsoleReporter) PrintTokenCount(label string, tokens int, err error) {
+	if err != nil {
+		fmt.Printf("Token count for %s: unavailable (%v)\n", label, err)
+		return
+	}
+	fmt.Printf("Token count for %s: %d input tokens\n", label, tokens)
+}
+
+// PrintDryRun prints the estimated load of every variant and concurrency level
+func (c *ConsoleReporter) PrintDryRun(estimates []types.LoadEstimate, latency time.Duration, priced bool) {
+	fmt.Println("\n" + strings.Repeat("=", 80))
+	fmt.Printf("Dry Run Estimate (assuming %v per request, no generation requests sent)\n", latency)
+	fmt.Println(strings.Repeat("=", 80))
+
+	total, estimated := 0.0, false
+	fmt.Printf("%-40s %5s %10s %10s %10s %12s %10s\n", "Variant", "Conc", "Input Tok", "Max Out", "Req/min", "TPM", "Cost (USD)")
+	for _, e := range estimates {
+		input := fmt.Sprintf("%d", e.InputTokens)
+		if !e.Counted {
+			input += "*"
+			estimated = true
+		}
+		cost := "-"
+		if priced {
+			cost = fmt.Sprintf("%.4f", e.Cost)
+			total += e.Cost
+		}
+		fmt.Printf("%-40s %5d %10s %10d %10.1f %12.0f %10s\n",
+			e.Variant.Label(), e.Concurrency, input, e.MaxOutputTokens, e.RequestsPerMinute, e.TokensPerMinute, cost)
+	}
+
+	fmt.Println()
+	fmt.Println("TPM counts input plus max output tokens per request, the amount reserved against the quota.")
+	if estimated {
+		fmt.Println("* not counted by CountTokens; estimated at 4 characters per token.")
+	}
+	if priced {
+		fmt.Printf("Estimated total cost (upper bound, every request generating max tokens): $%.4f\n", total)
+	}
+}
+
+// PrintRecordingSaved prints where the recorded traffic was saved
+func (c *ConsoleReporter) PrintRecordingSaved(exchanges int, filename string) {
+	fmt.Printf("\nRecorded %d requests to: %s\n", exchanges, filename)
+}
+
 // PrintReportSaved prints a message indicating the report was saved
 func (c *ConsoleReporter) PrintReportSaved(filename string) {
 	fmt.Println()
@@ -2,10 +2,13 @@ package report
 
 import (
 	"fmt"
+	"math"
 	"os"
+	"sort"
 	"strings"
 	"time"
 
+	"bedrock-performance/internal/bedrock"
 	"bedrock-performance/internal/config"
 	"bedrock-performance/internal/types"
 )
@@ -39,12 +42,61 @@ func (m *MarkdownReporter) Generate(allStats []*types.ConcurrencyLevelStats) str
 	// Detailed Results by Concurrency Level
 	m.writeDetailedResults(&sb, allStats)
 
+	// Target vs actual input tokens (only when the prompt was sized by tokens)
+	m.writePromptCalibration(&sb, allStats)
+
+	// Counted vs reported input tokens (only with the CountTokens preflight)
+	m.writeTokenAccounting(&sb, allStats)
+
+	// Response quality and goodput (only when validation was configured)
+	m.writeQualityAnalysis(&sb, allStats)
+
+	// Repeatability (only when levels were run more than once)
+	m.writeRepeatability(&sb, allStats)
+
+	// Invoke vs Converse comparison (only when both were run)
+	m.writeComparison(&sb, "API Comparison (Invoke vs Converse)",
+		"Identical load sent through InvokeModel and the Converse API. Deltas are relative to the first API listed.",
+		allStats,
+		func(v types.Variant) string { return v.API },
+		func(v types.Variant) types.Variant { v.API = ""; return v },
+	)
+
+	// Model target comparison (base model vs inference profile vs provisioned)
+	m.writeComparison(&sb, "Model Target Comparison",
+		"Identical load sent to each configured model target. Deltas are relative to the primary model.",
+		allStats,
+		func(v types.Variant) string { return v.Model },
+		func(v types.Variant) types.Variant { v.Model = ""; return v },
+	)
+
+	// Service tier comparison (default vs priority vs flex)
+	m.writeServiceTierComparison(&sb, allStats)
+
+	// Latency-optimized vs standard inference (only when optimized latency was requested)
+	m.writeLatencyModeComparison(&sb, allStats)
+
+	// Prompt caching hit/miss and enabled vs disabled (only when caching was benchmarked)
+	m.writePromptCaching(&sb, allStats)
+
+	// Reasoning (only when thinking content was seen)
+	m.writeReasoningAnalysis(&sb, allStats)
+
+	// Tool use (only when tool use was benchmarked)
+	m.writeToolUseAnalysis(&sb, allStats)
+
+	// Image and document input (only when attachments were configured)
+	m.writeMultimodalAnalysis(&sb, allStats)
+
 	// Latency Analysis
 	m.writeLatencyAnalysis(&sb, allStats)
 
 	// TTFT Analysis (if available)
 	m.writeTTFTAnalysis(&sb, allStats)
 
+	// Retries and injected faults (if any)
+	m.writeRetryAnalysis(&sb, allStats)
+
 	// Error Analysis
 	m.writeErrorAnalysis(&sb, allStats)
 
@@ -56,17 +108,222 @@ func (m *MarkdownReporter) writeConfiguration(sb *strings.Builder) {
 	sb.WriteString("## Test Configuration\n\n")
 	sb.WriteString("| Parameter | Value |\n")
 	sb.WriteString("|-----------|-------|\n")
-	sb.WriteString(fmt.Sprintf("| Model | %s |\n", m.config.Model.ID))
+	for i, target := range m.config.Model.Targets() {
+		label := "Model"
+		if i > 0 {
+			label = fmt.Sprintf("Compared Model %d", i)
+		}
+		ref := bedrock.ParseModelID(target)
+		sb.WriteString(fmt.Sprintf("| %s | %s (%s) |\n", label, target, ref.Kind))
+		if ref.Kind == bedrock.ModelKindCrossRegionProfile {
+			sb.WriteString(fmt.Sprintf("| %s Routing | %s geography; the fulfilling region is not returned by the runtime API "+
+				"(see CloudTrail `inferenceRegion`) |\n", label, ref.Geo))
+		}
+	}
+	if m.config.Model.Format != "" {
+		sb.WriteString(fmt.Sprintf("| Model Format | %s |\n", m.config.Model.Format))
+	}
 	sb.WriteString(fmt.Sprintf("| Region | %s |\n", m.config.AWS.Region))
+	if endpoint := endpointDescription(&m.config.AWS); endpoint != "" {
+		sb.WriteString(fmt.Sprintf("| Endpoint | %s |\n", endpoint))
+	}
+	if traffic := trafficDescription(&m.config.Recording); traffic != "" {
+		sb.WriteString(fmt.Sprintf("| Traffic | %s |\n", traffic))
+	}
+	if retry := retryDescription(&m.config.AWS); retry != "" {
+		sb.WriteString(fmt.Sprintf("| Retry Policy | %s |\n", retry))
+	}
+	if faults := faultsDescription(&m.config.Faults); faults != "" {
+		sb.WriteString(fmt.Sprintf("| Injected Faults | %s |\n", faults))
+	}
+	if checks := validationDescription(&m.config.Validation); checks != "" {
+		sb.WriteString(fmt.Sprintf("| Response Validation | %s |\n", checks))
+	}
+	if m.config.Test.CountTokens.Enabled {
+		sb.WriteString(fmt.Sprintf("| Token Count Preflight | enabled (tolerance ±%g%%) |\n", m.config.Test.CountTokens.TolerancePercent))
+	}
 	sb.WriteString(fmt.Sprintf("| Quota | %d |\n", m.config.Model.Quota))
-	sb.WriteString(fmt.Sprintf("| Prompt Size | %d characters |\n", m.config.Test.PromptSize))
+	sb.WriteString(fmt.Sprintf("| Prompt Size | %s |\n", promptSizeDescription(&m.config.Test)))
 	sb.WriteString(fmt.Sprintf("| Max Tokens | %d |\n", m.config.Test.MaxTokens))
 	sb.WriteString(fmt.Sprintf("| Temperature | %.2f |\n", m.config.Test.Temperature))
+	m.writeInferenceParams(sb)
 	sb.WriteString(fmt.Sprintf("| Streaming Enabled | %t |\n", m.config.Test.Streaming))
 	sb.WriteString(fmt.Sprintf("| Non-Streaming Enabled | %t |\n", m.config.Test.NonStreaming))
+	sb.WriteString(fmt.Sprintf("| API | %s |\n", m.config.Test.API))
+	sb.WriteString(fmt.Sprintf("| Service Tier | %s |\n", strings.Join(m.config.Test.Tiers(), ", ")))
+	sb.WriteString(fmt.Sprintf("| Performance Latency | %s |\n", strings.Join(m.config.Test.Latencies(), ", ")))
+	if budgets := m.config.Test.Budgets(); len(budgets) > 1 || budgets[0] > 0 {
+		sb.WriteString(fmt.Sprintf("| Thinking Budget | %v tokens |\n", budgets))
+	}
+	if efforts := m.config.Test.Efforts(); len(efforts) > 1 || efforts[0] != "" {
+		sb.WriteString(fmt.Sprintf("| Reasoning Effort | %s |\n", strings.Join(efforts, ", ")))
+	}
+	if caching := m.config.Test.PromptCaching; caching.Enabled {
+		sb.WriteString(fmt.Sprintf("| Prompt Caching | enabled (compare disabled: %t) |\n", caching.CompareDisabled))
+	}
+	if media := m.config.Test.Multimodal; len(media.Images) > 0 {
+		sb.WriteString(fmt.Sprintf("| Images | %d files, %v per request, resolutions %v (0 = original) |\n",
+			len(media.Images), media.ImagesPerRequest(), media.ImageResolutions()))
+	}
+	if docs := m.config.Test.Multimodal.Documents; len(docs) > 0 {
+		sb.WriteString(fmt.Sprintf("| Documents | %s |\n", strings.Join(docs, ", ")))
+	}
+	if tools := m.config.Test.ToolUse; tools.Enabled {
+		source := "built-in get_weather"
+		if tools.ToolsFile != "" {
+			source = tools.ToolsFile
+		}
+		sb.WriteString(fmt.Sprintf("| Tool Use | %s (tool choice: %s, second turn: %t) |\n", source, tools.ToolChoice, tools.SecondTurn))
+	}
 	sb.WriteString(fmt.Sprintf("| Concurrency Range | %d - %d (step: %d) |\n",
 		m.config.Concurrency.Start, m.config.Concurrency.End, m.config.Concurrency.Step))
-	sb.WriteString(fmt.Sprintf("| Duration per Level | %d seconds |\n\n", m.config.Concurrency.DurationSeconds))
+	sb.WriteString(fmt.Sprintf("| Duration per Level | %d seconds |\n", m.config.Concurrency.DurationSeconds))
+	sb.WriteString(fmt.Sprintf("| Repetitions | %d (randomized order: %t) |\n\n",
+		m.config.Concurrency.Repetitions, m.config.Concurrency.RandomizeOrder))
+}
+
+// endpointDescription describes a non-default endpoint selection, or returns "" for the regional endpoint
+func endpointDescription(aws *config.AWSConfig) string {
+	var parts []string
+	if aws.EndpointURL != "" {
+		parts = append(parts, aws.EndpointURL)
+	}
+	if aws.UseFIPS {
+		parts = append(parts, "FIPS")
+	}
+	if aws.UseDualStack {
+		parts = append(parts, "dual-stack")
+	}
+	if aws.Anonymous {
+		parts = append(parts, "unsigned requests")
+	}
+	return strings.Join(parts, ", ")
+}
+
+// trafficDescription describes recording.mode, or returns "" for a plain live run
+func trafficDescription(rec *config.RecordingConfig) string {
+	switch rec.Mode {
+	case "record":
+		return fmt.Sprintf("live, recorded to %s", rec.File)
+	case "replay":
+		return fmt.Sprintf("replayed from %s with recorded timing (Bedrock was not called)", rec.File)
+	}
+	return ""
+}
+
+// retryDescription describes the retry and timeout settings that differ
+// from the SDK defaults, or returns "" when none do
+func retryDescription(aws *config.AWSConfig) string {
+	var parts []string
+	if aws.RetryMode != "" {
+		parts = append(parts, aws.RetryMode+" mode")
+	}
+	if aws.MaxAttempts > 0 {
+		parts = append(parts, fmt.Sprintf("max %d attempts", aws.MaxAttempts))
+	}
+	if aws.MaxBackoffMs > 0 {
+		parts = append(parts, fmt.Sprintf("max backoff %d ms", aws.MaxBackoffMs))
+	}
+	if aws.RequestTimeoutMs > 0 {
+		parts = append(parts, fmt.Sprintf("request timeout %d ms", aws.RequestTimeoutMs))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// faultsDescription lists the fault injection rules, or returns "" when there are none
+func faultsDescription(f *config.FaultsConfig) string {
+	var parts []string
+	for _, rule := range f.Rules {
+		desc := rule.Type
+		switch rule.Type {
+		case "latency":
+			desc = fmt.Sprintf("latency +%d ms", rule.LatencyMs)
+		case "server_error":
+			status := rule.Status
+			if status == 0 {
+				status = 500
+			}
+			desc = fmt.Sprintf("server error %d", status)
+		case "drop":
+			desc = fmt.Sprintf("drop after %d ms", rule.AfterMs)
+		}
+		desc += fmt.Sprintf(" on %.0f%% of attempts", rule.Rate*100)
+		if rule.StartSeconds > 0 || rule.EndSeconds > 0 {
+			end := "end"
+			if rule.EndSeconds > 0 {
+				end = fmt.Sprintf("%gs", rule.EndSeconds)
+			}
+			desc += fmt.Sprintf(" (%gs-%s of each level)", rule.StartSeconds, end)
+		}
+		parts = append(parts, desc)
+	}
+	return strings.Join(parts, "; ")
+}
+
+// promptSizeDescription describes how the prompt is sized, in characters or
+// by target input tokens
+func promptSizeDescription(t *config.TestConfig) string {
+	if t.PromptSizeTokens > 0 {
+		return fmt.Sprintf("%d input tokens (±%g%%, calibrated per model)", t.PromptSizeTokens, t.PromptTokenTolerance)
+	}
+	return fmt.Sprintf("%d characters", t.PromptSize)
+}
+
+// validationDescription lists the configured content checks, or returns "" when there are none
+func validationDescription(v *config.ValidationConfig) string {
+	var parts []string
+	if v.NonEmpty {
+		parts = append(parts, "non-empty")
+	}
+	if v.RejectMaxTokens {
+		parts = append(parts, "not stopped at max_tokens")
+	}
+	if v.MinLength > 0 {
+		parts = append(parts, fmt.Sprintf("at least %d characters", v.MinLength))
+	}
+	if v.MaxLength > 0 {
+		parts = append(parts, fmt.Sprintf("at most %d characters", v.MaxLength))
+	}
+	for _, s := range v.Contains {
+		parts = append(parts, fmt.Sprintf("contains %q", s))
+	}
+	if v.Regex != "" {
+		parts = append(parts, fmt.Sprintf("matches `%s`", v.Regex))
+	}
+	if v.JSONSchemaFile != "" {
+		parts = append(parts, "JSON matching "+v.JSONSchemaFile)
+	} else if v.JSON {
+		parts = append(parts, "valid JSON")
+	}
+	return strings.Join(parts, ", ")
+}
+
+// writeInferenceParams writes the optional prompt and sampling settings that were set
+func (m *MarkdownReporter) writeInferenceParams(sb *strings.Builder) {
+	t := m.config.Test
+	if t.SystemPrompt != "" {
+		source := "inline"
+		if t.SystemPromptFile != "" {
+			source = t.SystemPromptFile
+		}
+		sb.WriteString(fmt.Sprintf("| System Prompt | %d characters (%s) |\n", len(t.SystemPrompt), source))
+	}
+	if t.TopP > 0 {
+		sb.WriteString(fmt.Sprintf("| Top P | %.2f |\n", t.TopP))
+	}
+	if t.TopK > 0 {
+		sb.WriteString(fmt.Sprintf("| Top K | %d |\n", t.TopK))
+	}
+	if len(t.StopSequences) > 0 {
+		quoted := make([]string, len(t.StopSequences))
+		for i, seq := range t.StopSequences {
+			quoted[i] = fmt.Sprintf("`%q`", seq)
+		}
+		sb.WriteString(fmt.Sprintf("| Stop Sequences | %s |\n", strings.Join(quoted, ", ")))
+	}
+	if t.Prefill != "" {
+		sb.WriteString(fmt.Sprintf("| Assistant Prefill | `%q` |\n", t.Prefill))
+	}
 }
 
 // writeOverallSummary writes the overall summary section
@@ -77,12 +334,16 @@ func (m *MarkdownReporter) writeOverallSummary(sb *strings.Builder, allStats []*
 	totalSuccess := 0
 	totalFailures := 0
 	totalTokens := 0
+	totalValid := 0
+	totalQualityFailures := 0
 
 	for _, stat := range allStats {
 		totalRequests += stat.Stats.TotalRequests
 		totalSuccess += stat.Stats.SuccessCount
 		totalFailures += stat.Stats.FailureCount
 		totalTokens += stat.Stats.TotalTokens
+		totalValid += stat.Stats.ValidCount
+		totalQualityFailures += stat.Stats.QualityFailures
 	}
 
 	successRate := 0.0
@@ -95,19 +356,32 @@ func (m *MarkdownReporter) writeOverallSummary(sb *strings.Builder, allStats []*
 	sb.WriteString(fmt.Sprintf("| Total Requests | %d |\n", totalRequests))
 	sb.WriteString(fmt.Sprintf("| Successful Requests | %d (%.2f%%) |\n", totalSuccess, successRate))
 	sb.WriteString(fmt.Sprintf("| Failed Requests | %d |\n", totalFailures))
-	sb.WriteString(fmt.Sprintf("| Total Tokens Processed | %d |\n\n", totalTokens))
+	if m.config.Validation.Enabled() && totalRequests > 0 {
+		sb.WriteString(fmt.Sprintf("| Valid Responses | %d (%.2f%%) |\n", totalValid, float64(totalValid)/float64(totalRequests)*100.0))
+		sb.WriteString(fmt.Sprintf("| Quality Failures | %d |\n", totalQualityFailures))
+	}
+	sb.WriteString(fmt.Sprintf("| Total Tokens Processed | %d |\n", totalTokens))
+	if pricing := &m.config.Model.Pricing; pricing.Enabled() {
+		totalCost := 0.0
+		for _, stat := range allStats {
+			totalCost += estimateCost(pricing, stat)
+		}
+		sb.WriteString(fmt.Sprintf("| Estimated Cost | $%.4f |\n", totalCost))
+	}
+	sb.WriteString("\n")
 }
 
 // writeDetailedResults writes detailed results for each concurrency level
 func (m *MarkdownReporter) writeDetailedResults(sb *strings.Builder, allStats []*types.ConcurrencyLevelStats) {
 	sb.WriteString("## Detailed Results by Concurrency Level\n\n")
 
-	sb.WriteString("| Concurrency | Requests | Success Rate | Req/s | Tokens/s | Avg Latency (ms) | P50 (ms) | P95 (ms) | P99 (ms) |\n")
-	sb.WriteString("|-------------|----------|--------------|-------|----------|------------------|----------|----------|----------|\n")
+	sb.WriteString("| Variant | Concurrency | Requests | Success Rate | Req/s | Tokens/s | Avg Latency (ms) | P50 (ms) | P95 (ms) | P99 (ms) |\n")
+	sb.WriteString("|---------|-------------|----------|--------------|-------|----------|------------------|----------|----------|----------|\n")
 
 	for _, stat := range allStats {
 		s := stat.Stats
-		sb.WriteString(fmt.Sprintf("| %d | %d | %.2f%% | %.2f | %.2f | %.2f | %.2f | %.2f | %.2f |\n",
+		sb.WriteString(fmt.Sprintf("| %s | %d | %d | %.2f%% | %.2f | %.2f | %.2f | %.2f | %.2f | %.2f |\n",
+			stat.Variant.Label(),
 			stat.ConcurrencyLevel,
 			s.TotalRequests,
 			s.SuccessRate,
@@ -122,18 +396,776 @@ func (m *MarkdownReporter) writeDetailedResults(sb *strings.Builder, allStats []
 	sb.WriteString("\n")
 }
 
+// writeQualityAnalysis writes how many successful responses failed content
+// validation and the goodput left once they are excluded
+func (m *MarkdownReporter) writeQualityAnalysis(sb *strings.Builder, allStats []*types.ConcurrencyLevelStats) {
+	if !m.config.Validation.Enabled() {
+		return
+	}
+
+	sb.WriteString("## Response Quality and Goodput\n\n")
+	sb.WriteString(fmt.Sprintf("Successful responses were checked for: %s. ", validationDescription(&m.config.Validation)))
+	sb.WriteString("Quality failures returned HTTP 200 but failed a check; goodput counts only valid responses.\n\n")
+
+	sb.WriteString("| Variant | Concurrency | API Success Rate | Valid Rate | Quality Failures | Failed Checks | Req/s | Goodput Req/s | Tokens/s | Goodput Tokens/s |\n")
+	sb.WriteString("|---------|-------------|------------------|------------|------------------|---------------|-------|---------------|----------|------------------|\n")
+
+	samples := make(map[string]string)
+	for _, stat := range allStats {
+		s := stat.Stats
+		checks := []string{}
+		for _, check := range sortedKeys(s.QualityFailuresByCheck) {
+			checks = append(checks, fmt.Sprintf("%s(%d)", check, s.QualityFailuresByCheck[check]))
+		}
+		failedChecks := strings.Join(checks, ", ")
+		if failedChecks == "" {
+			failedChecks = "-"
+		}
+		sb.WriteString(fmt.Sprintf("| %s | %d | %.2f%% | %.2f%% | %d | %s | %.2f | %.2f | %.2f | %.2f |\n",
+			stat.Variant.Label(),
+			stat.ConcurrencyLevel,
+			s.SuccessRate,
+			s.ValidRate,
+			s.QualityFailures,
+			failedChecks,
+			s.RequestsPerSecond,
+			s.GoodputRequestsPerSecond,
+			s.TokenThroughput,
+			s.GoodputTokenThroughput,
+		))
+		for check, detail := range s.QualityFailureSamples {
+			if _, ok := samples[check]; !ok {
+				samples[check] = detail
+			}
+		}
+	}
+	sb.WriteString("\n")
+
+	if len(samples) == 0 {
+		return
+	}
+	sb.WriteString("### Sample Quality Failures\n\n")
+	sb.WriteString("| Check | Example |\n")
+	sb.WriteString("|-------|---------|\n")
+	for _, check := range sortedKeys(samples) {
+		sb.WriteString(fmt.Sprintf("| %s | %s |\n", check, strings.ReplaceAll(samples[check], "|", "\\|")))
+	}
+	sb.WriteString("\n")
+}
+
+// writePromptCalibration compares the target input tokens with the input
+// tokens actually reported during the run, for prompts sized by tokens
+func (m *MarkdownReporter) writePromptCalibration(sb *strings.Builder, allStats []*types.ConcurrencyLevelStats) {
+	if m.config.Test.PromptSizeTokens == 0 {
+		return
+	}
+
+	sb.WriteString("## Prompt Size Calibration\n\n")
+	sb.WriteString(fmt.Sprintf("The prompt text was scaled per model until a probe reported %d input tokens (±%g%%). ",
+		m.config.Test.PromptSizeTokens, m.config.Test.PromptTokenTolerance))
+	sb.WriteString("Actual input tokens include cached prompt tokens, images and documents, so variants that attach them exceed the target.\n\n")
+
+	sb.WriteString("| Variant | Concurrency | Prompt Characters | Target Input Tokens | Probe Input Tokens | Actual Avg Input Tokens | Deviation |\n")
+	sb.WriteString("|---------|-------------|-------------------|---------------------|--------------------|-------------------------|-----------|\n")
+
+	for _, stat := range allStats {
+		c := stat.Calibration
+		if c == nil {
+			continue
+		}
+		actual := stat.Stats.AvgInputTokens()
+		deviation := "-"
+		if stat.Stats.SuccessCount > 0 {
+			deviation = fmt.Sprintf("%+.1f%%", (actual-float64(c.TargetTokens))/float64(c.TargetTokens)*100)
+		}
+		probe := fmt.Sprintf("%d", c.ProbeTokens)
+		if !c.Converged {
+			probe += " (not converged)"
+		}
+		sb.WriteString(fmt.Sprintf("| %s | %d | %d | %d | %s | %.1f | %s |\n",
+			stat.Variant.Label(),
+			stat.ConcurrencyLevel,
+			c.PromptSize,
+			c.TargetTokens,
+			probe,
+			actual,
+			deviation,
+		))
+	}
+	sb.WriteString("\n")
+}
+
+// writeTokenAccounting compares the input tokens counted by the CountTokens
+// preflight with the input tokens reported by responses, flagging variants
+// that differ by more than the tolerance
+func (m *MarkdownReporter) writeTokenAccounting(sb *strings.Builder, allStats []*types.ConcurrencyLevelStats) {
+	if !m.config.Test.CountTokens.Enabled {
+		return
+	}
+	tolerance := m.config.Test.CountTokens.TolerancePercent
+
+	var rows strings.Builder
+	mismatches, compared := 0, 0
+	for _, stat := range allStats {
+		s := stat.Stats
+		counted, reported, difference, status := "-", "-", "-", ""
+		switch {
+		case stat.CountError != "":
+			status = "not counted: " + strings.ReplaceAll(stat.CountError, "|", "\\|")
+		case stat.CountedInputTokens == 0:
+			status = "not counted"
+		case s.SuccessCount == 0:
+			counted = fmt.Sprintf("%d", stat.CountedInputTokens)
+			status = "no successful responses"
+		default:
+			actual := s.AvgInputTokens()
+			diff := (actual - float64(stat.CountedInputTokens)) / float64(stat.CountedInputTokens) * 100
+			counted = fmt.Sprintf("%d", stat.CountedInputTokens)
+			reported = fmt.Sprintf("%.1f", actual)
+			difference = fmt.Sprintf("%+.1f%%", diff)
+			status = "ok"
+			compared++
+			if math.Abs(diff) > tolerance {
+				status = "**mismatch**"
+				mismatches++
+			}
+		}
+		rows.WriteString(fmt.Sprintf("| %s | %d | %s | %s | %s | %s |\n",
+			stat.Variant.Label(), stat.ConcurrencyLevel, counted, reported, difference, status))
+	}
+
+	sb.WriteString("## Token Accounting\n\n")
+	sb.WriteString("Input tokens counted with the CountTokens API before the run, compared with the average input tokens reported by successful responses (cached prompt tokens included). ")
+	sb.WriteString(fmt.Sprintf("Differences above %g%% are flagged as mismatches.\n\n", tolerance))
+	if mismatches > 0 {
+		sb.WriteString(fmt.Sprintf("**%d of %d compared result sets report input tokens that differ from the count.**\n\n", mismatches, compared))
+	}
+	sb.WriteString("| Variant | Concurrency | Counted Input Tokens | Reported Avg Input Tokens | Difference | Status |\n")
+	sb.WriteString("|---------|-------------|----------------------|---------------------------|------------|--------|\n")
+	sb.WriteString(rows.String())
+	sb.WriteString("\n")
+}
+
+// sortedKeys returns the keys of m in ascending order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// writeRepeatability writes the mean and 95% bootstrap confidence interval of
+// each metric across repetitions
+func (m *MarkdownReporter) writeRepeatability(sb *strings.Builder, allStats []*types.ConcurrencyLevelStats) {
+	hasSummary := false
+	hasTTFT := false
+	for _, stat := range allStats {
+		if stat.Summary != nil {
+			hasSummary = true
+			hasTTFT = hasTTFT || stat.Summary.HasTTFT
+		}
+	}
+
+	if !hasSummary {
+		return
+	}
+
+	sb.WriteString("## Repeatability\n\n")
+	sb.WriteString(fmt.Sprintf("Each concurrency level was run %d times. Values are the mean across repetitions "+
+		"with the 95%% bootstrap confidence interval in brackets.\n\n", m.config.Concurrency.Repetitions))
+
+	sb.WriteString("### Throughput and Latency\n\n")
+	sb.WriteString("| Variant | Concurrency | Req/s | Tokens/s | P50 (ms) | P95 (ms) | P99 (ms) |\n")
+	sb.WriteString("|---------|-------------|-------|----------|----------|----------|----------|\n")
+
+	for _, stat := range allStats {
+		s := stat.Summary
+		if s == nil {
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("| %s | %d | %s | %s | %s | %s | %s |\n",
+			stat.Variant.Label(),
+			stat.ConcurrencyLevel,
+			FormatCI(s.RequestsPerSecond),
+			FormatCI(s.TokenThroughput),
+			FormatCI(s.P50Latency),
+			FormatCI(s.P95Latency),
+			FormatCI(s.P99Latency),
+		))
+	}
+	sb.WriteString("\n")
+
+	if !hasTTFT {
+		return
+	}
+
+	sb.WriteString("### Time to First Token\n\n")
+	sb.WriteString("| Variant | Concurrency | P50 TTFT (ms) | P95 TTFT (ms) | P99 TTFT (ms) |\n")
+	sb.WriteString("|---------|-------------|---------------|---------------|---------------|\n")
+
+	for _, stat := range allStats {
+		s := stat.Summary
+		if s == nil || !s.HasTTFT {
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("| %s | %d | %s | %s | %s |\n",
+			stat.Variant.Label(),
+			stat.ConcurrencyLevel,
+			FormatCI(s.P50TTFT),
+			FormatCI(s.P95TTFT),
+			FormatCI(s.P99TTFT),
+		))
+	}
+	sb.WriteString("\n")
+}
+
+// writeComparison writes a side-by-side comparison of variants that differ only
+// in the dimension selected by value. Rows are grouped by the remaining variant
+// settings and concurrency level; the first value seen in each group is the
+// baseline for the delta columns. Nothing is written unless the dimension
+// takes at least two values.
+func (m *MarkdownReporter) writeComparison(sb *strings.Builder, title, description string, allStats []*types.ConcurrencyLevelStats,
+	value func(types.Variant) string, clear func(types.Variant) types.Variant) {
+	values := make(map[string]bool)
+	for _, stat := range allStats {
+		values[value(stat.Variant)] = true
+	}
+	if len(values) < 2 {
+		return
+	}
+
+	order, groups := groupVariants(allStats, clear)
+
+	sb.WriteString(fmt.Sprintf("## %s\n\n", title))
+	sb.WriteString(description + "\n\n")
+	sb.WriteString("| Variant | Concurrency | Compared | Req/s | P50 Latency (ms) | P99 Latency (ms) | P50 TTFT (ms) | Server Latency Avg (ms) | Δ P50 Latency | Δ P50 TTFT |\n")
+	sb.WriteString("|---------|-------------|----------|-------|------------------|------------------|---------------|-------------------------|---------------|------------|\n")
+
+	for _, key := range order {
+		baseline := groups[key][0].Stats
+		for i, stat := range groups[key] {
+			s := stat.Stats
+
+			deltaLatency := "baseline"
+			if i > 0 {
+				deltaLatency = formatDelta(s.P50Latency, baseline.P50Latency)
+			}
+
+			ttft := "-"
+			deltaTTFT := "-"
+			if s.HasTTFT {
+				ttft = fmt.Sprintf("%.2f", s.P50TTFT)
+				if i == 0 {
+					deltaTTFT = "baseline"
+				} else if baseline.HasTTFT {
+					deltaTTFT = formatDelta(s.P50TTFT, baseline.P50TTFT)
+				}
+			}
+
+			serverLatency := "-"
+			if s.HasServerLatency {
+				serverLatency = fmt.Sprintf("%.2f", s.AvgServerLatency)
+			}
+
+			sb.WriteString(fmt.Sprintf("| %s | %d | %s | %.2f | %.2f | %.2f | %s | %s | %s | %s |\n",
+				key.variant.Label(),
+				key.concurrency,
+				value(stat.Variant),
+				s.RequestsPerSecond,
+				s.P50Latency,
+				s.P99Latency,
+				ttft,
+				serverLatency,
+				deltaLatency,
+				deltaTTFT,
+			))
+		}
+	}
+	sb.WriteString("\n")
+}
+
+// variantGroup identifies stats that share every variant setting except the compared one
+type variantGroup struct {
+	variant     types.Variant
+	concurrency int
+}
+
+// groupVariants groups stats by their variant with the compared dimension
+// cleared, preserving the order in which groups first appear
+func groupVariants(allStats []*types.ConcurrencyLevelStats, clear func(types.Variant) types.Variant) ([]variantGroup, map[variantGroup][]*types.ConcurrencyLevelStats) {
+	var order []variantGroup
+	groups := make(map[variantGroup][]*types.ConcurrencyLevelStats)
+	for _, stat := range allStats {
+		key := variantGroup{variant: clear(stat.Variant), concurrency: stat.ConcurrencyLevel}
+		if _, ok := groups[key]; !ok {
+			order = append(order, key)
+		}
+		groups[key] = append(groups[key], stat)
+	}
+	return order, groups
+}
+
+// writeServiceTierComparison compares service tiers run under identical load,
+// including throttling and estimated cost
+func (m *MarkdownReporter) writeServiceTierComparison(sb *strings.Builder, allStats []*types.ConcurrencyLevelStats) {
+	tiers := make(map[string]bool)
+	for _, stat := range allStats {
+		tiers[stat.Variant.ServiceTier] = true
+	}
+	if len(tiers) < 2 {
+		return
+	}
+
+	order, groups := groupVariants(allStats, func(v types.Variant) types.Variant { v.ServiceTier = ""; return v })
+	pricing := &m.config.Model.Pricing
+
+	sb.WriteString("## Service Tier Comparison\n\n")
+	sb.WriteString("Identical load sent on each service tier. Deltas are relative to the first tier listed.")
+	if pricing.Enabled() {
+		sb.WriteString(fmt.Sprintf(" Cost estimates use $%.4f / $%.4f per million input / output tokens with tier multipliers applied.",
+			pricing.InputPerMillion, pricing.OutputPerMillion))
+	}
+	sb.WriteString("\n\n")
+
+	sb.WriteString("| Variant | Concurrency | Tier | Req/s | P50 Latency (ms) | P99 Latency (ms) | P50 TTFT (ms) | P99 TTFT (ms) | Throttled | Est. Cost (USD) | Cost / 1K Req (USD) | Δ P50 Latency |\n")
+	sb.WriteString("|---------|-------------|------|-------|------------------|------------------|---------------|---------------|-----------|-----------------|---------------------|---------------|\n")
+
+	for _, key := range order {
+		baseline := groups[key][0].Stats
+		for i, stat := range groups[key] {
+			s := stat.Stats
+			tier := stat.Variant.ServiceTier
+
+			p50TTFT, p99TTFT := "-", "-"
+			if s.HasTTFT {
+				p50TTFT = fmt.Sprintf("%.2f", s.P50TTFT)
+				p99TTFT = fmt.Sprintf("%.2f", s.P99TTFT)
+			}
+
+			throttled := "0 (0.00%)"
+			if s.TotalRequests > 0 {
+				count := s.ErrorsByType["ThrottlingError"]
+				throttled = fmt.Sprintf("%d (%.2f%%)", count, float64(count)/float64(s.TotalRequests)*100.0)
+			}
+
+			cost, costPerK := "-", "-"
+			if pricing.Enabled() {
+				total := estimateCost(pricing, stat)
+				cost = fmt.Sprintf("%.4f", total)
+				if s.SuccessCount > 0 {
+					costPerK = fmt.Sprintf("%.4f", total/float64(s.SuccessCount)*1000.0)
+				}
+			}
+
+			deltaLatency := "baseline"
+			if i > 0 {
+				deltaLatency = formatDelta(s.P50Latency, baseline.P50Latency)
+			}
+
+			sb.WriteString(fmt.Sprintf("| %s | %d | %s | %.2f | %.2f | %.2f | %s | %s | %s | %s | %s | %s |\n",
+				key.variant.Label(),
+				key.concurrency,
+				tier,
+				s.RequestsPerSecond,
+				s.P50Latency,
+				s.P99Latency,
+				p50TTFT,
+				p99TTFT,
+				throttled,
+				cost,
+				costPerK,
+				deltaLatency,
+			))
+		}
+	}
+	sb.WriteString("\n")
+}
+
+// writeLatencyModeComparison compares latency-optimized with standard
+// inference run under identical load: TTFT, per-request output speed and
+// estimated cost, plus how many requests Bedrock actually served optimized
+func (m *MarkdownReporter) writeLatencyModeComparison(sb *strings.Builder, allStats []*types.ConcurrencyLevelStats) {
+	optimized := false
+	for _, stat := range allStats {
+		optimized = optimized || stat.Variant.Latency != ""
+	}
+	if !optimized {
+		return
+	}
+
+	order, groups := groupVariants(allStats, func(v types.Variant) types.Variant { v.Latency = ""; return v })
+	pricing := &m.config.Model.Pricing
+
+	sb.WriteString("## Latency-Optimized Inference\n\n")
+	sb.WriteString("Identical load sent with each performance latency mode. Deltas are relative to the first mode listed. ")
+	sb.WriteString("Served Optimized counts the successful requests Bedrock reported serving with optimized latency; ")
+	sb.WriteString("requests it could not serve optimized fall back to standard latency.")
+	if pricing.Enabled() {
+		sb.WriteString(fmt.Sprintf(" Cost estimates apply a %.2fx multiplier to optimized requests.", pricing.LatencyMultiplier("optimized")))
+	}
+	sb.WriteString("\n\n")
+
+	sb.WriteString("| Variant | Concurrency | Latency | Served Optimized | P50 TTFT (ms) | Δ P50 TTFT | P50 Latency (ms) | Δ P50 Latency | P50 Output Tokens/s | Δ Output Tokens/s | Cost / 1K Req (USD) | Δ Cost |\n")
+	sb.WriteString("|---------|-------------|---------|------------------|---------------|------------|------------------|---------------|---------------------|-------------------|---------------------|--------|\n")
+
+	for _, key := range order {
+		baseline := groups[key][0]
+		for i, stat := range groups[key] {
+			s := stat.Stats
+
+			latency := stat.Variant.Latency
+			if latency == "" {
+				latency = m.config.Test.PerformanceLatency
+			}
+
+			served := "-"
+			if s.LatencyModeReported > 0 {
+				served = fmt.Sprintf("%d / %d", s.OptimizedServed, s.LatencyModeReported)
+			}
+
+			p50TTFT, deltaTTFT := "-", "-"
+			if s.HasTTFT {
+				p50TTFT = fmt.Sprintf("%.2f", s.P50TTFT)
+			}
+
+			costPerK, deltaCost := "-", "-"
+			if pricing.Enabled() && s.SuccessCount > 0 {
+				costPerK = fmt.Sprintf("%.4f", costPerThousand(pricing, stat))
+			}
+
+			deltaLatency, deltaTPS := "baseline", "baseline"
+			if i == 0 {
+				if s.HasTTFT {
+					deltaTTFT = "baseline"
+				}
+				if costPerK != "-" {
+					deltaCost = "baseline"
+				}
+			} else {
+				b := baseline.Stats
+				deltaLatency = formatDelta(s.P50Latency, b.P50Latency)
+				deltaTPS = formatPercentChange(s.P50OutputTokensPerSec, b.P50OutputTokensPerSec)
+				if s.HasTTFT && b.HasTTFT {
+					deltaTTFT = formatDelta(s.P50TTFT, b.P50TTFT)
+				}
+				if costPerK != "-" && b.SuccessCount > 0 {
+					deltaCost = formatPercentChange(costPerThousand(pricing, stat), costPerThousand(pricing, baseline))
+				}
+			}
+
+			sb.WriteString(fmt.Sprintf("| %s | %d | %s | %s | %s | %s | %.2f | %s | %.2f | %s | %s | %s |\n",
+				key.variant.Label(),
+				key.concurrency,
+				latency,
+				served,
+				p50TTFT,
+				deltaTTFT,
+				s.P50Latency,
+				deltaLatency,
+				s.P50OutputTokensPerSec,
+				deltaTPS,
+				costPerK,
+				deltaCost,
+			))
+		}
+	}
+	sb.WriteString("\n")
+}
+
+// estimateCost returns the estimated cost of one level, including prompt
+// cache reads and writes and the latency-optimized price
+func estimateCost(pricing *config.PricingConfig, stat *types.ConcurrencyLevelStats) float64 {
+	s := stat.Stats
+	tier := stat.Variant.ServiceTier
+	cost := pricing.EstimateCost(s.TotalInputTokens, s.TotalOutputTokens, tier) +
+		pricing.CacheCost(s.TotalCacheReadTokens, s.TotalCacheWriteTokens, tier)
+	return cost * pricing.LatencyMultiplier(stat.Variant.Latency)
+}
+
+// costPerThousand returns the estimated cost of 1,000 successful requests of one level
+func costPerThousand(pricing *config.PricingConfig, stat *types.ConcurrencyLevelStats) float64 {
+	return estimateCost(pricing, stat) / float64(stat.Stats.SuccessCount) * 1000.0
+}
+
+// writePromptCaching writes cache token accounting and hit/miss latency for
+// prompt caching runs
+func (m *MarkdownReporter) writePromptCaching(sb *strings.Builder, allStats []*types.ConcurrencyLevelStats) {
+	if !m.config.Test.PromptCaching.Enabled {
+		return
+	}
+
+	sb.WriteString("## Prompt Caching\n\n")
+	sb.WriteString("Every request sends the same shared prefix followed by a unique suffix. ")
+	sb.WriteString("A hit is a request that read its prefix from the cache; a miss wrote it (or did not use the cache). ")
+	sb.WriteString("Input Tokens exclude cached tokens.\n\n")
+
+	sb.WriteString("| Variant | Concurrency | Hit Ratio | Hits | Writes | Input Tokens | Cache Read Tokens | Cache Write Tokens | P50 TTFT Hit (ms) | P50 TTFT Miss (ms) | P50 Latency Hit (ms) | P50 Latency Miss (ms) |\n")
+	sb.WriteString("|---------|-------------|-----------|------|--------|--------------|-------------------|--------------------|-------------------|--------------------|----------------------|-----------------------|\n")
+
+	for _, stat := range allStats {
+		s := stat.Stats
+		if stat.Variant.PromptCaching != "on" {
+			continue
+		}
+
+		ttftHit, ttftMiss := "-", "-"
+		if s.HasCacheTTFT {
+			if s.CacheHits > 0 {
+				ttftHit = fmt.Sprintf("%.2f", s.P50TTFTCacheHit)
+			}
+			if s.SuccessCount > s.CacheHits {
+				ttftMiss = fmt.Sprintf("%.2f", s.P50TTFTCacheMiss)
+			}
+		}
+		latencyHit, latencyMiss := "-", "-"
+		if s.CacheHits > 0 {
+			latencyHit = fmt.Sprintf("%.2f", s.P50LatencyCacheHit)
+		}
+		if s.SuccessCount > s.CacheHits {
+			latencyMiss = fmt.Sprintf("%.2f", s.P50LatencyCacheMiss)
+		}
+
+		sb.WriteString(fmt.Sprintf("| %s | %d | %.2f%% | %d | %d | %d | %d | %d | %s | %s | %s | %s |\n",
+			stat.Variant.Label(),
+			stat.ConcurrencyLevel,
+			s.CacheHitRatio,
+			s.CacheHits,
+			s.CacheWrites,
+			s.TotalInputTokens,
+			s.TotalCacheReadTokens,
+			s.TotalCacheWriteTokens,
+			ttftHit,
+			ttftMiss,
+			latencyHit,
+			latencyMiss,
+		))
+	}
+	sb.WriteString("\n")
+
+	m.writeComparison(sb, "Prompt Caching Comparison (Enabled vs Disabled)",
+		"Identical prompts sent with and without a cache breakpoint after the shared prefix. Deltas are relative to caching disabled.",
+		allStats,
+		func(v types.Variant) string { return v.PromptCaching },
+		func(v types.Variant) types.Variant { v.PromptCaching = ""; return v },
+	)
+}
+
+// writeReasoningAnalysis writes the thinking vs answer split of reasoning
+// runs and how the thinking budget affects latency and throughput
+func (m *MarkdownReporter) writeReasoningAnalysis(sb *strings.Builder, allStats []*types.ConcurrencyLevelStats) {
+	hasThinking := false
+	for _, stat := range allStats {
+		if stat.Stats.HasThinking {
+			hasThinking = true
+			break
+		}
+	}
+	if !hasThinking {
+		return
+	}
+
+	sb.WriteString("## Reasoning Analysis\n\n")
+	sb.WriteString("TTFT elsewhere in this report is the first token of any kind. Here the first thinking token and the first answer token are shown separately. ")
+	sb.WriteString("Thinking tokens are counted in output tokens; when a model does not report them they are estimated from the share of thinking text.\n\n")
+
+	sb.WriteString("| Variant | Concurrency | P50 First Thinking (ms) | P50 First Answer (ms) | P95 First Answer (ms) | Avg Thinking Tokens | Avg Answer Tokens | Thinking Share | P50 Latency (ms) | Answer Tokens/s per Request | Req/s |\n")
+	sb.WriteString("|---------|-------------|-------------------------|-----------------------|-----------------------|---------------------|-------------------|----------------|------------------|-----------------------------|-------|\n")
+
+	for _, stat := range allStats {
+		s := stat.Stats
+		if !s.HasThinking && stat.Variant.Thinking == "" {
+			continue
+		}
+
+		firstThinking, firstAnswer, p95Answer := "-", "-", "-"
+		if s.HasTTFT && s.HasThinking {
+			firstThinking = fmt.Sprintf("%.2f", s.P50TimeToFirstThinking)
+			firstAnswer = fmt.Sprintf("%.2f", s.P50TimeToFirstAnswer)
+			p95Answer = fmt.Sprintf("%.2f", s.P95TimeToFirstAnswer)
+		}
+		share := "-"
+		if total := s.AvgThinkingTokens + s.AvgAnswerTokens; total > 0 {
+			share = fmt.Sprintf("%.1f%%", s.AvgThinkingTokens/total*100.0)
+		}
+
+		sb.WriteString(fmt.Sprintf("| %s | %d | %s | %s | %s | %.1f | %.1f | %s | %.2f | %.2f | %.2f |\n",
+			stat.Variant.Label(),
+			stat.ConcurrencyLevel,
+			firstThinking,
+			firstAnswer,
+			p95Answer,
+			s.AvgThinkingTokens,
+			s.AvgAnswerTokens,
+			share,
+			s.P50Latency,
+			s.AnswerTokensPerSecPerQuery,
+			s.RequestsPerSecond,
+		))
+	}
+	sb.WriteString("\n")
+
+	title, description := "Thinking Budget Comparison", "Identical load sent with each thinking budget. Deltas are relative to the first budget listed."
+	if efforts := m.config.Test.Efforts(); len(efforts) > 1 || efforts[0] != "" {
+		title, description = "Reasoning Effort Comparison", "Identical load sent with each reasoning effort. Deltas are relative to the first effort listed."
+	}
+	m.writeComparison(sb, title, description,
+		allStats,
+		func(v types.Variant) string { return v.Thinking },
+		func(v types.Variant) types.Variant { v.Thinking = ""; return v },
+	)
+}
+
+// writeToolUseAnalysis writes tool call rates, time to the first tool_use
+// block and its complete input, and the latency of the follow-up turn
+func (m *MarkdownReporter) writeToolUseAnalysis(sb *strings.Builder, allStats []*types.ConcurrencyLevelStats) {
+	if !m.config.Test.ToolUse.Enabled {
+		return
+	}
+
+	sb.WriteString("## Tool Use Analysis\n\n")
+	sb.WriteString("Time to tool use is measured from the request start to the first `tool_use` block; input complete is when its input JSON has fully streamed. ")
+	sb.WriteString("Invalid inputs are tool calls whose input did not parse as a JSON object.\n\n")
+
+	sb.WriteString("| Variant | Concurrency | Tool Call Rate | Tool Calls | Invalid Inputs | P50 First Tool Use (ms) | P95 First Tool Use (ms) | P50 Input Complete (ms) | P95 Input Complete (ms) | P50 Latency (ms) |\n")
+	sb.WriteString("|---------|-------------|----------------|------------|----------------|-------------------------|-------------------------|-------------------------|-------------------------|------------------|\n")
+
+	hasFollowUps := false
+	for _, stat := range allStats {
+		s := stat.Stats
+		hasFollowUps = hasFollowUps || s.FollowUps > 0
+
+		firstToolUse, p95ToolUse, inputComplete, p95Complete := "-", "-", "-", "-"
+		if s.HasTTFT && s.HasToolUse {
+			firstToolUse = fmt.Sprintf("%.2f", s.P50TimeToFirstToolUse)
+			p95ToolUse = fmt.Sprintf("%.2f", s.P95TimeToFirstToolUse)
+			inputComplete = fmt.Sprintf("%.2f", s.P50TimeToToolInputComplete)
+			p95Complete = fmt.Sprintf("%.2f", s.P95TimeToToolInputComplete)
+		}
+
+		sb.WriteString(fmt.Sprintf("| %s | %d | %.1f%% | %d | %d | %s | %s | %s | %s | %.2f |\n",
+			stat.Variant.Label(),
+			stat.ConcurrencyLevel,
+			s.ToolCallRate,
+			s.TotalToolCalls,
+			s.InvalidToolInputs,
+			firstToolUse,
+			p95ToolUse,
+			inputComplete,
+			p95Complete,
+			s.P50Latency,
+		))
+	}
+	sb.WriteString("\n")
+
+	if !hasFollowUps {
+		return
+	}
+
+	sb.WriteString("### Second Turn (Tool Result)\n\n")
+	sb.WriteString("The tool calls are answered with the canned `tool_result` and the final answer is timed separately from the first turn.\n\n")
+	sb.WriteString("| Variant | Concurrency | Follow-ups | Failures | P50 Latency (ms) | P95 Latency (ms) | P50 TTFT (ms) |\n")
+	sb.WriteString("|---------|-------------|------------|----------|------------------|------------------|---------------|\n")
+	for _, stat := range allStats {
+		s := stat.Stats
+		if s.FollowUps == 0 {
+			continue
+		}
+		ttft := "-"
+		if s.P50FollowUpTTFT > 0 {
+			ttft = fmt.Sprintf("%.2f", s.P50FollowUpTTFT)
+		}
+		sb.WriteString(fmt.Sprintf("| %s | %d | %d | %d | %.2f | %.2f | %s |\n",
+			stat.Variant.Label(),
+			stat.ConcurrencyLevel,
+			s.FollowUps,
+			s.FollowUpFailures,
+			s.P50FollowUpLatency,
+			s.P95FollowUpLatency,
+			ttft,
+		))
+	}
+	sb.WriteString("\n")
+}
+
+// writeMultimodalAnalysis writes input tokens and latency by the images and
+// documents attached to each request
+func (m *MarkdownReporter) writeMultimodalAnalysis(sb *strings.Builder, allStats []*types.ConcurrencyLevelStats) {
+	media := m.config.Test.Multimodal
+	if len(media.Images) == 0 && len(media.Documents) == 0 {
+		return
+	}
+
+	sb.WriteString("## Multimodal Input Analysis\n\n")
+	if len(media.Documents) > 0 {
+		sb.WriteString(fmt.Sprintf("Every request carries %d document(s). ", len(media.Documents)))
+	}
+	sb.WriteString("Input tokens include the tokens the model charged for images and documents.\n\n")
+
+	sb.WriteString("| Variant | Concurrency | Avg Input Tokens/Request | P50 Latency (ms) | P95 Latency (ms) | P50 TTFT (ms) | Req/s |\n")
+	sb.WriteString("|---------|-------------|--------------------------|------------------|------------------|---------------|-------|\n")
+	for _, stat := range allStats {
+		s := stat.Stats
+
+		avgInput := 0.0
+		if s.SuccessCount > 0 {
+			avgInput = float64(s.TotalInputTokens) / float64(s.SuccessCount)
+		}
+		ttft := "-"
+		if s.HasTTFT {
+			ttft = fmt.Sprintf("%.2f", s.P50TTFT)
+		}
+
+		sb.WriteString(fmt.Sprintf("| %s | %d | %.1f | %.2f | %.2f | %s | %.2f |\n",
+			stat.Variant.Label(),
+			stat.ConcurrencyLevel,
+			avgInput,
+			s.P50Latency,
+			s.P95Latency,
+			ttft,
+			s.RequestsPerSecond,
+		))
+	}
+	sb.WriteString("\n")
+
+	m.writeComparison(sb, "Image Input Comparison",
+		"Identical load sent with each image count and resolution. Deltas are relative to the first setting listed.",
+		allStats,
+		func(v types.Variant) string { return v.Images },
+		func(v types.Variant) types.Variant { v.Images = ""; return v },
+	)
+}
+
+// formatDelta formats the difference between value and baseline in ms and percent
+func formatDelta(value, baseline float64) string {
+	if baseline == 0 {
+		return fmt.Sprintf("%+.2f ms", value-baseline)
+	}
+	return fmt.Sprintf("%+.2f ms (%+.1f%%)", value-baseline, (value-baseline)/baseline*100.0)
+}
+
+// formatPercentChange formats the relative change of value from baseline
+func formatPercentChange(value, baseline float64) string {
+	if baseline == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%+.1f%%", (value-baseline)/baseline*100.0)
+}
+
 // writeLatencyAnalysis writes latency analysis section
 func (m *MarkdownReporter) writeLatencyAnalysis(sb *strings.Builder, allStats []*types.ConcurrencyLevelStats) {
 	sb.WriteString("## Latency Analysis\n\n")
 	sb.WriteString("### Latency Distribution by Concurrency Level\n\n")
 
-	sb.WriteString("| Concurrency | Min (ms) | Avg (ms) | Max (ms) | P50 (ms) | P95 (ms) | P99 (ms) |\n")
-	sb.WriteString("|-------------|----------|----------|----------|----------|----------|----------|\n")
+	sb.WriteString("| Variant | Concurrency | Min (ms) | Avg (ms) | Max (ms) | P50 (ms) | P95 (ms) | P99 (ms) |\n")
+	sb.WriteString("|---------|-------------|----------|----------|----------|----------|----------|----------|\n")
 
 	for _, stat := range allStats {
 		s := stat.Stats
 		if s.SuccessCount > 0 {
-			sb.WriteString(fmt.Sprintf("| %d | %.2f | %.2f | %.2f | %.2f | %.2f | %.2f |\n",
+			sb.WriteString(fmt.Sprintf("| %s | %d | %.2f | %.2f | %.2f | %.2f | %.2f | %.2f |\n",
+				stat.Variant.Label(),
 				stat.ConcurrencyLevel,
 				s.MinLatency,
 				s.AvgLatency,
@@ -145,6 +1177,64 @@ func (m *MarkdownReporter) writeLatencyAnalysis(sb *strings.Builder, allStats []
 		}
 	}
 	sb.WriteString("\n")
+
+	m.writeServerClientSplit(sb, allStats)
+}
+
+// writeServerClientSplit splits total latency into the time Bedrock reports
+// spending on the request and the remaining network/client overhead
+func (m *MarkdownReporter) writeServerClientSplit(sb *strings.Builder, allStats []*types.ConcurrencyLevelStats) {
+	hasServer := false
+	for _, stat := range allStats {
+		if stat.Stats.HasServerLatency {
+			hasServer = true
+			break
+		}
+	}
+	if !hasServer {
+		return
+	}
+
+	sb.WriteString("### Server vs Network/Client Latency\n\n")
+	sb.WriteString("Server time is the latency Bedrock reports (invocation metrics, response headers or Converse metrics). ")
+	sb.WriteString("Overhead is the rest of the measured latency: network path, TLS, SDK and client time. ")
+	sb.WriteString("A large overhead share points at the network path rather than Bedrock.\n\n")
+
+	sb.WriteString("| Variant | Concurrency | Avg Total (ms) | Avg Server (ms) | P50 Server (ms) | P95 Server (ms) | Avg Overhead (ms) | P95 Overhead (ms) | Overhead Share | Server First Byte (ms) | Client TTFT (ms) |\n")
+	sb.WriteString("|---------|-------------|----------------|-----------------|-----------------|-----------------|-------------------|-------------------|----------------|------------------------|------------------|\n")
+
+	for _, stat := range allStats {
+		s := stat.Stats
+		if !s.HasServerLatency {
+			continue
+		}
+		share := "-"
+		if s.AvgLatency > 0 {
+			share = fmt.Sprintf("%.1f%%", s.AvgClientOverhead/s.AvgLatency*100.0)
+		}
+		firstByte := "-"
+		if s.HasServerFirstByte {
+			firstByte = fmt.Sprintf("%.2f", s.AvgServerFirstByte)
+		}
+		ttft := "-"
+		if s.HasTTFT {
+			ttft = fmt.Sprintf("%.2f", s.AvgTTFT)
+		}
+		sb.WriteString(fmt.Sprintf("| %s | %d | %.2f | %.2f | %.2f | %.2f | %.2f | %.2f | %s | %s | %s |\n",
+			stat.Variant.Label(),
+			stat.ConcurrencyLevel,
+			s.AvgLatency,
+			s.AvgServerLatency,
+			s.P50ServerLatency,
+			s.P95ServerLatency,
+			s.AvgClientOverhead,
+			s.P95ClientOverhead,
+			share,
+			firstByte,
+			ttft,
+		))
+	}
+	sb.WriteString("\n")
 }
 
 // writeTTFTAnalysis writes TTFT analysis section (if available)
@@ -165,13 +1255,14 @@ func (m *MarkdownReporter) writeTTFTAnalysis(sb *strings.Builder, allStats []*ty
 	sb.WriteString("## Time to First Token (TTFT) Analysis\n\n")
 	sb.WriteString("### TTFT Distribution by Concurrency Level (Streaming Mode)\n\n")
 
-	sb.WriteString("| Concurrency | Min (ms) | Avg (ms) | Max (ms) | P50 (ms) | P95 (ms) | P99 (ms) |\n")
-	sb.WriteString("|-------------|----------|----------|----------|----------|----------|----------|\n")
+	sb.WriteString("| Variant | Concurrency | Min (ms) | Avg (ms) | Max (ms) | P50 (ms) | P95 (ms) | P99 (ms) |\n")
+	sb.WriteString("|---------|-------------|----------|----------|----------|----------|----------|----------|\n")
 
 	for _, stat := range allStats {
 		s := stat.Stats
 		if s.HasTTFT {
-			sb.WriteString(fmt.Sprintf("| %d | %.2f | %.2f | %.2f | %.2f | %.2f | %.2f |\n",
+			sb.WriteString(fmt.Sprintf("| %s | %d | %.2f | %.2f | %.2f | %.2f | %.2f | %.2f |\n",
+				stat.Variant.Label(),
 				stat.ConcurrencyLevel,
 				s.MinTTFT,
 				s.AvgTTFT,
@@ -183,16 +1274,60 @@ func (m *MarkdownReporter) writeTTFTAnalysis(sb *strings.Builder, allStats []*ty
 		}
 	}
 	sb.WriteString("\n")
+
+	m.writeDecodeAnalysis(sb, allStats)
+}
+
+// writeDecodeAnalysis writes decode speed (TPOT, inter-token latency and
+// per-request output token rate) for streaming variants
+func (m *MarkdownReporter) writeDecodeAnalysis(sb *strings.Builder, allStats []*types.ConcurrencyLevelStats) {
+	sb.WriteString("### Decode Speed by Concurrency Level (Streaming Mode)\n\n")
+	sb.WriteString("TPOT is (duration − TTFT) / (output tokens − 1). ITL is the gap between consecutive content chunks; ")
+	sb.WriteString("Max Stall is the longest gap seen. Output Tokens/s is measured per request.\n\n")
+
+	sb.WriteString("| Variant | Concurrency | Avg TPOT (ms) | P50 TPOT (ms) | P95 TPOT (ms) | P99 TPOT (ms) | P50 ITL (ms) | P95 ITL (ms) | P99 ITL (ms) | Max Stall (ms) | Output Tokens/s (P50) | Output Tokens/s (P10) |\n")
+	sb.WriteString("|---------|-------------|---------------|---------------|---------------|---------------|--------------|--------------|--------------|----------------|-----------------------|-----------------------|\n")
+
+	for _, stat := range allStats {
+		s := stat.Stats
+		if s.HasTPOT || s.HasITL {
+			sb.WriteString(fmt.Sprintf("| %s | %d | %.2f | %.2f | %.2f | %.2f | %.2f | %.2f | %.2f | %.2f | %.2f | %.2f |\n",
+				stat.Variant.Label(),
+				stat.ConcurrencyLevel,
+				s.AvgTPOT,
+				s.P50TPOT,
+				s.P95TPOT,
+				s.P99TPOT,
+				s.P50ITL,
+				s.P95ITL,
+				s.P99ITL,
+				s.MaxITL,
+				s.P50OutputTokensPerSec,
+				s.P10OutputTokensPerSec,
+			))
+		}
+	}
+	sb.WriteString("\n")
 }
 
 // writeErrorAnalysis writes error analysis section
 func (m *MarkdownReporter) writeErrorAnalysis(sb *strings.Builder, allStats []*types.ConcurrencyLevelStats) {
 	// Aggregate errors across all concurrency levels
 	allErrors := make(map[string]int)
+	allStatuses := make(map[int]int)
+	requestIDs := make(map[string]string)
 	for _, stat := range allStats {
 		for errType, count := range stat.Stats.ErrorsByType {
 			allErrors[errType] += count
 		}
+		for status, count := range stat.Stats.ErrorsByStatus {
+			allStatuses[status] += count
+		}
+		for errType, id := range stat.Stats.ErrorRequestIDs {
+			if _, ok := requestIDs[errType]; !ok {
+				requestIDs[errType] = id
+			}
+		}
 	}
 
 	if len(allErrors) == 0 {
@@ -204,18 +1339,40 @@ func (m *MarkdownReporter) writeErrorAnalysis(sb *strings.Builder, allStats []*t
 	sb.WriteString("## Error Analysis\n\n")
 	sb.WriteString("### Error Distribution\n\n")
 
-	sb.WriteString("| Error Type | Count |\n")
-	sb.WriteString("|------------|-------|\n")
+	sb.WriteString("| Error Type | Count | Sample Request ID |\n")
+	sb.WriteString("|------------|-------|-------------------|\n")
 
 	for errType, count := range allErrors {
-		sb.WriteString(fmt.Sprintf("| %s | %d |\n", errType, count))
+		requestID := requestIDs[errType]
+		if requestID == "" {
+			requestID = "-"
+		}
+		sb.WriteString(fmt.Sprintf("| %s | %d | %s |\n", errType, count, requestID))
+	}
+	sb.WriteString("\n")
+
+	sb.WriteString("### Errors by HTTP Status\n\n")
+	sb.WriteString("| HTTP Status | Count |\n")
+	sb.WriteString("|-------------|-------|\n")
+
+	statuses := make([]int, 0, len(allStatuses))
+	for status := range allStatuses {
+		statuses = append(statuses, status)
+	}
+	sort.Ints(statuses)
+	for _, status := range statuses {
+		label := fmt.Sprintf("%d", status)
+		if status == 0 {
+			label = "no response"
+		}
+		sb.WriteString(fmt.Sprintf("| %s | %d |\n", label, allStatuses[status]))
 	}
 	sb.WriteString("\n")
 
 	// Error breakdown by concurrency level
 	sb.WriteString("### Errors by Concurrency Level\n\n")
-	sb.WriteString("| Concurrency | Total Errors | Error Types |\n")
-	sb.WriteString("|-------------|--------------|-------------|\n")
+	sb.WriteString("| Variant | Concurrency | Total Errors | Error Types |\n")
+	sb.WriteString("|---------|-------------|--------------|-------------|\n")
 
 	for _, stat := range allStats {
 		if stat.Stats.FailureCount > 0 {
@@ -223,7 +1380,8 @@ func (m *MarkdownReporter) writeErrorAnalysis(sb *strings.Builder, allStats []*t
 			for errType, count := range stat.Stats.ErrorsByType {
 				errorTypes = append(errorTypes, fmt.Sprintf("%s(%d)", errType, count))
 			}
-			sb.WriteString(fmt.Sprintf("| %d | %d | %s |\n",
+			sb.WriteString(fmt.Sprintf("| %s | %d | %d | %s |\n",
+				stat.Variant.Label(),
 				stat.ConcurrencyLevel,
 				stat.Stats.FailureCount,
 				strings.Join(errorTypes, ", "),
@@ -231,6 +1389,97 @@ func (m *MarkdownReporter) writeErrorAnalysis(sb *strings.Builder, allStats []*t
 		}
 	}
 	sb.WriteString("\n")
+
+	m.writeStreamFailureAnalysis(sb, allStats)
+}
+
+// writeStreamFailureAnalysis writes when streaming requests failed: at the
+// initial call, after the stream opened but before any content, or mid-stream
+func (m *MarkdownReporter) writeStreamFailureAnalysis(sb *strings.Builder, allStats []*types.ConcurrencyLevelStats) {
+	hasFailures := false
+	for _, stat := range allStats {
+		if stat.Stats.HasStreamFailures() {
+			hasFailures = true
+			break
+		}
+	}
+	if !hasFailures {
+		return
+	}
+
+	sb.WriteString("### Streaming Failure Analysis\n\n")
+	sb.WriteString("Mid-stream failures are exceptions Bedrock sent after content had started arriving; ")
+	sb.WriteString("partial tokens are the output generated before the failure (a lower bound when usage was not yet reported).\n\n")
+	sb.WriteString("| Variant | Concurrency | At Request | Before First Token | Mid-Stream | Avg Failed After (ms) | Partial Output Tokens |\n")
+	sb.WriteString("|---------|-------------|------------|--------------------|------------|-----------------------|-----------------------|\n")
+
+	for _, stat := range allStats {
+		s := stat.Stats
+		if !s.HasStreamFailures() {
+			continue
+		}
+		failedAfter := "-"
+		if s.FailedMidStream > 0 {
+			failedAfter = fmt.Sprintf("%.2f", s.AvgMidStreamFailureAfter)
+		}
+		sb.WriteString(fmt.Sprintf("| %s | %d | %d | %d | %d | %s | %d |\n",
+			stat.Variant.Label(),
+			stat.ConcurrencyLevel,
+			s.FailedAtRequest,
+			s.FailedBeforeFirstToken,
+			s.FailedMidStream,
+			failedAfter,
+			s.PartialOutputTokens,
+		))
+	}
+	sb.WriteString("\n")
+}
+
+// writeRetryAnalysis writes how many requests the SDK retried and how many
+// of those it recovered, next to the success rate and tail latency they
+// produced. It is written when faults were injected or any request was retried.
+func (m *MarkdownReporter) writeRetryAnalysis(sb *strings.Builder, allStats []*types.ConcurrencyLevelStats) {
+	hasRetries := false
+	for _, stat := range allStats {
+		if stat.Stats.HasRetries() {
+			hasRetries = true
+			break
+		}
+	}
+	if !hasRetries && !m.config.Faults.Enabled() {
+		return
+	}
+
+	sb.WriteString("## Retries and Fault Injection\n\n")
+	if m.config.Faults.Enabled() {
+		sb.WriteString(fmt.Sprintf("Injected faults: %s. ", faultsDescription(&m.config.Faults)))
+		sb.WriteString("Faults are injected per HTTP attempt, so the SDK retries them like real failures.\n\n")
+	}
+	retry := retryDescription(&m.config.AWS)
+	if retry == "" {
+		retry = "SDK defaults (standard mode, max 3 attempts)"
+	}
+	sb.WriteString(fmt.Sprintf("Retry policy: %s. Recovered requests were retried and then succeeded; ", retry))
+	sb.WriteString("their latency includes the failed attempts and backoff.\n\n")
+
+	sb.WriteString("| Variant | Concurrency | Success Rate | Avg Attempts | Retried | Recovered | Timeouts | P50 Latency (ms) | P99 Latency (ms) |\n")
+	sb.WriteString("|---------|-------------|--------------|--------------|---------|-----------|----------|------------------|------------------|\n")
+
+	for _, stat := range allStats {
+		s := stat.Stats
+		sb.WriteString(fmt.Sprintf("| %s | %d | %.2f%% | %.2f | %d | %d | %d | %.2f | %.2f |\n",
+			stat.Variant.Label(),
+			stat.ConcurrencyLevel,
+			s.SuccessRate,
+			s.AvgAttempts,
+			s.RetriedRequests,
+			s.RetriedSuccesses,
+			s.ErrorsByType["TimeoutError"],
+			s.P50Latency,
+			s.P99Latency,
+		))
+	}
+	sb.WriteString("\n")
 }
 
 // SaveToFile saves the report to a file
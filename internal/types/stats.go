@@ -1,15 +1,18 @@
 package types
 
-import "time"
+import (
+	"strings"
+	"time"
+)
 
 // Stats contains computed statistics
 type Stats struct {
 	// General stats
-	TotalRequests     int
-	SuccessCount      int
-	FailureCount      int
-	SuccessRate       float64
-	Duration          time.Duration
+	TotalRequests int
+	SuccessCount  int
+	FailureCount  int
+	SuccessRate   float64
+	Duration      time.Duration
 
 	// Token stats
 	TotalInputTokens  int
@@ -17,6 +20,47 @@ type Stats struct {
 	TotalTokens       int
 	TokenThroughput   float64 // tokens per second
 
+	// Reasoning stats. Time to first thinking/answer token in milliseconds
+	// (streaming only); thinking tokens are part of TotalOutputTokens.
+	HasThinking                bool
+	P50TimeToFirstThinking     float64
+	P50TimeToFirstAnswer       float64
+	P95TimeToFirstAnswer       float64
+	TotalThinkingTokens        int
+	AvgThinkingTokens          float64
+	AvgAnswerTokens            float64
+	AnswerTokensPerSecPerQuery float64 // average answer tokens / request duration
+
+	// Prompt caching stats. Cached tokens are not included in TotalInputTokens.
+	TotalCacheReadTokens  int
+	TotalCacheWriteTokens int
+	CacheHits             int     // successful requests that read from the cache
+	CacheWrites           int     // successful requests that wrote the cache without reading it
+	CacheHitRatio         float64 // percentage of successful requests that were cache hits
+	HasCacheTTFT          bool
+	P50TTFTCacheHit       float64
+	P50TTFTCacheMiss      float64
+	P50LatencyCacheHit    float64
+	P50LatencyCacheMiss   float64
+
+	// Tool use stats. Time to the first tool_use block and to its complete
+	// input in milliseconds (streaming only); follow-up turns are not counted
+	// in the general stats above.
+	HasToolUse                 bool
+	ToolCallRequests           int     // successful requests with at least one tool call
+	ToolCallRate               float64 // percentage of successful requests that called a tool
+	TotalToolCalls             int
+	InvalidToolInputs          int // tool calls whose input was not a JSON object
+	P50TimeToFirstToolUse      float64
+	P95TimeToFirstToolUse      float64
+	P50TimeToToolInputComplete float64
+	P95TimeToToolInputComplete float64
+	FollowUps                  int
+	FollowUpFailures           int
+	P50FollowUpLatency         float64
+	P95FollowUpLatency         float64
+	P50FollowUpTTFT            float64
+
 	// Latency stats (in milliseconds)
 	AvgLatency float64
 	MinLatency float64
@@ -25,24 +69,236 @@ type Stats struct {
 	P95Latency float64
 	P99Latency float64
 
+	// Server-side latency reported by Bedrock (in milliseconds, when available)
+	HasServerLatency bool
+	AvgServerLatency float64
+	P50ServerLatency float64
+	P95ServerLatency float64
+	// Client overhead is total latency minus server latency: network, TLS, SDK and client time
+	AvgClientOverhead float64
+	P50ClientOverhead float64
+	P95ClientOverhead float64
+	// Server-side time to first byte (streaming only, when reported)
+	HasServerFirstByte bool
+	AvgServerFirstByte float64
+
 	// TTFT stats (in milliseconds, only for streaming)
-	HasTTFT    bool
-	AvgTTFT    float64
-	MinTTFT    float64
-	MaxTTFT    float64
-	P50TTFT    float64
-	P95TTFT    float64
-	P99TTFT    float64
+	HasTTFT bool
+	AvgTTFT float64
+	MinTTFT float64
+	MaxTTFT float64
+	P50TTFT float64
+	P95TTFT float64
+	P99TTFT float64
+
+	// Time per output token after the first (in milliseconds, only for streaming)
+	HasTPOT bool
+	AvgTPOT float64
+	P50TPOT float64
+	P95TPOT float64
+	P99TPOT float64
+
+	// Inter-token latency: gaps between consecutive content chunks
+	// (in milliseconds, only for streaming). MaxITL is the longest stall.
+	HasITL bool
+	P50ITL float64
+	P95ITL float64
+	P99ITL float64
+	MaxITL float64
+
+	// Per-request output tokens/sec (output tokens / request duration)
+	AvgOutputTokensPerSec float64
+	P10OutputTokensPerSec float64 // slowest 10% of requests are below this
+	P50OutputTokensPerSec float64
 
 	// Throughput
 	RequestsPerSecond float64
 
+	// Content validation (only when checks are configured). Quality failures
+	// are API successes whose content failed a check; they are included in
+	// SuccessCount. Goodput counts only validated responses.
+	HasValidation            bool
+	ValidCount               int
+	ValidRate                float64 // percentage of all requests that returned valid content
+	QualityFailures          int
+	QualityFailuresByCheck   map[string]int
+	QualityFailureSamples    map[string]string // one failure detail per check
+	GoodputRequestsPerSecond float64
+	GoodputTokenThroughput   float64 // input + output tokens/sec of valid responses
+
+	// Retries. AvgAttempts is the mean number of SDK attempts per request
+	// (0 when attempts are not known); retried requests made more than one.
+	AvgAttempts      float64
+	RetriedRequests  int
+	RetriedSuccesses int // retried requests that eventually succeeded
+
+	// Latency mode of successful requests: LatencyModeReported counts those
+	// Bedrock reported a mode for, OptimizedServed those served optimized
+	LatencyModeReported int
+	OptimizedServed     int
+
 	// Errors
 	ErrorsByType map[string]int
+	// ErrorsByStatus counts failures by HTTP status code (0 if no response was received)
+	ErrorsByStatus map[int]int
+	// ErrorRequestIDs holds one AWS request ID per error type for follow-up
+	ErrorRequestIDs map[string]string
+
+	// Streaming failures by phase (only for streaming)
+	FailedAtRequest          int
+	FailedBeforeFirstToken   int
+	FailedMidStream          int
+	AvgMidStreamFailureAfter float64 // ms from request start to a mid-stream failure
+	PartialOutputTokens      int     // tokens generated by requests that then failed mid-stream
+}
+
+// HasCacheActivity reports whether any request read or wrote the prompt cache
+func (s *Stats) HasCacheActivity() bool {
+	return s.TotalCacheReadTokens+s.TotalCacheWriteTokens > 0
+}
+
+// AvgInputTokens returns the mean input tokens per successful request,
+// including tokens read from or written to the prompt cache
+func (s *Stats) AvgInputTokens() float64 {
+	if s.SuccessCount == 0 {
+		return 0
+	}
+	return float64(s.TotalInputTokens+s.TotalCacheReadTokens+s.TotalCacheWriteTokens) / float64(s.SuccessCount)
+}
+
+// HasRetries reports whether any request was retried by the SDK
+func (s *Stats) HasRetries() bool {
+	return s.RetriedRequests > 0
+}
+
+// HasStreamFailures reports whether any streaming request failed
+func (s *Stats) HasStreamFailures() bool {
+	return s.FailedAtRequest+s.FailedBeforeFirstToken+s.FailedMidStream > 0
+}
+
+// ConfidenceInterval is a mean with its 95% bootstrap confidence interval
+type ConfidenceInterval struct {
+	Mean  float64
+	Lower float64
+	Upper float64
+}
+
+// TrialSummary aggregates the per-repetition stats of one concurrency level
+type TrialSummary struct {
+	Repetitions int
+
+	RequestsPerSecond ConfidenceInterval
+	TokenThroughput   ConfidenceInterval
+
+	P50Latency ConfidenceInterval
+	P95Latency ConfidenceInterval
+	P99Latency ConfidenceInterval
+
+	HasTTFT bool
+	P50TTFT ConfidenceInterval
+	P95TTFT ConfidenceInterval
+	P99TTFT ConfidenceInterval
+}
+
+// Variant identifies one combination of invocation settings that is swept
+// across all concurrency levels. Runs that differ in a single field are
+// compared side by side in the report.
+type Variant struct {
+	// Model is the display name of the benchmarked model target
+	Model       string
+	Streaming   bool
+	API         string
+	ServiceTier string
+	// Latency is the performance latency mode ("standard" or "optimized") when set
+	Latency string
+	// PromptCaching is "on" or "off" when prompt caching is benchmarked, "" otherwise
+	PromptCaching string
+	// Thinking is the extended thinking budget ("off" or a token count) or
+	// the reasoning effort ("effort high") when set
+	Thinking string
+	// Images describes the images attached per request (e.g. "2 images @1024px") when set
+	Images string
+}
+
+// Label returns a human-readable name for the variant
+func (v Variant) Label() string {
+	var parts []string
+	if v.Model != "" {
+		parts = append(parts, v.Model)
+	}
+	if v.Streaming {
+		parts = append(parts, "Streaming")
+	} else {
+		parts = append(parts, "Non-Streaming")
+	}
+	if v.API != "" {
+		parts = append(parts, v.API)
+	}
+	if v.ServiceTier != "" {
+		parts = append(parts, v.ServiceTier+" tier")
+	}
+	if v.Latency != "" {
+		parts = append(parts, v.Latency+" latency")
+	}
+	if v.PromptCaching != "" {
+		parts = append(parts, "cache "+v.PromptCaching)
+	}
+	if v.Thinking != "" {
+		parts = append(parts, "thinking "+v.Thinking)
+	}
+	if v.Images != "" {
+		parts = append(parts, v.Images)
+	}
+	return strings.Join(parts, " / ")
 }
 
 // ConcurrencyLevelStats tracks stats for a specific concurrency level
 type ConcurrencyLevelStats struct {
+	Variant          Variant
 	ConcurrencyLevel int
-	Stats            *Stats
+	// Stats is computed over the pooled results of all repetitions
+	Stats *Stats
+	// Trials holds the stats of each individual repetition
+	Trials []*Stats
+	// Summary is nil unless the level was run more than once
+	Summary *TrialSummary
+	// Calibration is nil unless the prompt was sized by input tokens
+	Calibration *PromptCalibration
+	// CountedInputTokens is the CountTokens preflight count of the variant's
+	// request (0 when not counted); CountError says why counting failed
+	CountedInputTokens int
+	CountError         string
+}
+
+// LoadEstimate is the dry-run estimate of one variant at one concurrency level
+type LoadEstimate struct {
+	Variant     Variant
+	Concurrency int
+	// InputTokens per request; Counted is false when CountTokens was not
+	// available and the count was estimated from the prompt length
+	InputTokens     int
+	Counted         bool
+	MaxOutputTokens int
+	// RequestsPerMinute and TokensPerMinute assume every worker sends
+	// requests back to back; tokens count input plus max output tokens,
+	// the amount reserved against the tokens-per-minute quota
+	RequestsPerMinute float64
+	TokensPerMinute   float64
+	Requests          int
+	// Cost is the upper bound for the level with every request generating
+	// max output tokens (0 without pricing)
+	Cost float64
+}
+
+// PromptCalibration is the prompt size found for a target input token count
+type PromptCalibration struct {
+	TargetTokens int
+	// PromptSize is the length of the generated prompt text in characters
+	PromptSize int
+	// ProbeTokens is the input token count reported for the last probe
+	ProbeTokens int
+	// Probes is the number of probe requests sent; 0 when the size was cached
+	Probes int
+	// Converged reports whether ProbeTokens is within the tolerance of the target
+	Converged bool
 }
@@ -0,0 +1,313 @@
+package validation
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Schema is the subset of JSON Schema used to validate responses: type,
+// enum, const, properties, required, additionalProperties, items,
+// minItems/maxItems, minLength/maxLength, pattern and minimum/maximum.
+// ParseSchema rejects any other validation keyword rather than skipping it.
+type Schema struct {
+	Type                 schemaTypes        `json:"type"`
+	Enum                 []interface{}      `json:"enum"`
+	Const                schemaConst        `json:"const"`
+	Properties           map[string]*Schema `json:"properties"`
+	Required             []string           `json:"required"`
+	AdditionalProperties json.RawMessage    `json:"additionalProperties"`
+	Items                *Schema            `json:"items"`
+	MinItems             *int               `json:"minItems"`
+	MaxItems             *int               `json:"maxItems"`
+	MinLength            *int               `json:"minLength"`
+	MaxLength            *int               `json:"maxLength"`
+	Pattern              string             `json:"pattern"`
+	Minimum              *float64           `json:"minimum"`
+	Maximum              *float64           `json:"maximum"`
+
+	pattern     *regexp.Regexp
+	extraSchema *Schema
+	noExtra     bool
+}
+
+// schemaConst is the "const" keyword; set tells "const": null apart from no const
+type schemaConst struct {
+	set   bool
+	value interface{}
+}
+
+// UnmarshalJSON is also called for a null literal, so set records any const
+func (c *schemaConst) UnmarshalJSON(data []byte) error {
+	c.set = true
+	return json.Unmarshal(data, &c.value)
+}
+
+// schemaTypes is the "type" keyword, a single type name or a list of them
+type schemaTypes []string
+
+func (t *schemaTypes) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*t = schemaTypes{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return fmt.Errorf("type must be a string or an array of strings")
+	}
+	*t = list
+	return nil
+}
+
+// supportedKeywords are the keywords Schema enforces, plus annotations that
+// never affect validation
+var supportedKeywords = map[string]bool{
+	"type": true, "enum": true, "const": true, "properties": true, "required": true,
+	"additionalProperties": true, "items": true, "minItems": true, "maxItems": true,
+	"minLength": true, "maxLength": true, "pattern": true, "minimum": true, "maximum": true,
+	"$schema": true, "$id": true, "$comment": true, "title": true, "description": true,
+	"default": true, "examples": true,
+}
+
+// ParseSchema parses a JSON Schema document, rejecting keywords it cannot
+// enforce so a response is never counted as valid against a partial check
+func ParseSchema(data []byte) (*Schema, error) {
+	var raw interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	if err := checkKeywords(raw, "$"); err != nil {
+		return nil, err
+	}
+
+	var s Schema
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, err
+	}
+	if err := s.compile(); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// checkKeywords reports the first unsupported keyword in a decoded schema
+// and its subschemas
+func checkKeywords(raw interface{}, path string) error {
+	schema, ok := raw.(map[string]interface{})
+	if !ok {
+		if _, isBool := raw.(bool); isBool && path != "$" {
+			return nil // additionalProperties: true/false
+		}
+		return fmt.Errorf("%s: schema must be an object", path)
+	}
+
+	keywords := make([]string, 0, len(schema))
+	for keyword := range schema {
+		keywords = append(keywords, keyword)
+	}
+	sort.Strings(keywords)
+	for _, keyword := range keywords {
+		if !supportedKeywords[keyword] {
+			return fmt.Errorf("%s: unsupported schema keyword %q", path, keyword)
+		}
+	}
+
+	if props, ok := schema["properties"].(map[string]interface{}); ok {
+		names := make([]string, 0, len(props))
+		for name := range props {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if err := checkKeywords(props[name], path+".properties."+name); err != nil {
+				return err
+			}
+		}
+	}
+	if extra, ok := schema["additionalProperties"]; ok {
+		if err := checkKeywords(extra, path+".additionalProperties"); err != nil {
+			return err
+		}
+	}
+	if items, ok := schema["items"]; ok {
+		if _, isBool := items.(bool); isBool {
+			return fmt.Errorf("%s.items: must be a schema", path)
+		}
+		return checkKeywords(items, path+".items")
+	}
+	return nil
+}
+
+// compile prepares patterns and additionalProperties for s and its subschemas
+func (s *Schema) compile() error {
+	if s.Pattern != "" {
+		re, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			return fmt.Errorf("invalid pattern %q: %w", s.Pattern, err)
+		}
+		s.pattern = re
+	}
+	if len(s.AdditionalProperties) > 0 {
+		var allowed bool
+		if err := json.Unmarshal(s.AdditionalProperties, &allowed); err == nil {
+			s.noExtra = !allowed
+		} else {
+			var extra Schema
+			if err := json.Unmarshal(s.AdditionalProperties, &extra); err != nil {
+				return fmt.Errorf("additionalProperties must be a boolean or a schema")
+			}
+			if err := extra.compile(); err != nil {
+				return err
+			}
+			s.extraSchema = &extra
+		}
+	}
+	for _, prop := range s.Properties {
+		if err := prop.compile(); err != nil {
+			return err
+		}
+	}
+	if s.Items != nil {
+		return s.Items.compile()
+	}
+	return nil
+}
+
+// Validate checks a value decoded by encoding/json against the schema
+func (s *Schema) Validate(value interface{}) error {
+	return s.validate(value, "$")
+}
+
+func (s *Schema) validate(value interface{}, path string) error {
+	if len(s.Type) > 0 && !s.matchesType(value) {
+		return fmt.Errorf("%s: got %s, want %s", path, typeName(value), strings.Join(s.Type, " or "))
+	}
+	if len(s.Enum) > 0 && !containsValue(s.Enum, value) {
+		return fmt.Errorf("%s: value is not one of the enum values", path)
+	}
+	if s.Const.set && !reflect.DeepEqual(s.Const.value, value) {
+		return fmt.Errorf("%s: value does not equal const", path)
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return s.validateObject(v, path)
+	case []interface{}:
+		if s.MinItems != nil && len(v) < *s.MinItems {
+			return fmt.Errorf("%s: %d items, want at least %d", path, len(v), *s.MinItems)
+		}
+		if s.MaxItems != nil && len(v) > *s.MaxItems {
+			return fmt.Errorf("%s: %d items, want at most %d", path, len(v), *s.MaxItems)
+		}
+		if s.Items != nil {
+			for i, item := range v {
+				if err := s.Items.validate(item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+					return err
+				}
+			}
+		}
+	case string:
+		length := utf8.RuneCountInString(v)
+		if s.MinLength != nil && length < *s.MinLength {
+			return fmt.Errorf("%s: %d characters, want at least %d", path, length, *s.MinLength)
+		}
+		if s.MaxLength != nil && length > *s.MaxLength {
+			return fmt.Errorf("%s: %d characters, want at most %d", path, length, *s.MaxLength)
+		}
+		if s.pattern != nil && !s.pattern.MatchString(v) {
+			return fmt.Errorf("%s: does not match pattern %s", path, s.Pattern)
+		}
+	case float64:
+		if s.Minimum != nil && v < *s.Minimum {
+			return fmt.Errorf("%s: %g is below the minimum %g", path, v, *s.Minimum)
+		}
+		if s.Maximum != nil && v > *s.Maximum {
+			return fmt.Errorf("%s: %g is above the maximum %g", path, v, *s.Maximum)
+		}
+	}
+	return nil
+}
+
+// validateObject checks required, properties and additionalProperties
+func (s *Schema) validateObject(obj map[string]interface{}, path string) error {
+	for _, name := range s.Required {
+		if _, ok := obj[name]; !ok {
+			return fmt.Errorf("%s: missing required property %q", path, name)
+		}
+	}
+
+	// Visit properties in a stable order so the reported error is repeatable
+	names := make([]string, 0, len(obj))
+	for name := range obj {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		propPath := path + "." + name
+		if prop, ok := s.Properties[name]; ok {
+			if err := prop.validate(obj[name], propPath); err != nil {
+				return err
+			}
+			continue
+		}
+		if s.noExtra {
+			return fmt.Errorf("%s: unexpected property", propPath)
+		}
+		if s.extraSchema != nil {
+			if err := s.extraSchema.validate(obj[name], propPath); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// matchesType reports whether value has one of the schema's types
+func (s *Schema) matchesType(value interface{}) bool {
+	for _, t := range s.Type {
+		switch name := typeName(value); {
+		case t == name:
+			return true
+		case t == "integer" && name == "number":
+			if f := value.(float64); f == math.Trunc(f) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// typeName returns the JSON Schema type of a decoded JSON value
+func typeName(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// containsValue reports whether values holds a value equal to v
+func containsValue(values []interface{}, v interface{}) bool {
+	for _, candidate := range values {
+		if reflect.DeepEqual(candidate, v) {
+			return true
+		}
+	}
+	return false
+}
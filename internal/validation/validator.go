@@ -0,0 +1,123 @@
+// Package validation checks the content of successful Bedrock responses.
+// A response that fails a check is a quality failure: the API call
+// succeeded, but the output is not usable (empty, truncated at max_tokens,
+// not the requested format, ...).
+package validation
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"bedrock-performance/internal/bedrock"
+	"bedrock-performance/internal/config"
+)
+
+// Names of the content checks, used as quality failure categories
+const (
+	CheckNonEmpty   = "non_empty"
+	CheckMaxTokens  = "max_tokens"
+	CheckMinLength  = "min_length"
+	CheckMaxLength  = "max_length"
+	CheckContains   = "contains"
+	CheckRegex      = "regex"
+	CheckJSON       = "json"
+	CheckJSONSchema = "json_schema"
+)
+
+// Validator runs the configured checks against response content
+type Validator struct {
+	cfg     config.ValidationConfig
+	prefill string
+	regex   *regexp.Regexp
+	schema  *Schema
+}
+
+// New creates a validator for cfg, compiling the regex and loading the JSON
+// schema file. prefill is the assistant prefill sent with each request; the
+// checks apply to the prefill followed by the response, which is the text
+// a caller would actually use.
+func New(cfg config.ValidationConfig, prefill string) (*Validator, error) {
+	v := &Validator{cfg: cfg, prefill: prefill}
+	if cfg.Regex != "" {
+		re, err := regexp.Compile(cfg.Regex)
+		if err != nil {
+			return nil, fmt.Errorf("invalid validation.regex: %w", err)
+		}
+		v.regex = re
+	}
+	if cfg.JSONSchemaFile != "" {
+		data, err := os.ReadFile(cfg.JSONSchemaFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read validation.json_schema_file: %w", err)
+		}
+		schema, err := ParseSchema(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse validation.json_schema_file: %w", err)
+		}
+		v.schema = schema
+	}
+	return v, nil
+}
+
+// Check returns the first check the result fails and why, or "" if it
+// passes. Responses that only call tools have no text to check and pass.
+func (v *Validator) Check(result *bedrock.InvokeResult) (check, detail string) {
+	if v.cfg.RejectMaxTokens && result.HitMaxTokens() {
+		return CheckMaxTokens, fmt.Sprintf("stopped at max_tokens (stop reason %s)", result.StopReason)
+	}
+	if result.ResponseContent == "" && len(result.ToolCalls) > 0 {
+		return "", ""
+	}
+
+	content := v.prefill + result.ResponseContent
+	if v.cfg.NonEmpty && strings.TrimSpace(content) == "" {
+		return CheckNonEmpty, "empty response"
+	}
+	length := utf8.RuneCountInString(content)
+	if v.cfg.MinLength > 0 && length < v.cfg.MinLength {
+		return CheckMinLength, fmt.Sprintf("%d characters, want at least %d", length, v.cfg.MinLength)
+	}
+	if v.cfg.MaxLength > 0 && length > v.cfg.MaxLength {
+		return CheckMaxLength, fmt.Sprintf("%d characters, want at most %d", length, v.cfg.MaxLength)
+	}
+	for _, s := range v.cfg.Contains {
+		if !strings.Contains(content, s) {
+			return CheckContains, fmt.Sprintf("missing %q", s)
+		}
+	}
+	if v.regex != nil && !v.regex.MatchString(content) {
+		return CheckRegex, fmt.Sprintf("does not match %s", v.regex)
+	}
+
+	if v.cfg.JSON || v.schema != nil {
+		var value interface{}
+		if err := json.Unmarshal([]byte(stripCodeFence(content)), &value); err != nil {
+			return CheckJSON, fmt.Sprintf("invalid JSON: %v", err)
+		}
+		if v.schema != nil {
+			if err := v.schema.Validate(value); err != nil {
+				return CheckJSONSchema, err.Error()
+			}
+		}
+	}
+	return "", ""
+}
+
+// stripCodeFence removes a markdown code fence (```json ... ```) around the
+// whole content, which models often add to JSON answers
+func stripCodeFence(content string) string {
+	trimmed := strings.TrimSpace(content)
+	if !strings.HasPrefix(trimmed, "```") || !strings.HasSuffix(trimmed, "```") || len(trimmed) < 6 {
+		return content
+	}
+	inner := strings.TrimSuffix(trimmed[3:], "```")
+	if newline := strings.IndexByte(inner, '\n'); newline >= 0 {
+		// Drop the language tag on the opening line
+		inner = inner[newline+1:]
+	}
+	return inner
+}
@@ -0,0 +1,146 @@
+package validation
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"bedrock-performance/internal/bedrock"
+	"bedrock-performance/internal/config"
+)
+
+const personSchema = `{
+	"type": "object",
+	"required": ["name", "age"],
+	"additionalProperties": false,
+	"properties": {
+		"name": {"type": "string", "minLength": 1},
+		"age": {"type": "integer", "minimum": 0},
+		"tags": {"type": "array", "items": {"enum": ["a", "b"]}, "maxItems": 2}
+	}
+}`
+
+func TestCheck(t *testing.T) {
+	schemaFile := filepath.Join(t.TempDir(), "schema.json")
+	if err := os.WriteFile(schemaFile, []byte(personSchema), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		cfg       config.ValidationConfig
+		prefill   string
+		content   string
+		stop      string
+		wantCheck string
+	}{
+		{"non-empty passes", config.ValidationConfig{NonEmpty: true}, "", "hello", "end_turn", ""},
+		{"whitespace is empty", config.ValidationConfig{NonEmpty: true}, "", " \n", "end_turn", CheckNonEmpty},
+		{"truncated", config.ValidationConfig{RejectMaxTokens: true}, "", "hello", "max_tokens", CheckMaxTokens},
+		{"truncated (length)", config.ValidationConfig{RejectMaxTokens: true}, "", "hello", "LENGTH", CheckMaxTokens},
+		{"too short", config.ValidationConfig{MinLength: 10}, "", "héllo", "", CheckMinLength},
+		{"too long", config.ValidationConfig{MaxLength: 3}, "", "hello", "", CheckMaxLength},
+		{"missing substring", config.ValidationConfig{Contains: []string{"hello", "world"}}, "", "hello there", "", CheckContains},
+		{"regex", config.ValidationConfig{Regex: `^\d+$`}, "", "12a", "", CheckRegex},
+		{"invalid json", config.ValidationConfig{JSON: true}, "", `{"a": }`, "", CheckJSON},
+		{"fenced json", config.ValidationConfig{JSON: true}, "", "```json\n{\"a\": 1}\n```", "", ""},
+		{"json with prefill", config.ValidationConfig{JSON: true}, "{", `"a": 1}`, "", ""},
+		{"schema passes", config.ValidationConfig{JSONSchemaFile: schemaFile}, "", `{"name": "Ann", "age": 30, "tags": ["a"]}`, "", ""},
+		{"schema missing property", config.ValidationConfig{JSONSchemaFile: schemaFile}, "", `{"name": "Ann"}`, "", CheckJSONSchema},
+		{"schema wrong type", config.ValidationConfig{JSONSchemaFile: schemaFile}, "", `{"name": "Ann", "age": 1.5}`, "", CheckJSONSchema},
+		{"schema extra property", config.ValidationConfig{JSONSchemaFile: schemaFile}, "", `{"name": "Ann", "age": 1, "x": 1}`, "", CheckJSONSchema},
+		{"schema enum", config.ValidationConfig{JSONSchemaFile: schemaFile}, "", `{"name": "Ann", "age": 1, "tags": ["c"]}`, "", CheckJSONSchema},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, err := New(tt.cfg, tt.prefill)
+			if err != nil {
+				t.Fatal(err)
+			}
+			check, detail := v.Check(&bedrock.InvokeResult{ResponseContent: tt.content, StopReason: tt.stop})
+			if check != tt.wantCheck {
+				t.Errorf("Check() = %q (%s), want %q", check, detail, tt.wantCheck)
+			}
+			if check != "" && detail == "" {
+				t.Errorf("Check() failed %q without a detail", check)
+			}
+		})
+	}
+}
+
+func TestToolCallsPassContentChecks(t *testing.T) {
+	v, err := New(config.ValidationConfig{NonEmpty: true, JSON: true}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := &bedrock.InvokeResult{ToolCalls: []bedrock.ToolCall{{Name: "get_weather", Input: "{}"}}}
+	if check, detail := v.Check(result); check != "" {
+		t.Errorf("tool call response failed %s: %s", check, detail)
+	}
+}
+
+func TestSchemaErrorPath(t *testing.T) {
+	schema, err := ParseSchema([]byte(personSchema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = schema.Validate(map[string]interface{}{"name": "", "age": float64(3)})
+	if err == nil || !strings.Contains(err.Error(), "$.name") {
+		t.Errorf("Validate() = %v, want an error at $.name", err)
+	}
+	if _, err := ParseSchema([]byte(`{"pattern": "("}`)); err == nil {
+		t.Errorf("ParseSchema accepted an invalid pattern")
+	}
+}
+
+func TestSchemaRejectsUnsupportedKeywords(t *testing.T) {
+	tests := []struct {
+		schema   string
+		wantPath string
+	}{
+		{`{"$ref": "#/definitions/person"}`, `$: unsupported schema keyword "$ref"`},
+		{`{"type": "object", "properties": {"id": {"oneOf": [{"type": "string"}, {"type": "integer"}]}}}`, `$.properties.id: unsupported schema keyword "oneOf"`},
+		{`{"type": "array", "items": {"type": "string", "format": "email"}}`, `$.items: unsupported schema keyword "format"`},
+		{`{"additionalProperties": {"not": {"type": "null"}}}`, `$.additionalProperties: unsupported schema keyword "not"`},
+		{`{"allOf": [], "anyOf": []}`, `$: unsupported schema keyword "allOf"`},
+	}
+	for _, tt := range tests {
+		if _, err := ParseSchema([]byte(tt.schema)); err == nil || err.Error() != tt.wantPath {
+			t.Errorf("ParseSchema(%s) = %v, want %q", tt.schema, err, tt.wantPath)
+		}
+	}
+
+	annotated := `{"$schema": "https://json-schema.org/draft/2020-12/schema", "title": "Person", "type": "object", "properties": {"name": {"type": "string", "description": "Full name"}}}`
+	if _, err := ParseSchema([]byte(annotated)); err != nil {
+		t.Errorf("ParseSchema rejected annotations: %v", err)
+	}
+}
+
+func TestSchemaConst(t *testing.T) {
+	tests := []struct {
+		schema string
+		value  interface{}
+		valid  bool
+	}{
+		{`{"const": null}`, nil, true},
+		{`{"const": null}`, "null", false},
+		{`{"const": null}`, float64(0), false},
+		{`{"properties": {"deleted": {"const": null}}}`, map[string]interface{}{"deleted": false}, false},
+		{`{"const": "ok"}`, "ok", true},
+		{`{"const": "ok"}`, "OK", false},
+		{`{"const": {"a": [1, 2]}}`, map[string]interface{}{"a": []interface{}{float64(1), float64(2)}}, true},
+		// Without const any value passes, null included
+		{`{}`, nil, true},
+		{`{}`, "anything", true},
+	}
+	for _, tt := range tests {
+		schema, err := ParseSchema([]byte(tt.schema))
+		if err != nil {
+			t.Fatalf("ParseSchema(%s) = %v", tt.schema, err)
+		}
+		if err := schema.Validate(tt.value); (err == nil) != tt.valid {
+			t.Errorf("%s: Validate(%#v) = %v, want valid %v", tt.schema, tt.value, err, tt.valid)
+		}
+	}
+}